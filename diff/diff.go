@@ -0,0 +1,162 @@
+// Package diff computes line-level diffs of text using Myers' algorithm.
+package diff
+
+import "strings"
+
+// Op is the kind of a diff line
+type Op string
+
+const (
+	Equal  Op = "equal"
+	Insert Op = "insert"
+	Delete Op = "delete"
+)
+
+// Limits on the work done for one diff. Inputs beyond them are diffed as the
+// deletion of every changed line followed by the insertion of the new ones.
+const (
+	// maxLines bounds the number of lines of each side left after trimming the
+	// common prefix and suffix
+	maxLines = 10000
+	// maxEdits bounds the length of the edit script searched for. The memory used
+	// by the search grows with its square.
+	maxEdits = 1000
+)
+
+// Line is one line of a diff
+type Line struct {
+	Op   Op
+	Text string
+}
+
+// Lines returns the shortest edit script turning a into b, line by line
+func Lines(a, b string) []Line {
+	x, y := splitLines(a), splitLines(b)
+
+	// Common prefix and suffix do not need the edit graph
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+
+	lines := make([]Line, 0, len(x)+len(y))
+	for _, text := range x[:prefix] {
+		lines = append(lines, Line{Op: Equal, Text: text})
+	}
+	lines = append(lines, myers(x[prefix:len(x)-suffix], y[prefix:len(y)-suffix])...)
+	for _, text := range x[len(x)-suffix:] {
+		lines = append(lines, Line{Op: Equal, Text: text})
+	}
+	return lines
+}
+
+// myers finds the shortest edit script with Myers' O((N+M)D) algorithm, falling
+// back to replacing every line when the inputs exceed maxLines or no script of
+// at most maxEdits edits exists
+func myers(a, b []string) []Line {
+	n, m := len(a), len(b)
+	maxD := min(n+m, maxEdits)
+	if n+m == 0 {
+		return nil
+	}
+	if n > maxLines || m > maxLines {
+		return replaceAll(a, b)
+	}
+
+	// v[offset+k] is the furthest x reached on diagonal k. trace[d] keeps v[offset-d]
+	// to v[offset+d] as they were before step d: the only diagonals step d reads.
+	offset := maxD + 1
+	v := make([]int, 2*maxD+3)
+	var trace [][]int
+	found := false
+
+search:
+	for d := 0; d <= maxD; d++ {
+		trace = append(trace, append([]int(nil), v[offset-d:offset+d+1]...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // down: insertion
+			} else {
+				x = v[offset+k-1] + 1 // right: deletion
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				found = true
+				break search
+			}
+		}
+	}
+	if !found {
+		return replaceAll(a, b)
+	}
+
+	// Walk the trace backwards from (n, m) to recover the edits
+	var reversed []Line
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		// trace[d][d+k] is v[offset+k] before step d
+		v := trace[d]
+		k := x - y
+		var prevK int
+		if k == -d || (k != d && v[d+k-1] < v[d+k+1]) {
+			prevK = k + 1
+		} else {
+			prevK = k - 1
+		}
+		prevX := 0
+		if d > 0 {
+			prevX = v[d+prevK]
+		}
+		prevY := prevX - prevK
+
+		for x > prevX && y > prevY {
+			reversed = append(reversed, Line{Op: Equal, Text: a[x-1]})
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				reversed = append(reversed, Line{Op: Insert, Text: b[y-1]})
+			} else {
+				reversed = append(reversed, Line{Op: Delete, Text: a[x-1]})
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	lines := make([]Line, len(reversed))
+	for i, line := range reversed {
+		lines[len(reversed)-1-i] = line
+	}
+	return lines
+}
+
+// replaceAll returns the edit script deleting every line of a and inserting every line of b
+func replaceAll(a, b []string) []Line {
+	lines := make([]Line, 0, len(a)+len(b))
+	for _, text := range a {
+		lines = append(lines, Line{Op: Delete, Text: text})
+	}
+	for _, text := range b {
+		lines = append(lines, Line{Op: Insert, Text: text})
+	}
+	return lines
+}
+
+// splitLines splits text into lines; a trailing newline does not start a new line
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
@@ -0,0 +1,97 @@
+package diff
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestLines(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want []Line
+	}{
+		{"empty", "", "", []Line{}},
+		{"identical", "a\nb\n", "a\nb\n", []Line{{Equal, "a"}, {Equal, "b"}}},
+		{"insert into empty", "", "a\nb", []Line{{Insert, "a"}, {Insert, "b"}}},
+		{"delete everything", "a\nb", "", []Line{{Delete, "a"}, {Delete, "b"}}},
+		{"insert only", "a\nc", "a\nb\nc", []Line{{Equal, "a"}, {Insert, "b"}, {Equal, "c"}}},
+		{"delete only", "a\nb\nc", "a\nc", []Line{{Equal, "a"}, {Delete, "b"}, {Equal, "c"}}},
+		{"full rewrite", "a\nb", "c\nd", []Line{{Delete, "a"}, {Delete, "b"}, {Insert, "c"}, {Insert, "d"}}},
+		{"change in the middle", "a\nb\nc\nd", "a\nx\nc\ny\nd", []Line{
+			{Equal, "a"}, {Delete, "b"}, {Insert, "x"}, {Equal, "c"}, {Insert, "y"}, {Equal, "d"},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := Lines(tt.a, tt.b)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Lines(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLinesIsShortest(t *testing.T) {
+	a := "a\nb\nc\na\nb\nb\na"
+	b := "c\nb\na\nb\na\nc"
+	edits := 0
+	for _, line := range Lines(a, b) {
+		if line.Op != Equal {
+			edits++
+		}
+	}
+	// The example of Myers' paper: the shortest edit script has 5 edits
+	if edits != 5 {
+		t.Errorf("got %d edits, want 5", edits)
+	}
+	checkScript(t, a, b, Lines(a, b))
+}
+
+func TestLinesLargeRewrite(t *testing.T) {
+	// Rewrites needing more than maxEdits edits fall back to replacing every line
+	a, b := numberedLines("a", 3000), numberedLines("b", 3000)
+	lines := Lines(a, b)
+	if len(lines) != 6000 {
+		t.Fatalf("got %d lines, want 6000", len(lines))
+	}
+	for i, line := range lines {
+		if (i < 3000 && line.Op != Delete) || (i >= 3000 && line.Op != Insert) {
+			t.Fatalf("line %d is %s, want all deletions then insertions", i, line.Op)
+		}
+	}
+
+	// Small edits of a long text are still diffed exactly
+	edited := strings.Replace(a, "a1500\n", "changed\n", 1)
+	checkScript(t, a, edited, Lines(a, edited))
+	if got := len(Lines(a, edited)); got != 3001 {
+		t.Errorf("got %d lines, want 3001", got)
+	}
+}
+
+// checkScript checks that applying lines to a gives b
+func checkScript(t *testing.T, a, b string, lines []Line) {
+	t.Helper()
+	var from, to []string
+	for _, line := range lines {
+		if line.Op != Insert {
+			from = append(from, line.Text)
+		}
+		if line.Op != Delete {
+			to = append(to, line.Text)
+		}
+	}
+	if !reflect.DeepEqual(from, splitLines(a)) || !reflect.DeepEqual(to, splitLines(b)) {
+		t.Errorf("edit script %v does not turn %q into %q", lines, a, b)
+	}
+}
+
+func numberedLines(prefix string, n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		fmt.Fprintf(&b, "%s%d\n", prefix, i)
+	}
+	return b.String()
+}
@@ -0,0 +1,182 @@
+// Package feed encodes blog posts as RSS 2.0 and Atom 1.0 syndication feeds.
+package feed
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// Channel describes a feed and its items
+type Channel struct {
+	Title       string
+	Link        string // URL of the site the feed belongs to
+	FeedURL     string // URL of the feed itself
+	Description string
+	Updated     time.Time
+	Items       []Item
+}
+
+// Item is one post of a feed
+type Item struct {
+	Title      string
+	Link       string
+	Summary    string
+	Author     string
+	Categories []string
+	Published  time.Time
+	Updated    time.Time
+}
+
+type rssDocument struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	AtomNS  string     `xml:"xmlns:atom,attr"`
+	DCNS    string     `xml:"xmlns:dc,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	AtomLink      rssLink   `xml:"atom:link"`
+	LastBuildDate string    `xml:"lastBuildDate,omitempty"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr"`
+	Type string `xml:"type,attr"`
+}
+
+type rssItem struct {
+	Title       string   `xml:"title"`
+	Link        string   `xml:"link"`
+	GUID        rssGUID  `xml:"guid"`
+	Description string   `xml:"description,omitempty"`
+	Author      string   `xml:"dc:creator,omitempty"`
+	Categories  []string `xml:"category"`
+	PubDate     string   `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	Value       string `xml:",chardata"`
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+}
+
+// RSS encodes the channel as an RSS 2.0 document
+func RSS(ch Channel) ([]byte, error) {
+	doc := rssDocument{
+		Version: "2.0",
+		AtomNS:  "http://www.w3.org/2005/Atom",
+		DCNS:    "http://purl.org/dc/elements/1.1/",
+		Channel: rssChannel{
+			Title:       ch.Title,
+			Link:        ch.Link,
+			Description: ch.Description,
+			AtomLink:    rssLink{Href: ch.FeedURL, Rel: "self", Type: "application/rss+xml"},
+		},
+	}
+	if !ch.Updated.IsZero() {
+		doc.Channel.LastBuildDate = ch.Updated.UTC().Format(time.RFC1123Z)
+	}
+
+	for _, item := range ch.Items {
+		doc.Channel.Items = append(doc.Channel.Items, rssItem{
+			Title:       item.Title,
+			Link:        item.Link,
+			GUID:        rssGUID{Value: item.Link, IsPermaLink: true},
+			Description: item.Summary,
+			Author:      item.Author,
+			Categories:  item.Categories,
+			PubDate:     item.Published.UTC().Format(time.RFC1123Z),
+		})
+	}
+
+	return encode(doc)
+}
+
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Links   []atomLink  `xml:"link"`
+	Updated string      `xml:"updated"`
+	Author  atomPerson  `xml:"author"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomLink struct {
+	Href string `xml:"href,attr"`
+	Rel  string `xml:"rel,attr,omitempty"`
+	Type string `xml:"type,attr,omitempty"`
+}
+
+type atomPerson struct {
+	Name string `xml:"name"`
+}
+
+type atomCategory struct {
+	Term string `xml:"term,attr"`
+}
+
+type atomEntry struct {
+	Title      string         `xml:"title"`
+	ID         string         `xml:"id"`
+	Link       atomLink       `xml:"link"`
+	Published  string         `xml:"published"`
+	Updated    string         `xml:"updated"`
+	Summary    string         `xml:"summary,omitempty"`
+	Author     *atomPerson    `xml:"author,omitempty"`
+	Categories []atomCategory `xml:"category"`
+}
+
+// Atom encodes the channel as an Atom 1.0 document
+func Atom(ch Channel) ([]byte, error) {
+	updated := ch.Updated
+	if updated.IsZero() {
+		updated = time.Now()
+	}
+
+	doc := atomFeed{
+		Title: ch.Title,
+		ID:    ch.FeedURL,
+		Links: []atomLink{
+			{Href: ch.Link, Rel: "alternate", Type: "text/html"},
+			{Href: ch.FeedURL, Rel: "self", Type: "application/atom+xml"},
+		},
+		Updated: updated.UTC().Format(time.RFC3339),
+		// Feed-level author covers entries without one, as Atom requires an author
+		Author: atomPerson{Name: ch.Title},
+	}
+
+	for _, item := range ch.Items {
+		entry := atomEntry{
+			Title:     item.Title,
+			ID:        item.Link,
+			Link:      atomLink{Href: item.Link, Rel: "alternate", Type: "text/html"},
+			Published: item.Published.UTC().Format(time.RFC3339),
+			Updated:   item.Updated.UTC().Format(time.RFC3339),
+			Summary:   item.Summary,
+		}
+		if item.Author != "" {
+			entry.Author = &atomPerson{Name: item.Author}
+		}
+		for _, category := range item.Categories {
+			entry.Categories = append(entry.Categories, atomCategory{Term: category})
+		}
+		doc.Entries = append(doc.Entries, entry)
+	}
+
+	return encode(doc)
+}
+
+// encode marshals a feed document with the XML header
+func encode(doc interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
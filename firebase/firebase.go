@@ -94,7 +94,7 @@ func InitializeFirebase(ctx context.Context) error {
 		return err
 	}
 
-	// Initialize Auth (optional, for future use)
+	// Initialize Auth (used to verify ID tokens on write routes)
 	AuthClient, err = app.Auth(ctx)
 	if err != nil {
 		log.Printf("Warning: Failed to initialize Auth client: %v", err)
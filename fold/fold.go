@@ -0,0 +1,47 @@
+// Package fold folds text to lowercase ASCII, so that Vietnamese and other
+// Latin-script words match with or without diacritics ("Hướng" folds to "huong").
+package fold
+
+import (
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// specialLetters folds letters that do not decompose into an ASCII letter and
+// combining marks
+var specialLetters = map[rune]string{
+	'đ': "d", 'ð': "d", 'ø': "o", 'ł': "l", 'ı': "i",
+	'ß': "ss", 'æ': "ae", 'œ': "oe", 'þ': "th", 'ŋ': "ng",
+}
+
+// Rune folds one rune. Letters and digits of the Latin script fold to lowercase
+// ASCII (possibly several letters: ß folds to "ss") and combining marks to "",
+// with inWord true. Any other rune separates words and returns inWord false.
+func Rune(r rune) (folded string, inWord bool) {
+	r = unicode.ToLower(r)
+	switch {
+	case r < unicode.MaxASCII:
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			return string(r), true
+		}
+		return "", false
+	case unicode.Is(unicode.Mn, r):
+		// Combining marks of decomposed text (u + horn) belong to the letter before them
+		return "", true
+	case specialLetters[r] != "":
+		return specialLetters[r], true
+	case !unicode.IsLetter(r):
+		return "", false
+	}
+
+	// NFD splits accented letters into a base letter and combining marks
+	// (ư = u + horn, ế = e + circumflex + acute); only the ASCII base is kept
+	var base []rune
+	for _, d := range norm.NFD.String(string(r)) {
+		if d < unicode.MaxASCII && unicode.IsLetter(d) {
+			base = append(base, d)
+		}
+	}
+	return string(base), len(base) > 0
+}
@@ -0,0 +1,71 @@
+package fold
+
+import (
+	"strings"
+	"testing"
+)
+
+// foldString folds text rune by rune, separating words with single spaces
+func foldString(text string) string {
+	var words []string
+	var word strings.Builder
+	for _, r := range text + " " {
+		folded, inWord := Rune(r)
+		if !inWord {
+			if word.Len() > 0 {
+				words = append(words, word.String())
+				word.Reset()
+			}
+			continue
+		}
+		word.WriteString(folded)
+	}
+	return strings.Join(words, " ")
+}
+
+func TestRune(t *testing.T) {
+	tests := []struct {
+		r      rune
+		want   string
+		inWord bool
+	}{
+		{'a', "a", true},
+		{'Z', "z", true},
+		{'7', "7", true},
+		{'ư', "u", true},
+		{'Ế', "e", true},
+		{'đ', "d", true},
+		{'Đ', "d", true},
+		{'ß', "ss", true},
+		{'\u0301', "", true}, // combining acute accent
+		{' ', "", false},
+		{'-', "", false},
+		{'入', "", false}, // other scripts have no ASCII base letter
+	}
+	for _, tt := range tests {
+		folded, inWord := Rune(tt.r)
+		if folded != tt.want || inWord != tt.inWord {
+			t.Errorf("Rune(%q) = %q, %v, want %q, %v", tt.r, folded, inWord, tt.want, tt.inWord)
+		}
+	}
+}
+
+func TestVietnameseFoldsLikeUnaccented(t *testing.T) {
+	tests := []struct {
+		text string
+		want string
+	}{
+		{"Hướng dẫn lập trình Go", "huong dan lap trinh go"},
+		{"Đường đi của Đạt", "duong di cua dat"},
+		{"Tổng hợp những lỗi thường gặp", "tong hop nhung loi thuong gap"},
+		{"Ưu tiên phương thức mới", "uu tien phuong thuc moi"},
+		// Decomposed text: base letters followed by combining marks
+		{"Hu\u031bo\u031b\u0301ng da\u0302\u0303n", "huong dan"},
+		{"huong dan lap trinh go", "huong dan lap trinh go"},
+	}
+	for _, tt := range tests {
+		if got := foldString(tt.text); got != tt.want {
+			t.Errorf("fold(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,225 @@
+// Package fracindex generates fractional index keys: strings that sort in the
+// order of the items they position, and between any two of which a new key can
+// always be generated, so that moving an item only rewrites that item.
+//
+// Keys use base-62 digits (0-9, A-Z, a-z, which sort in byte order). A key is
+// an integer part, whose first character encodes its length, followed by an
+// optional fraction without trailing zeros. Appending at either end increments
+// the integer part, so keys stay short for items added in order.
+package fracindex
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// smallestInteger is the lowest integer part, before which no key can be generated
+var smallestInteger = "A" + strings.Repeat("0", 26)
+
+// ErrInvalidKey is returned for malformed keys and bounds that are out of order
+var ErrInvalidKey = errors.New("invalid fractional index key")
+
+// First is the key of the first item of an empty sequence
+const First = "a0"
+
+// KeyBetween returns a key that sorts after a and before b. An empty a means
+// the start of the sequence and an empty b its end.
+func KeyBetween(a, b string) (string, error) {
+	if a != "" {
+		if err := validate(a); err != nil {
+			return "", err
+		}
+	}
+	if b != "" {
+		if err := validate(b); err != nil {
+			return "", err
+		}
+	}
+	if a != "" && b != "" && a >= b {
+		return "", fmt.Errorf("%w: %q is not before %q", ErrInvalidKey, a, b)
+	}
+
+	switch {
+	case a == "" && b == "":
+		return First, nil
+
+	case a == "":
+		ib := integerPart(b)
+		fb := b[len(ib):]
+		if ib == smallestInteger {
+			return ib + midpoint("", fb), nil
+		}
+		// The integer part alone sorts before b when b has a fraction
+		if ib < b {
+			return ib, nil
+		}
+		key, ok := decrementInteger(ib)
+		if !ok {
+			return "", fmt.Errorf("%w: no key before %q", ErrInvalidKey, b)
+		}
+		return key, nil
+
+	case b == "":
+		ia := integerPart(a)
+		fa := a[len(ia):]
+		if key, ok := incrementInteger(ia); ok {
+			return key, nil
+		}
+		return ia + midpoint(fa, ""), nil
+	}
+
+	ia := integerPart(a)
+	fa := a[len(ia):]
+	ib := integerPart(b)
+	fb := b[len(ib):]
+	if ia == ib {
+		return ia + midpoint(fa, fb), nil
+	}
+	key, ok := incrementInteger(ia)
+	if !ok {
+		return "", fmt.Errorf("%w: no key after %q", ErrInvalidKey, a)
+	}
+	if key < b {
+		return key, nil
+	}
+	return ia + midpoint(fa, ""), nil
+}
+
+// midpoint returns a fraction between the fractions a and b, where an empty b
+// means 1. a must sort before b and neither may end with a zero.
+func midpoint(a, b string) string {
+	if b != "" {
+		// Keep the common prefix, padding a with zeros
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			return b[:n] + midpoint(a[min(n, len(a)):], b[n:])
+		}
+	}
+
+	// The first digits differ
+	digitA := 0
+	if a != "" {
+		digitA = strings.IndexByte(digits, a[0])
+	}
+	digitB := len(digits)
+	if b != "" {
+		digitB = strings.IndexByte(digits, b[0])
+	}
+	if digitB-digitA > 1 {
+		return string(digits[(digitA+digitB+1)/2])
+	}
+
+	// The first digits are consecutive
+	if len(b) > 1 {
+		return b[:1]
+	}
+	rest := ""
+	if a != "" {
+		rest = a[1:]
+	}
+	return string(digits[digitA]) + midpoint(rest, "")
+}
+
+// digitAt returns the digit of fraction s at index i, zero past its end
+func digitAt(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return '0'
+}
+
+// integerLength returns the length of an integer part starting with head:
+// a-z are non-negative integers of 2 to 27 characters, A-Z negative ones of 27 to 2
+func integerLength(head byte) (int, bool) {
+	switch {
+	case head >= 'a' && head <= 'z':
+		return int(head-'a') + 2, true
+	case head >= 'A' && head <= 'Z':
+		return int('Z'-head) + 2, true
+	}
+	return 0, false
+}
+
+func integerPart(key string) string {
+	n, _ := integerLength(key[0])
+	return key[:n]
+}
+
+func validate(key string) error {
+	n, ok := integerLength(key[0])
+	if !ok || len(key) < n || key == smallestInteger {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	for i := 1; i < len(key); i++ {
+		if strings.IndexByte(digits, key[i]) < 0 {
+			return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+		}
+	}
+	if strings.HasSuffix(key[n:], "0") {
+		return fmt.Errorf("%w: %q", ErrInvalidKey, key)
+	}
+	return nil
+}
+
+// incrementInteger returns the integer part following x, false after the largest one
+func incrementInteger(x string) (string, bool) {
+	head, digs := x[0], []byte(x[1:])
+	for i := len(digs) - 1; i >= 0; i-- {
+		d := strings.IndexByte(digits, digs[i]) + 1
+		if d < len(digits) {
+			digs[i] = digits[d]
+			return string(head) + string(digs), true
+		}
+		digs[i] = '0'
+	}
+
+	// Every digit carried over: move to the next length
+	switch head {
+	case 'Z':
+		return "a0", true
+	case 'z':
+		return "", false
+	}
+	head++
+	if head > 'a' {
+		digs = append(digs, '0')
+	} else {
+		digs = digs[:len(digs)-1]
+	}
+	return string(head) + string(digs), true
+}
+
+// decrementInteger returns the integer part before x, false before the smallest one
+func decrementInteger(x string) (string, bool) {
+	head, digs := x[0], []byte(x[1:])
+	last := digits[len(digits)-1]
+	for i := len(digs) - 1; i >= 0; i-- {
+		d := strings.IndexByte(digits, digs[i]) - 1
+		if d >= 0 {
+			digs[i] = digits[d]
+			return string(head) + string(digs), true
+		}
+		digs[i] = last
+	}
+
+	// Every digit borrowed: move to the previous length
+	switch head {
+	case 'a':
+		return "Z" + string(last), true
+	case 'A':
+		return "", false
+	}
+	head--
+	if head < 'Z' {
+		digs = append(digs, last)
+	} else {
+		digs = digs[:len(digs)-1]
+	}
+	return string(head) + string(digs), true
+}
@@ -0,0 +1,145 @@
+package fracindex
+
+import (
+	"errors"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func TestKeyBetween(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+		want string
+	}{
+		{"empty sequence", "", "", "a0"},
+		{"append", "a0", "", "a1"},
+		{"prepend", "", "a0", "Zz"},
+		{"between integers", "a0", "a2", "a1"},
+		{"between consecutive integers", "a0", "a1", "a0V"},
+		{"between fractions", "a0V", "a1", "a0l"},
+		{"append after the largest digit", "az", "", "b00"},
+		{"prepend before the smallest digit", "", "b00", "az"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := KeyBetween(tt.a, tt.b)
+			if err != nil {
+				t.Fatalf("KeyBetween(%q, %q): %v", tt.a, tt.b, err)
+			}
+			if got != tt.want {
+				t.Errorf("KeyBetween(%q, %q) = %q, want %q", tt.a, tt.b, got, tt.want)
+			}
+			checkBetween(t, tt.a, got, tt.b)
+		})
+	}
+}
+
+func TestKeyBetweenInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		a, b string
+	}{
+		{"out of order", "a2", "a1"},
+		{"equal", "a1", "a1"},
+		{"bad head", "!0", ""},
+		{"too short", "b0", ""},
+		{"trailing zero", "", "a10"},
+		{"bad digit", "a-", ""},
+		{"smallest integer", smallestInteger, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if key, err := KeyBetween(tt.a, tt.b); !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("KeyBetween(%q, %q) = %q, %v, want ErrInvalidKey", tt.a, tt.b, key, err)
+			}
+		})
+	}
+}
+
+func TestKeyBetweenAppendAndPrepend(t *testing.T) {
+	last, first := First, First
+	for i := 0; i < 10000; i++ {
+		next, err := KeyBetween(last, "")
+		if err != nil {
+			t.Fatalf("append %d: %v", i, err)
+		}
+		checkBetween(t, last, next, "")
+		last = next
+
+		prev, err := KeyBetween("", first)
+		if err != nil {
+			t.Fatalf("prepend %d: %v", i, err)
+		}
+		checkBetween(t, "", prev, first)
+		first = prev
+	}
+	// Keys added in order only grow the integer part
+	if len(last) > 4 || len(first) > 4 {
+		t.Errorf("keys grew to %q and %q", first, last)
+	}
+}
+
+func TestKeyBetweenSameGap(t *testing.T) {
+	// Inserting repeatedly right after a, or right before b, keeps finding keys
+	lower, upper := "a0", "a1"
+	for i := 0; i < 500; i++ {
+		key, err := KeyBetween(lower, upper)
+		if err != nil {
+			t.Fatalf("insert %d: %v", i, err)
+		}
+		checkBetween(t, lower, key, upper)
+		if i%2 == 0 {
+			upper = key
+		} else {
+			lower = key
+		}
+	}
+
+	upper = "a1"
+	for i := 0; i < 500; i++ {
+		key, err := KeyBetween("a0", upper)
+		if err != nil {
+			t.Fatalf("insert %d: %v", i, err)
+		}
+		checkBetween(t, "a0", key, upper)
+		upper = key
+	}
+}
+
+func TestKeyBetweenRandomInserts(t *testing.T) {
+	rng := rand.New(rand.NewSource(1))
+	keys := []string{}
+	for i := 0; i < 2000; i++ {
+		at := rng.Intn(len(keys) + 1)
+		lower, upper := "", ""
+		if at > 0 {
+			lower = keys[at-1]
+		}
+		if at < len(keys) {
+			upper = keys[at]
+		}
+		key, err := KeyBetween(lower, upper)
+		if err != nil {
+			t.Fatalf("insert %d between %q and %q: %v", i, lower, upper, err)
+		}
+		checkBetween(t, lower, key, upper)
+		keys = append(keys[:at], append([]string{key}, keys[at:]...)...)
+	}
+	if !sort.StringsAreSorted(keys) {
+		t.Error("keys are not sorted")
+	}
+}
+
+// checkBetween checks that key is a valid key strictly between a and b, where ""
+// is unbounded
+func checkBetween(t *testing.T, a, key, b string) {
+	t.Helper()
+	if err := validate(key); err != nil {
+		t.Fatalf("generated invalid key: %v", err)
+	}
+	if (a != "" && key <= a) || (b != "" && key >= b) {
+		t.Fatalf("key %q is not between %q and %q", key, a, b)
+	}
+}
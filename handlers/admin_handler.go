@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"apigo1/middleware"
+	"apigo1/models"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"firebase.google.com/go/v4/auth"
+	"github.com/gorilla/mux"
+)
+
+// AdminHandler handles administrative HTTP requests
+type AdminHandler struct {
+	roles middleware.RoleAssigner
+}
+
+// NewAdminHandler creates a new AdminHandler
+func NewAdminHandler(roles middleware.RoleAssigner) *AdminHandler {
+	return &AdminHandler{roles: roles}
+}
+
+// SetUserRole handles PUT /admin/users/{uid}/role
+// @Summary      Gán role cho user
+// @Description  Gán role (reader, author, editor, admin) cho user qua Firebase custom claims, giữ nguyên các custom claims khác. Chỉ admin. User cần refresh ID token để nhận role mới
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        uid   path      string                 true  "Firebase UID"
+// @Param        role  body      models.SetRoleRequest  true  "Role"
+// @Success      200   {object}  Response
+// @Failure      400   {object}  Response
+// @Failure      401   {object}  Response
+// @Failure      403   {object}  Response
+// @Failure      404   {object}  Response
+// @Failure      500   {object}  Response
+// @Router       /admin/users/{uid}/role [put]
+func (h *AdminHandler) SetUserRole(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	if !user.HasRole(middleware.RoleAdmin) {
+		writeError(w, http.StatusForbidden, "Only admins can assign roles")
+		return
+	}
+
+	var req models.SetRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	role, ok := middleware.ParseRole(req.Role)
+	if !ok {
+		writeError(w, http.StatusBadRequest, "Role must be one of reader, author, editor, admin")
+		return
+	}
+
+	uid := mux.Vars(r)["uid"]
+	if err := middleware.AssignRole(r.Context(), h.roles, uid, role); err != nil {
+		if auth.IsUserNotFound(err) {
+			writeError(w, http.StatusNotFound, "User not found")
+			return
+		}
+		log.Printf("Failed to set role for user %s: %v", uid, err)
+		writeError(w, http.StatusInternalServerError, "Failed to assign role")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"message": "Role assigned successfully",
+	})
+}
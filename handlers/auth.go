@@ -0,0 +1,16 @@
+package handlers
+
+import (
+	"apigo1/middleware"
+	"net/http"
+)
+
+// currentUser returns the authenticated caller, writing a 401 response when
+// the request did not go through the authentication middleware
+func currentUser(w http.ResponseWriter, r *http.Request) (*middleware.User, bool) {
+	user, ok := middleware.UserFromContext(r.Context())
+	if !ok {
+		writeError(w, http.StatusUnauthorized, "Authentication required")
+	}
+	return user, ok
+}
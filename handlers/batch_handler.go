@@ -0,0 +1,316 @@
+package handlers
+
+import (
+	"apigo1/middleware"
+	"apigo1/models"
+	"apigo1/store"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// BatchTodos handles POST /todos:batch
+// @Summary      Tạo, cập nhật và xóa nhiều todos
+// @Description  Thực hiện tối đa 100 thao tác create/update/delete trên todos của user hiện tại trong một request (một transaction Firestore) và trả về kết quả của từng thao tác. Field todo của thao tác có body như POST /todos (create) hoặc PUT /todos/{id} (update); mỗi todo chỉ được xuất hiện trong một thao tác. Với atomic=true, khi một thao tác lỗi thì không thao tác nào được áp dụng: request trả về status của thao tác lỗi và các thao tác khác có status 424. Không có atomic, các thao tác lỗi được bỏ qua và request trả về 200
+// @Tags         todos
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        batch  body      models.TodoBatchRequest  true  "Operations"
+// @Success      200    {object}  Response{data=models.BatchResponse}
+// @Failure      400    {object}  Response{data=models.BatchResponse}
+// @Failure      401    {object}  Response
+// @Failure      404    {object}  Response{data=models.BatchResponse}
+// @Failure      409    {object}  Response{data=models.BatchResponse}
+// @Failure      503    {object}  Response
+// @Router       /todos:batch [post]
+func (h *TodoHandler) BatchTodos(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.TodoBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if !checkBatchSize(w, len(req.Operations)) {
+		return
+	}
+
+	results := make([]models.BatchResult, len(req.Operations))
+	var ops []store.TodoBatchOp
+	var indexes []int // Index in results of each operation sent to the store
+	targeted := make(map[int]bool)
+	for i, op := range req.Operations {
+		results[i] = models.BatchResult{Index: i, Op: op.Op, ID: op.ID}
+		batchOp, err := h.batchOp(r.Context(), user.UID, op, targeted)
+		if err != nil {
+			failBatchResult(&results[i], err, "Todo not found")
+			continue
+		}
+		ops = append(ops, batchOp)
+		indexes = append(indexes, i)
+	}
+
+	// An atomic batch with an invalid operation does not reach the store
+	if !abortBatchResults(results, req.Atomic) && len(ops) > 0 {
+		applied, err := h.store.Batch(r.Context(), user.UID, ops, req.Atomic)
+		if err != nil {
+			writeStoreError(w, err, "Todo not found")
+			return
+		}
+		for k, result := range applied {
+			i := indexes[k]
+			if result.Err != nil {
+				failBatchResult(&results[i], result.Err, "Todo not found")
+				continue
+			}
+			results[i].Status = http.StatusOK
+			if result.Todo != nil {
+				results[i].ID = result.Todo.ID
+				results[i].Data = result.Todo
+			}
+			if ops[k].Type == models.BatchCreate {
+				results[i].Status = http.StatusCreated
+			}
+		}
+	}
+
+	writeBatchResponse(w, req.Atomic, results)
+}
+
+// batchOp checks an operation of a todo batch of ownerID like the matching single
+// request and returns it as a store operation
+func (h *TodoHandler) batchOp(ctx context.Context, ownerID string, op models.TodoBatchOperation, targeted map[int]bool) (store.TodoBatchOp, error) {
+	batchOp := store.TodoBatchOp{Type: op.Op, ID: op.ID}
+	if err := checkBatchOperation(op.Op, op.ID, targeted); err != nil {
+		return batchOp, err
+	}
+
+	var err error
+	switch op.Op {
+	case models.BatchCreate:
+		var req models.CreateTodoRequest
+		if err = decodeBatchData(op.Todo, "todo", &req); err == nil {
+			batchOp.Todo, err = h.newTodo(ctx, ownerID, &req)
+		}
+
+	case models.BatchUpdate:
+		var req models.UpdateTodoRequest
+		if err = decodeBatchData(op.Todo, "todo", &req); err == nil {
+			batchOp.Change, err = h.todoChanges(ctx, ownerID, &req)
+		}
+	}
+	return batchOp, err
+}
+
+// BatchBlogs handles POST /blogs:batch
+// @Summary      Tạo, cập nhật và xóa nhiều blogs
+// @Description  Thực hiện tối đa 100 thao tác create/update/delete trên blogs trong một request (một transaction Firestore) và trả về kết quả của từng thao tác. Field blog của thao tác có body như POST /blogs (create) hoặc PUT /blogs/{id} (update), với cùng quyền như các request đó; mỗi blog chỉ được xuất hiện trong một thao tác. Với atomic=true, khi một thao tác lỗi thì không thao tác nào được áp dụng: request trả về status của thao tác lỗi và các thao tác khác có status 424. Không có atomic, các thao tác lỗi được bỏ qua và request trả về 200
+// @Tags         blogs
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        batch  body      models.BlogBatchRequest  true  "Operations"
+// @Success      200    {object}  Response{data=models.BatchResponse}
+// @Failure      400    {object}  Response{data=models.BatchResponse}
+// @Failure      401    {object}  Response
+// @Failure      403    {object}  Response{data=models.BatchResponse}
+// @Failure      404    {object}  Response{data=models.BatchResponse}
+// @Failure      409    {object}  Response{data=models.BatchResponse}
+// @Failure      503    {object}  Response
+// @Router       /blogs:batch [post]
+func (h *BlogHandler) BatchBlogs(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.BlogBatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if !checkBatchSize(w, len(req.Operations)) {
+		return
+	}
+
+	results := make([]models.BatchResult, len(req.Operations))
+	var ops []store.BlogBatchOp
+	var indexes []int // Index in results of each operation sent to the store
+	targeted := make(map[int]bool)
+	for i, op := range req.Operations {
+		results[i] = models.BatchResult{Index: i, Op: op.Op, ID: op.ID}
+		batchOp, err := h.batchOp(user, op, targeted)
+		if err != nil {
+			failBatchResult(&results[i], err, "Blog not found")
+			continue
+		}
+		ops = append(ops, batchOp)
+		indexes = append(indexes, i)
+	}
+
+	// An atomic batch with an invalid operation does not reach the store
+	if !abortBatchResults(results, req.Atomic) && len(ops) > 0 {
+		applied, err := h.store.Batch(r.Context(), ops, req.Atomic)
+		if err != nil {
+			writeStoreError(w, err, "Blog not found")
+			return
+		}
+		for k, result := range applied {
+			i := indexes[k]
+			if result.Err != nil {
+				failBatchResult(&results[i], result.Err, "Blog not found")
+				continue
+			}
+			results[i].Status = http.StatusOK
+			if result.Blog != nil {
+				results[i].ID = result.Blog.ID
+				results[i].Data = result.Blog
+			}
+			if ops[k].Type == models.BatchCreate {
+				results[i].Status = http.StatusCreated
+			}
+		}
+	}
+
+	writeBatchResponse(w, req.Atomic, results)
+}
+
+// batchOp checks an operation of a blog batch by user like the matching single
+// request and returns it as a store operation
+func (h *BlogHandler) batchOp(user *middleware.User, op models.BlogBatchOperation, targeted map[int]bool) (store.BlogBatchOp, error) {
+	batchOp := store.BlogBatchOp{Type: op.Op, ID: op.ID}
+	if err := checkBatchOperation(op.Op, op.ID, targeted); err != nil {
+		return batchOp, err
+	}
+
+	var err error
+	switch op.Op {
+	case models.BatchCreate:
+		if !canCreateBlog(user) {
+			return batchOp, forbidden("Only authors, editors and admins can create blogs")
+		}
+		var req models.CreateBlogRequest
+		if err = decodeBatchData(op.Blog, "blog", &req); err == nil {
+			batchOp.Blog, err = h.newBlog(user, &req)
+		}
+
+	case models.BatchUpdate:
+		var req models.UpdateBlogRequest
+		if err = decodeBatchData(op.Blog, "blog", &req); err == nil {
+			batchOp.Change, err = h.blogChanges(user, &req)
+		}
+
+	case models.BatchDelete:
+		if !canDeleteBlog(user) {
+			return batchOp, forbidden("Only editors and admins can delete blogs")
+		}
+	}
+	return batchOp, err
+}
+
+// checkBatchSize checks the number of operations of a batch, writing a 400
+// response when there are none or too many
+func checkBatchSize(w http.ResponseWriter, n int) bool {
+	if n == 0 {
+		writeError(w, http.StatusBadRequest, "operations is required")
+		return false
+	}
+	if n > store.MaxBatchOperations {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("A batch can have at most %d operations", store.MaxBatchOperations))
+		return false
+	}
+	return true
+}
+
+// checkBatchOperation checks the type and target of a batch operation. An item may
+// only be updated or deleted by one operation of a batch; targeted collects the
+// items of the operations checked so far.
+func checkBatchOperation(op models.BatchOp, id int, targeted map[int]bool) error {
+	switch op {
+	case models.BatchCreate:
+		return nil
+	case models.BatchUpdate, models.BatchDelete:
+		if id <= 0 {
+			return badRequest("id is required for update and delete operations")
+		}
+		if targeted[id] {
+			return badRequest(fmt.Sprintf("%d is the target of more than one operation", id))
+		}
+		targeted[id] = true
+		return nil
+	}
+	return badRequest("op must be one of create, update, delete")
+}
+
+// decodeBatchData decodes the body of a create or update operation into v. name is
+// the field holding it.
+func decodeBatchData(data json.RawMessage, name string, v interface{}) error {
+	if len(data) == 0 {
+		return badRequest(name + " is required for create and update operations")
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		return badRequest("Invalid " + name)
+	}
+	return nil
+}
+
+// failBatchResult records the error of a batch operation in its result
+func failBatchResult(result *models.BatchResult, err error, notFoundMessage string) {
+	result.Status, result.Error = errorStatus(err, notFoundMessage)
+	result.Data = nil
+}
+
+// abortBatchResults reports whether an atomic batch has a failed operation, and then
+// marks the other operations as not applied
+func abortBatchResults(results []models.BatchResult, atomic bool) bool {
+	if !atomic {
+		return false
+	}
+	failed := false
+	for _, result := range results {
+		if result.Error != "" {
+			failed = true
+			break
+		}
+	}
+	if !failed {
+		return false
+	}
+	for i := range results {
+		if results[i].Error == "" {
+			failBatchResult(&results[i], store.ErrBatchAborted, "")
+		}
+	}
+	return true
+}
+
+// writeBatchResponse writes the results of a batch. A failed atomic batch gets the
+// status of its first failed operation; any other batch gets 200 OK, with the
+// outcome of each operation in its result.
+func writeBatchResponse(w http.ResponseWriter, atomic bool, results []models.BatchResult) {
+	response := Response{Success: true}
+	status := http.StatusOK
+	batch := models.BatchResponse{Atomic: atomic, Results: results}
+	for _, result := range results {
+		if result.Error == "" {
+			batch.Succeeded++
+			continue
+		}
+		batch.Failed++
+		if atomic && response.Success && result.Status != http.StatusFailedDependency {
+			status = result.Status
+			response.Success = false
+			response.Error = fmt.Sprintf("Operation %d failed: %s", result.Index, result.Error)
+		}
+	}
+	response.Data = batch
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"apigo1/middleware"
+	"apigo1/store"
+	"encoding/json"
+	"net/http"
+)
+
+// ListAdminBlogs handles GET /admin/blogs
+// @Summary      Lấy danh sách blogs cho trang quản trị
+// @Description  Trả về blogs đầy đủ, gồm cả bản nháp, blogs đang hẹn giờ publish và metadata nội bộ (author_id, revision, updated_by, publish_at). Author chỉ thấy blogs của mình; editor/admin thấy tất cả
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        limit      query     int     false  "Số blogs mỗi trang (mặc định 20, tối đa 100)"
+// @Param        cursor     query     string  false  "Cursor trả về từ trang trước (next_cursor)"
+// @Param        published  query     bool    false  "Lọc theo trạng thái published"
+// @Param        tag        query     string  false  "Lọc các blogs có tag này"
+// @Param        author     query     string  false  "Lọc theo tác giả"
+// @Param        author_id  query     string  false  "Lọc theo UID của tác giả"
+// @Param        sort       query     string  false  "Sắp xếp theo các field id, title, author, created_at, updated_at, cách nhau bởi dấu phẩy; thêm '-' để sắp xếp giảm dần (vd: -created_at)"
+// @Success      200        {object}  Response{data=[]models.Blog}
+// @Failure      400        {object}  Response
+// @Failure      401        {object}  Response
+// @Failure      403        {object}  Response
+// @Failure      503        {object}  Response
+// @Router       /admin/blogs [get]
+func (h *BlogHandler) ListAdminBlogs(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	if !user.HasRole(middleware.RoleAuthor) {
+		writeError(w, http.StatusForbidden, "Only authors, editors and admins can manage blogs")
+		return
+	}
+
+	opts, err := parseListQuery(r, adminBlogQueryFields)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	// Authors only manage their own blogs
+	if !user.HasRole(middleware.RoleEditor) {
+		opts.Filters = append(opts.Filters, store.Filter{Field: "author_id", Op: "==", Value: user.UID})
+	}
+
+	blogs, nextCursor, err := h.store.List(r.Context(), opts)
+	if err != nil {
+		writeStoreError(w, err, "Blog not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":     true,
+		"data":        blogs,
+		"next_cursor": nextCursor,
+		"has_more":    nextCursor != "",
+	})
+}
+
+// GetAdminBlog handles GET /admin/blogs/{id}
+// @Summary      Lấy blog cho trang quản trị
+// @Description  Trả về blog đầy đủ theo ID, kể cả bản nháp và blog đang hẹn giờ publish. Cần quyền sửa blog
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Blog ID"
+// @Success      200  {object}  Response{data=models.Blog}
+// @Failure      400  {object}  Response
+// @Failure      401  {object}  Response
+// @Failure      403  {object}  Response
+// @Failure      404  {object}  Response
+// @Failure      503  {object}  Response
+// @Router       /admin/blogs/{id} [get]
+func (h *BlogHandler) GetAdminBlog(w http.ResponseWriter, r *http.Request) {
+	blog, ok := h.editableBlog(w, r)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    blog,
+	})
+}
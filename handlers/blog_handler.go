@@ -1,12 +1,17 @@
 package handlers
 
 import (
+	"apigo1/markdown"
+	"apigo1/middleware"
 	"apigo1/models"
+	"apigo1/slug"
 	"apigo1/store"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"net/url"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/gorilla/mux"
@@ -14,154 +19,242 @@ import (
 
 // BlogHandler handles blog-related HTTP requests
 type BlogHandler struct {
-	store *store.BlogStore
+	store   store.BlogStoreInterface
+	slugs   *slug.Slugger
+	renders *renderCache
 }
 
-// NewBlogHandler creates a new BlogHandler
-func NewBlogHandler(s *store.BlogStore) *BlogHandler {
-	return &BlogHandler{store: s}
+// NewBlogHandler creates a new BlogHandler. slugs generates the slugs of blogs
+// from their titles and normalizes slugs given in requests.
+func NewBlogHandler(s store.BlogStoreInterface, slugs *slug.Slugger) *BlogHandler {
+	return &BlogHandler{
+		store:   s,
+		slugs:   slugs,
+		renders: newRenderCache(),
+	}
 }
 
 // GetAllBlogs handles GET /blogs
-// @Summary      Lấy tất cả blogs
-// @Description  Trả về danh sách tất cả blogs
+// @Summary      Lấy danh sách blogs đã published
+// @Description  Trả về danh sách blogs đã published theo trang (mặc định mới nhất trước), có thể lọc và sắp xếp. Mỗi blog chỉ gồm tóm tắt (excerpt) thay cho toàn bộ nội dung. Dùng next_cursor của trang trước làm cursor để lấy trang tiếp theo
 // @Tags         blogs
 // @Accept       json
 // @Produce      json
-// @Success      200  {object}  Response{data=[]models.Blog}
+// @Param        limit      query     int     false  "Số blogs mỗi trang (mặc định 20, tối đa 100)"
+// @Param        cursor     query     string  false  "Cursor trả về từ trang trước (next_cursor)"
+// @Param        published  query     bool    false  "Chỉ nhận true (danh sách công khai chỉ gồm blogs đã published)"
+// @Param        tag        query     string  false  "Lọc các blogs có tag này"
+// @Param        author     query     string  false  "Lọc theo tác giả"
+// @Param        sort       query     string  false  "Sắp xếp theo các field id, title, author, created_at, updated_at, cách nhau bởi dấu phẩy; thêm '-' để sắp xếp giảm dần (vd: -created_at)"
+// @Success      200        {object}  Response{data=[]models.BlogSummary}
+// @Failure      400        {object}  Response
+// @Failure      503        {object}  Response
 // @Router       /blogs [get]
 func (h *BlogHandler) GetAllBlogs(w http.ResponseWriter, r *http.Request) {
-	blogs := h.store.GetAll()
+	opts, err := parseListQuery(r, blogQueryFields)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	opts.Filters = append(opts.Filters, store.Filter{Field: "published", Op: "==", Value: true})
+
+	blogs, nextCursor, err := h.store.List(r.Context(), opts)
+	if err != nil {
+		writeStoreError(w, err, "Blog not found")
+		return
+	}
+
+	summaries := make([]*models.BlogSummary, 0, len(blogs))
+	for _, blog := range blogs {
+		summaries = append(summaries, blogSummary(blog, h.renders))
+	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"data":    blogs,
+		"success":     true,
+		"data":        summaries,
+		"next_cursor": nextCursor,
+		"has_more":    nextCursor != "",
 	})
 }
 
 // GetBlogByID handles GET /blogs/{id}
 // @Summary      Lấy blog theo ID
-// @Description  Trả về thông tin blog theo ID
+// @Description  Trả về blog đã published theo ID. Blog chưa published trả về 404
 // @Tags         blogs
 // @Accept       json
 // @Produce      json
 // @Param        id   path      int  true  "Blog ID"
-// @Success      200  {object}  Response{data=models.Blog}
+// @Success      200  {object}  Response{data=models.PublicBlog}
 // @Failure      400  {object}  Response
 // @Failure      404  {object}  Response
+// @Failure      503  {object}  Response
 // @Router       /blogs/{id} [get]
 func (h *BlogHandler) GetBlogByID(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid blog ID", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, "Invalid blog ID")
 		return
 	}
 
-	blog, exists := h.store.GetByID(id)
-	if !exists {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Blog not found",
-		})
+	blog, err := h.store.GetByID(r.Context(), id)
+	if err != nil {
+		writeStoreError(w, err, "Blog not found")
+		return
+	}
+	if !blog.Published {
+		writeError(w, http.StatusNotFound, "Blog not found")
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
-		"data":    blog,
+		"data":    publicBlog(blog),
 	})
 }
 
 // GetBlogBySlug handles GET /blogs/slug/{slug}
 // @Summary      Lấy blog theo slug
-// @Description  Trả về thông tin blog theo slug (URL-friendly identifier)
+// @Description  Trả về blog đã published theo slug (URL-friendly identifier). Với format=html, data có dạng models.RenderedBlog (HTML đã sanitize, kèm mục lục). Slug cũ của blog trả về 301 với header Location trỏ tới slug hiện tại
 // @Tags         blogs
 // @Accept       json
 // @Produce      json
-// @Param        slug   path      string  true  "Blog Slug"
-// @Success      200    {object}  Response{data=models.Blog}
-// @Failure      404    {object}  Response
+// @Param        slug    path      string  true   "Blog Slug"
+// @Param        format  query     string  false  "markdown (mặc định) hoặc html để nhận nội dung đã render sang HTML"  Enums(markdown, html)
+// @Success      200     {object}  Response{data=models.PublicBlog}
+// @Success      301     {object}  map[string]interface{}
+// @Failure      400     {object}  Response
+// @Failure      404     {object}  Response
+// @Failure      503    {object}  Response
 // @Router       /blogs/slug/{slug} [get]
 func (h *BlogHandler) GetBlogBySlug(w http.ResponseWriter, r *http.Request) {
 	vars := mux.Vars(r)
 	slug := vars["slug"]
 
-	blog, exists := h.store.GetBySlug(slug)
-	if !exists {
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "markdown" && format != "html" {
+		writeError(w, http.StatusBadRequest, "format must be markdown or html")
+		return
+	}
+
+	blog, err := h.store.GetBySlug(r.Context(), slug)
+	if err != nil {
+		writeStoreError(w, err, "Blog not found")
+		return
+	}
+	if !blog.Published {
+		writeError(w, http.StatusNotFound, "Blog not found")
+		return
+	}
+
+	// Old slugs permanently redirect to the current one, so published links keep working
+	if blog.Slug != slug {
+		location := "/api/blogs/slug/" + url.PathEscape(blog.Slug)
+		if r.URL.RawQuery != "" {
+			location += "?" + r.URL.RawQuery
+		}
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
+		w.Header().Set("Location", location)
+		w.WriteHeader(http.StatusMovedPermanently)
 		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Blog not found",
+			"success":  true,
+			"slug":     blog.Slug,
+			"location": location,
 		})
 		return
 	}
 
+	var data interface{} = publicBlog(blog)
+	if format == "html" {
+		data = h.renders.render(blog)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
-		"data":    blog,
+		"data":    data,
+	})
+}
+
+// GetBlogHTML handles GET /blogs/{id}/html
+// @Summary      Lấy blog đã render sang HTML
+// @Description  Trả về nội dung Markdown của blog đã published, được render và sanitize thành HTML trên server, kèm heading anchors và mục lục (table of contents)
+// @Tags         blogs
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Blog ID"
+// @Success      200  {object}  Response{data=models.RenderedBlog}
+// @Failure      400  {object}  Response
+// @Failure      404  {object}  Response
+// @Failure      503  {object}  Response
+// @Router       /blogs/{id}/html [get]
+func (h *BlogHandler) GetBlogHTML(w http.ResponseWriter, r *http.Request) {
+	vars := mux.Vars(r)
+	id, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid blog ID")
+		return
+	}
+
+	blog, err := h.store.GetByID(r.Context(), id)
+	if err != nil {
+		writeStoreError(w, err, "Blog not found")
+		return
+	}
+	if !blog.Published {
+		writeError(w, http.StatusNotFound, "Blog not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    h.renders.render(blog),
 	})
 }
 
 // CreateBlog handles POST /blogs
 // @Summary      Tạo blog mới
-// @Description  Tạo một blog mới với nội dung Markdown
+// @Description  Tạo một blog mới với nội dung Markdown. Cần role author trở lên; chỉ editor/admin được tạo blog đã published hoặc hẹn giờ publish (publish_at). Nội dung tối đa 200 KB và lồng blockquote/list tối đa 16 cấp
 // @Tags         blogs
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
 // @Param        blog  body      models.CreateBlogRequest  true  "Blog information"
 // @Success      201   {object}  Response{data=models.Blog}
 // @Failure      400   {object}  Response
+// @Failure      401   {object}  Response
+// @Failure      403   {object}  Response
+// @Failure      409   {object}  Response
+// @Failure      503   {object}  Response
 // @Router       /blogs [post]
 func (h *BlogHandler) CreateBlog(w http.ResponseWriter, r *http.Request) {
-	var req models.CreateBlogRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+	user, ok := currentUser(w, r)
+	if !ok {
 		return
 	}
-
-	if req.Title == "" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Title is required",
-		})
+	if !canCreateBlog(user) {
+		writeError(w, http.StatusForbidden, "Only authors, editors and admins can create blogs")
 		return
 	}
 
-	// Generate slug from title if not provided
-	slug := req.Slug
-	if slug == "" {
-		slug = generateSlug(req.Title)
+	var req models.CreateBlogRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
 	}
 
-	now := time.Now()
-	blog := &models.Blog{
-		Title:     req.Title,
-		Content:   req.Content,
-		Slug:      slug,
-		Author:    req.Author,
-		Published: req.Published,
-		Tags:      req.Tags,
-		CreatedAt: now,
-		UpdatedAt: now,
+	blog, err := h.newBlog(user, &req)
+	if err != nil {
+		writeStoreError(w, err, "Blog not found")
+		return
 	}
 
-	createdBlog := h.store.Create(blog)
-	if createdBlog == nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Failed to create blog",
-		})
+	createdBlog, err := h.store.Create(r.Context(), blog)
+	if err != nil {
+		writeStoreError(w, err, "Blog not found")
 		return
 	}
 
@@ -175,77 +268,52 @@ func (h *BlogHandler) CreateBlog(w http.ResponseWriter, r *http.Request) {
 
 // UpdateBlog handles PUT /blogs/{id}
 // @Summary      Cập nhật blog
-// @Description  Cập nhật thông tin blog theo ID
+// @Description  Cập nhật thông tin blog theo ID. Author chỉ sửa được blog của mình; chỉ editor/admin được đổi trạng thái published hoặc lịch publish (publish_at, chuỗi rỗng để hủy). Nội dung tối đa 200 KB và lồng blockquote/list tối đa 16 cấp
 // @Tags         blogs
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
 // @Param        id    path      int                      true  "Blog ID"
 // @Param        blog  body      models.UpdateBlogRequest  true  "Updated blog information"
 // @Success      200   {object}  Response{data=models.Blog}
 // @Failure      400   {object}  Response
+// @Failure      401   {object}  Response
+// @Failure      403   {object}  Response
 // @Failure      404   {object}  Response
+// @Failure      409   {object}  Response
+// @Failure      503   {object}  Response
 // @Router       /blogs/{id} [put]
 func (h *BlogHandler) UpdateBlog(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid blog ID", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, "Invalid blog ID")
 		return
 	}
 
 	var req models.UpdateBlogRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
-	existingBlog, exists := h.store.GetByID(id)
-	if !exists {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Blog not found",
-		})
+	change, err := h.blogChanges(user, &req)
+	if err != nil {
+		writeStoreError(w, err, "Blog not found")
 		return
 	}
 
-	updatedBlog := &models.Blog{
-		ID:        existingBlog.ID,
-		Title:     existingBlog.Title,
-		Content:   existingBlog.Content,
-		Slug:      existingBlog.Slug,
-		Author:    existingBlog.Author,
-		Published: existingBlog.Published,
-		Tags:      existingBlog.Tags,
-		UpdatedAt: time.Now(),
-	}
-
-	// Update fields if provided
-	if req.Title != nil {
-		updatedBlog.Title = *req.Title
-	}
-	if req.Content != nil {
-		updatedBlog.Content = *req.Content
-	}
-	if req.Slug != nil {
-		updatedBlog.Slug = *req.Slug
-	} else if req.Title != nil {
-		// Regenerate slug if title changed but slug not provided
-		updatedBlog.Slug = generateSlug(*req.Title)
-	}
-	if req.Author != nil {
-		updatedBlog.Author = *req.Author
-	}
-	if req.Published != nil {
-		updatedBlog.Published = *req.Published
-	}
-	if req.Tags != nil {
-		updatedBlog.Tags = *req.Tags
+	blog, err := h.store.Update(r.Context(), id, change)
+	if err != nil {
+		writeStoreError(w, err, "Blog not found")
+		return
 	}
 
-	blog, _ := h.store.Update(id, updatedBlog)
-
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
@@ -255,31 +323,38 @@ func (h *BlogHandler) UpdateBlog(w http.ResponseWriter, r *http.Request) {
 
 // DeleteBlog handles DELETE /blogs/{id}
 // @Summary      Xóa blog
-// @Description  Xóa blog theo ID
+// @Description  Xóa blog theo ID. Chỉ editor/admin
 // @Tags         blogs
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
 // @Param        id   path      int  true  "Blog ID"
 // @Success      200  {object}  Response
 // @Failure      400  {object}  Response
+// @Failure      401  {object}  Response
+// @Failure      403  {object}  Response
 // @Failure      404  {object}  Response
+// @Failure      503  {object}  Response
 // @Router       /blogs/{id} [delete]
 func (h *BlogHandler) DeleteBlog(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	if !canDeleteBlog(user) {
+		writeError(w, http.StatusForbidden, "Only editors and admins can delete blogs")
+		return
+	}
+
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid blog ID", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, "Invalid blog ID")
 		return
 	}
 
-	exists := h.store.Delete(id)
-	if !exists {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Blog not found",
-		})
+	if err := h.store.Delete(r.Context(), id); err != nil {
+		writeStoreError(w, err, "Blog not found")
 		return
 	}
 
@@ -290,26 +365,134 @@ func (h *BlogHandler) DeleteBlog(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
-// generateSlug generates a URL-friendly slug from title
-func generateSlug(title string) string {
-	slug := strings.ToLower(title)
-	slug = strings.TrimSpace(slug)
-	// Replace spaces with hyphens
-	slug = strings.ReplaceAll(slug, " ", "-")
-	// Remove special characters (keep only alphanumeric and hyphens)
-	var result strings.Builder
-	for _, char := range slug {
-		if (char >= 'a' && char <= 'z') || (char >= '0' && char <= '9') || char == '-' {
-			result.WriteRune(char)
-		}
+// newBlog builds the blog of a create request by user, checking it like POST /blogs.
+// Whether the user may create blogs at all is checked by the caller.
+func (h *BlogHandler) newBlog(user *middleware.User, req *models.CreateBlogRequest) (*models.Blog, error) {
+	if req.Title == "" {
+		return nil, badRequest("Title is required")
+	}
+	if err := validateContent(req.Content); err != nil {
+		return nil, err
+	}
+	if req.Published && !canPublishBlog(user) {
+		return nil, forbidden("Only editors and admins can publish blogs")
+	}
+	if req.PublishAt != nil && !canScheduleBlog(user) {
+		return nil, forbidden("Only editors and admins can schedule blogs")
+	}
+
+	// Generate slug from title if not provided. The store appends a suffix when it is taken.
+	blogSlug := h.slugs.Normalize(req.Slug)
+	if blogSlug == "" {
+		blogSlug = h.slugs.Make(req.Title)
+	}
+
+	now := time.Now()
+	blog := &models.Blog{
+		Title:     req.Title,
+		Content:   req.Content,
+		Slug:      blogSlug,
+		Author:    req.Author,
+		AuthorID:  user.UID,
+		Published: req.Published,
+		Tags:      slug.Tags(req.Tags),
+		UpdatedBy: user.UID,
+		CreatedAt: now,
+		UpdatedAt: now,
+	}
+
+	// A schedule only applies to blogs that are not published yet
+	if !blog.Published {
+		blog.PublishAt = req.PublishAt
 	}
-	slug = result.String()
-	// Remove multiple consecutive hyphens
-	for strings.Contains(slug, "--") {
-		slug = strings.ReplaceAll(slug, "--", "-")
+	return blog, nil
+}
+
+// validateContent checks that the Markdown content of a blog stays within the limits
+// that keep rendering it cheap
+func validateContent(content string) error {
+	err := markdown.Validate(content)
+	switch {
+	case errors.Is(err, markdown.ErrTooLong):
+		return badRequest(fmt.Sprintf("Content must be at most %d KB", markdown.MaxLength>>10))
+	case errors.Is(err, markdown.ErrTooDeep):
+		return badRequest(fmt.Sprintf("Content can nest block quotes and lists at most %d levels deep", markdown.MaxDepth))
 	}
-	// Remove leading/trailing hyphens
-	slug = strings.Trim(slug, "-")
-	return slug
+	return nil
 }
 
+// blogChanges checks an update request by user like PUT /blogs/{id} and returns the
+// change applied to the blog as stored. The change only touches the fields set in
+// the request, and the checks that depend on the blog run against that stored blog,
+// so concurrent edits and scheduled publishing are not overwritten.
+func (h *BlogHandler) blogChanges(user *middleware.User, req *models.UpdateBlogRequest) (store.BlogChange, error) {
+	if req.PublishAt != nil && !canScheduleBlog(user) {
+		return nil, forbidden("Only editors and admins can schedule blogs")
+	}
+	if req.Content != nil {
+		if err := validateContent(*req.Content); err != nil {
+			return nil, err
+		}
+	}
+
+	var publishAt *time.Time
+	if req.PublishAt != nil && *req.PublishAt != "" {
+		parsed, err := time.Parse(time.RFC3339, *req.PublishAt)
+		if err != nil {
+			return nil, badRequest("publish_at must be an RFC3339 time")
+		}
+		publishAt = &parsed
+	}
+
+	// The previous slug keeps redirecting to the new one
+	var newSlug string
+	if req.Slug != nil && h.slugs.Normalize(*req.Slug) != "" {
+		newSlug = h.slugs.Normalize(*req.Slug)
+	} else if req.Title != nil {
+		// Regenerate slug if title changed but slug not provided
+		newSlug = h.slugs.Make(*req.Title)
+	}
+
+	var tags []string
+	if req.Tags != nil {
+		tags = slug.Tags(*req.Tags)
+	}
+
+	return func(blog *models.Blog) error {
+		if !canEditBlog(user, blog) {
+			return forbidden("You can only edit your own blogs")
+		}
+		if req.Published != nil && *req.Published != blog.Published && !canPublishBlog(user) {
+			return forbidden("Only editors and admins can publish or unpublish blogs")
+		}
+
+		// Update fields if provided
+		if req.Title != nil {
+			blog.Title = *req.Title
+		}
+		if req.Content != nil {
+			blog.Content = *req.Content
+		}
+		if newSlug != "" {
+			blog.Slug = newSlug
+		}
+		if req.Author != nil {
+			blog.Author = *req.Author
+		}
+		if req.Tags != nil {
+			blog.Tags = append([]string{}, tags...)
+		}
+		if req.Published != nil {
+			blog.Published = *req.Published
+		}
+		if req.PublishAt != nil {
+			blog.PublishAt = publishAt
+		}
+		// A schedule only applies to blogs that are not published yet
+		if blog.Published {
+			blog.PublishAt = nil
+		}
+		blog.UpdatedBy = user.UID
+		return nil
+	}, nil
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"apigo1/middleware"
+	"apigo1/models"
+)
+
+// Blog authoring policy:
+//   - readers cannot write blogs
+//   - authors can create posts and edit their own posts
+//   - editors and admins can edit any post, publish/unpublish, schedule and delete
+
+// canCreateBlog reports whether the user may create blogs
+func canCreateBlog(user *middleware.User) bool {
+	return user.HasRole(middleware.RoleAuthor)
+}
+
+// canEditBlog reports whether the user may edit the given blog
+func canEditBlog(user *middleware.User, blog *models.Blog) bool {
+	if user.HasRole(middleware.RoleEditor) {
+		return true
+	}
+	return user.HasRole(middleware.RoleAuthor) && blog.AuthorID == user.UID
+}
+
+// canPublishBlog reports whether the user may change the Published flag
+func canPublishBlog(user *middleware.User) bool {
+	return user.HasRole(middleware.RoleEditor)
+}
+
+// canDeleteBlog reports whether the user may delete blogs
+func canDeleteBlog(user *middleware.User) bool {
+	return user.HasRole(middleware.RoleEditor)
+}
+
+// canScheduleBlog reports whether the user may set or cancel a scheduled publishing time
+func canScheduleBlog(user *middleware.User) bool {
+	return canPublishBlog(user)
+}
+
+// Comment policy:
+//   - signed-in users (and anonymous visitors when enabled) can comment on published posts
+//   - comments of editors and admins are approved right away, others wait for moderation
+//   - commenters can delete their own comments; editors and admins moderate and delete any comment
+
+// canModerateComments reports whether the user may approve, reject and delete any comment
+func canModerateComments(user *middleware.User) bool {
+	return user.HasRole(middleware.RoleEditor)
+}
+
+// canDeleteComment reports whether the user may delete the given comment
+func canDeleteComment(user *middleware.User, comment *models.Comment) bool {
+	if canModerateComments(user) {
+		return true
+	}
+	return comment.AuthorID != "" && comment.AuthorID == user.UID
+}
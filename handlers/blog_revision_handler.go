@@ -0,0 +1,259 @@
+package handlers
+
+import (
+	"apigo1/diff"
+	"apigo1/models"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// ListBlogRevisions handles GET /blogs/{id}/revisions
+// @Summary      Lấy lịch sử revisions của blog
+// @Description  Trả về các revisions của blog, mới nhất trước. Mỗi lần tạo, cập nhật hoặc khôi phục blog tạo ra một revision mới. Cần quyền sửa blog
+// @Tags         blogs
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Blog ID"
+// @Success      200  {object}  Response{data=[]models.BlogRevision}
+// @Failure      400  {object}  Response
+// @Failure      401  {object}  Response
+// @Failure      403  {object}  Response
+// @Failure      404  {object}  Response
+// @Failure      503  {object}  Response
+// @Router       /blogs/{id}/revisions [get]
+func (h *BlogHandler) ListBlogRevisions(w http.ResponseWriter, r *http.Request) {
+	blog, ok := h.editableBlog(w, r)
+	if !ok {
+		return
+	}
+
+	revisions, err := h.store.ListRevisions(r.Context(), blog.ID)
+	if err != nil {
+		writeStoreError(w, err, "Blog not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    revisions,
+	})
+}
+
+// GetBlogRevision handles GET /blogs/{id}/revisions/{rev}
+// @Summary      Lấy một revision của blog
+// @Description  Trả về nội dung blog tại revision rev. Cần quyền sửa blog
+// @Tags         blogs
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Blog ID"
+// @Param        rev  path      int  true  "Số revision"
+// @Success      200  {object}  Response{data=models.BlogRevision}
+// @Failure      400  {object}  Response
+// @Failure      401  {object}  Response
+// @Failure      403  {object}  Response
+// @Failure      404  {object}  Response
+// @Failure      503  {object}  Response
+// @Router       /blogs/{id}/revisions/{rev} [get]
+func (h *BlogHandler) GetBlogRevision(w http.ResponseWriter, r *http.Request) {
+	blog, ok := h.editableBlog(w, r)
+	if !ok {
+		return
+	}
+
+	rev, err := strconv.Atoi(mux.Vars(r)["rev"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid revision")
+		return
+	}
+
+	revision, err := h.store.GetRevision(r.Context(), blog.ID, rev)
+	if err != nil {
+		writeStoreError(w, err, "Revision not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    revision,
+	})
+}
+
+// DiffBlogRevisions handles GET /blogs/{id}/revisions/diff
+// @Summary      So sánh hai revisions của blog
+// @Description  Trả về diff theo từng dòng giữa nội dung của revision from và revision to. Mặc định to là revision mới nhất và from là revision ngay trước to. Cần quyền sửa blog
+// @Tags         blogs
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int  true   "Blog ID"
+// @Param        from  query     int  false  "Revision gốc (mặc định to - 1)"
+// @Param        to    query     int  false  "Revision đích (mặc định revision mới nhất)"
+// @Success      200   {object}  Response{data=models.BlogRevisionDiff}
+// @Failure      400   {object}  Response
+// @Failure      401   {object}  Response
+// @Failure      403   {object}  Response
+// @Failure      404   {object}  Response
+// @Failure      503   {object}  Response
+// @Router       /blogs/{id}/revisions/diff [get]
+func (h *BlogHandler) DiffBlogRevisions(w http.ResponseWriter, r *http.Request) {
+	blog, ok := h.editableBlog(w, r)
+	if !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	to := blog.Revision
+	if v := query.Get("to"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid revision in to")
+			return
+		}
+		to = n
+	}
+	from := to - 1
+	if v := query.Get("from"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "Invalid revision in from")
+			return
+		}
+		from = n
+	}
+
+	fromRevision, err := h.store.GetRevision(r.Context(), blog.ID, from)
+	if err != nil {
+		writeStoreError(w, err, "Revision not found")
+		return
+	}
+	toRevision, err := h.store.GetRevision(r.Context(), blog.ID, to)
+	if err != nil {
+		writeStoreError(w, err, "Revision not found")
+		return
+	}
+
+	result := &models.BlogRevisionDiff{
+		BlogID: blog.ID,
+		From:   from,
+		To:     to,
+		Lines:  []models.DiffLine{},
+	}
+	for _, line := range diff.Lines(fromRevision.Content, toRevision.Content) {
+		switch line.Op {
+		case diff.Insert:
+			result.Added++
+		case diff.Delete:
+			result.Removed++
+		}
+		result.Lines = append(result.Lines, models.DiffLine{Op: string(line.Op), Text: line.Text})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    result,
+	})
+}
+
+// RestoreBlogRevision handles POST /blogs/{id}/revisions/{rev}/restore
+// @Summary      Khôi phục blog về một revision
+// @Description  Ghi đè title, content, slug, author và tags của blog bằng nội dung của revision rev, tạo ra một revision mới. Trạng thái published và lịch publish hiện tại được giữ nguyên. Cần quyền sửa blog
+// @Tags         blogs
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Blog ID"
+// @Param        rev  path      int  true  "Số revision cần khôi phục"
+// @Success      200  {object}  Response{data=models.Blog}
+// @Failure      400  {object}  Response
+// @Failure      401  {object}  Response
+// @Failure      403  {object}  Response
+// @Failure      404  {object}  Response
+// @Failure      409  {object}  Response
+// @Failure      503  {object}  Response
+// @Router       /blogs/{id}/revisions/{rev}/restore [post]
+func (h *BlogHandler) RestoreBlogRevision(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	blog, ok := h.editableBlog(w, r)
+	if !ok {
+		return
+	}
+
+	rev, err := strconv.Atoi(mux.Vars(r)["rev"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid revision")
+		return
+	}
+
+	revision, err := h.store.GetRevision(r.Context(), blog.ID, rev)
+	if err != nil {
+		writeStoreError(w, err, "Revision not found")
+		return
+	}
+
+	// The content of the revision replaces the current one, empty fields included.
+	// Publishing is left to editors, so restoring never changes the published state or schedule.
+	updated, err := h.store.Update(r.Context(), blog.ID, func(current *models.Blog) error {
+		// The blog may have changed hands since it was loaded
+		if !canEditBlog(user, current) {
+			return forbidden("You can only edit your own blogs")
+		}
+		current.Title = revision.Title
+		current.Content = revision.Content
+		current.Author = revision.Author
+		current.Tags = append([]string{}, revision.Tags...)
+		// Revisions saved before slugs existed have none, the blog keeps its slug then
+		if revision.Slug != "" {
+			current.Slug = revision.Slug
+		}
+		current.UpdatedBy = user.UID
+		return nil
+	})
+	if err != nil {
+		writeStoreError(w, err, "Blog not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": true,
+		"data":    updated,
+	})
+}
+
+// editableBlog loads the blog from the {id} route variable and checks that the
+// current user may edit it, writing the error response otherwise
+func (h *BlogHandler) editableBlog(w http.ResponseWriter, r *http.Request) (*models.Blog, bool) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return nil, false
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid blog ID")
+		return nil, false
+	}
+
+	blog, err := h.store.GetByID(r.Context(), id)
+	if err != nil {
+		writeStoreError(w, err, "Blog not found")
+		return nil, false
+	}
+
+	if !canEditBlog(user, blog) {
+		writeError(w, http.StatusForbidden, "You can only access your own blogs")
+		return nil, false
+	}
+	return blog, true
+}
@@ -0,0 +1,43 @@
+package handlers
+
+import (
+	"apigo1/models"
+)
+
+// publicBlog returns the public view of a blog
+func publicBlog(blog *models.Blog) *models.PublicBlog {
+	return &models.PublicBlog{
+		ID:        blog.ID,
+		Title:     blog.Title,
+		Content:   blog.Content,
+		Slug:      blog.Slug,
+		Author:    blog.Author,
+		Tags:      blog.Tags,
+		CreatedAt: blog.CreatedAt,
+		UpdatedAt: blog.UpdatedAt,
+	}
+}
+
+// blogSummary returns the public list view of a blog, with its excerpt taken from renders
+func blogSummary(blog *models.Blog, renders *renderCache) *models.BlogSummary {
+	return &models.BlogSummary{
+		ID:        blog.ID,
+		Title:     blog.Title,
+		Slug:      blog.Slug,
+		Author:    blog.Author,
+		Excerpt:   renders.excerpt(blog),
+		Tags:      blog.Tags,
+		CreatedAt: blog.CreatedAt,
+		UpdatedAt: blog.UpdatedAt,
+	}
+}
+
+// publicComment returns the public view of a comment
+func publicComment(comment *models.Comment) models.PublicComment {
+	return models.PublicComment{
+		ID:         comment.ID,
+		AuthorName: comment.AuthorName,
+		Content:    comment.Content,
+		CreatedAt:  comment.CreatedAt,
+	}
+}
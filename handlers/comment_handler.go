@@ -0,0 +1,413 @@
+package handlers
+
+import (
+	"apigo1/middleware"
+	"apigo1/models"
+	"apigo1/store"
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+)
+
+const (
+	// maxCommentLength is the maximum length of a comment in characters
+	maxCommentLength = 5000
+	// maxCommentAuthorNameLength is the maximum length of a commenter name in characters
+	maxCommentAuthorNameLength = 100
+
+	defaultModerationLimit = 50
+	maxModerationLimit     = 100
+)
+
+// CommentHandler handles HTTP requests for blog comments
+type CommentHandler struct {
+	comments       store.CommentStoreInterface
+	blogs          store.BlogStoreInterface
+	allowAnonymous bool
+}
+
+// NewCommentHandler creates a new CommentHandler. When allowAnonymous is true,
+// visitors without a bearer token may comment too.
+func NewCommentHandler(comments store.CommentStoreInterface, blogs store.BlogStoreInterface, allowAnonymous bool) *CommentHandler {
+	return &CommentHandler{
+		comments:       comments,
+		blogs:          blogs,
+		allowAnonymous: allowAnonymous,
+	}
+}
+
+// OnBlogEvent deletes the comments of deleted blogs. It is a store.BlogListener.
+func (h *CommentHandler) OnBlogEvent(event store.BlogEvent) {
+	if event.Type != store.BlogDeleted {
+		return
+	}
+	if err := h.comments.DeleteByBlog(context.Background(), event.ID); err != nil {
+		log.Printf("Failed to delete comments of blog %d: %v", event.ID, err)
+	}
+}
+
+// GetComments handles GET /blogs/{id}/comments
+// @Summary      Lấy bình luận của blog
+// @Description  Trả về các bình luận đã được duyệt của blog đã published, cũ nhất trước. Mỗi bình luận gốc kèm các trả lời đã được duyệt (một cấp)
+// @Tags         comments
+// @Accept       json
+// @Produce      json
+// @Param        id   path      int  true  "Blog ID"
+// @Success      200  {object}  Response{data=[]models.CommentThread}
+// @Failure      400  {object}  Response
+// @Failure      404  {object}  Response
+// @Failure      503  {object}  Response
+// @Router       /blogs/{id}/comments [get]
+func (h *CommentHandler) GetComments(w http.ResponseWriter, r *http.Request) {
+	blog, ok := h.publishedBlog(w, r)
+	if !ok {
+		return
+	}
+
+	comments, err := h.comments.ListByBlog(r.Context(), blog.ID)
+	if err != nil {
+		writeStoreError(w, err, "Blog not found")
+		return
+	}
+
+	threads := []*models.CommentThread{}
+	byID := make(map[int]*models.CommentThread)
+	for _, comment := range comments {
+		if comment.Status != models.CommentApproved || comment.ParentID != 0 {
+			continue
+		}
+		thread := &models.CommentThread{PublicComment: publicComment(comment), Replies: []models.PublicComment{}}
+		threads = append(threads, thread)
+		byID[comment.ID] = thread
+	}
+	// Replies of comments that are not approved stay hidden with their parent
+	for _, comment := range comments {
+		if comment.Status != models.CommentApproved || comment.ParentID == 0 {
+			continue
+		}
+		if thread, ok := byID[comment.ParentID]; ok {
+			thread.Replies = append(thread.Replies, publicComment(comment))
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{
+		Success: true,
+		Data:    threads,
+	})
+}
+
+// CreateComment handles POST /blogs/{id}/comments
+// @Summary      Bình luận blog
+// @Description  Tạo bình luận hoặc trả lời một bình luận gốc (parent_id) của blog đã published. Bình luận của editor/admin được duyệt ngay, các bình luận khác ở trạng thái pending chờ duyệt. Không cần token khi server bật ALLOW_ANONYMOUS_COMMENTS, khi đó author_name là bắt buộc
+// @Tags         comments
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int                          true  "Blog ID"
+// @Param        comment  body      models.CreateCommentRequest  true  "Bình luận"
+// @Success      201      {object}  Response{data=models.Comment}
+// @Failure      400      {object}  Response
+// @Failure      401      {object}  Response
+// @Failure      404      {object}  Response
+// @Failure      503      {object}  Response
+// @Router       /blogs/{id}/comments [post]
+func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	user, authenticated := middleware.UserFromContext(r.Context())
+	if !authenticated && !h.allowAnonymous {
+		writeError(w, http.StatusUnauthorized, "Authentication required to comment")
+		return
+	}
+
+	blog, ok := h.publishedBlog(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CreateCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		writeError(w, http.StatusBadRequest, "Content is required")
+		return
+	}
+	if utf8.RuneCountInString(content) > maxCommentLength {
+		writeError(w, http.StatusBadRequest, "Content must be at most 5000 characters")
+		return
+	}
+
+	comment := &models.Comment{
+		BlogID:     blog.ID,
+		ParentID:   req.ParentID,
+		AuthorName: strings.TrimSpace(req.AuthorName),
+		Content:    content,
+		Status:     models.CommentPending,
+	}
+	if authenticated {
+		comment.AuthorID = user.UID
+		if comment.AuthorName == "" {
+			comment.AuthorName, _ = user.Claims["name"].(string)
+		}
+		if canModerateComments(user) {
+			comment.Status = models.CommentApproved
+		}
+	} else if comment.AuthorName == "" {
+		writeError(w, http.StatusBadRequest, "author_name is required for anonymous comments")
+		return
+	}
+	if utf8.RuneCountInString(comment.AuthorName) > maxCommentAuthorNameLength {
+		writeError(w, http.StatusBadRequest, "author_name must be at most 100 characters")
+		return
+	}
+
+	// Threads are one level deep: replies go to an approved top-level comment
+	if req.ParentID != 0 {
+		parent, err := h.comments.GetByID(r.Context(), blog.ID, req.ParentID)
+		if err != nil && !errors.Is(err, store.ErrNotFound) {
+			writeStoreError(w, err, "Parent comment not found")
+			return
+		}
+		if err != nil || parent.Status != models.CommentApproved {
+			writeError(w, http.StatusBadRequest, "Parent comment not found")
+			return
+		}
+		if parent.ParentID != 0 {
+			writeError(w, http.StatusBadRequest, "Replies can only be made to top-level comments")
+			return
+		}
+	}
+
+	createdComment, err := h.comments.Create(r.Context(), comment)
+	if err != nil {
+		writeStoreError(w, err, "Comment not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(Response{
+		Success: true,
+		Data:    createdComment,
+	})
+}
+
+// DeleteComment handles DELETE /blogs/{id}/comments/{commentID}
+// @Summary      Xóa bình luận
+// @Description  Xóa bình luận cùng các trả lời của nó. Người viết được xóa bình luận của mình, editor/admin được xóa mọi bình luận
+// @Tags         comments
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id         path      int  true  "Blog ID"
+// @Param        commentID  path      int  true  "Comment ID"
+// @Success      200        {object}  Response
+// @Failure      400        {object}  Response
+// @Failure      401        {object}  Response
+// @Failure      403        {object}  Response
+// @Failure      404        {object}  Response
+// @Failure      503        {object}  Response
+// @Router       /blogs/{id}/comments/{commentID} [delete]
+func (h *CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	blogID, commentID, ok := commentIDs(w, r)
+	if !ok {
+		return
+	}
+
+	comment, err := h.comments.GetByID(r.Context(), blogID, commentID)
+	if err != nil {
+		writeStoreError(w, err, "Comment not found")
+		return
+	}
+	if !canDeleteComment(user, comment) {
+		writeError(w, http.StatusForbidden, "You can only delete your own comments")
+		return
+	}
+
+	if err := h.comments.Delete(r.Context(), blogID, commentID); err != nil {
+		writeStoreError(w, err, "Comment not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{
+		Success: true,
+		Message: "Comment deleted successfully",
+	})
+}
+
+// ListModerationQueue handles GET /admin/comments
+// @Summary      Hàng đợi duyệt bình luận
+// @Description  Trả về bình luận của mọi blog theo trạng thái (mặc định pending), cũ nhất trước. Chỉ editor/admin
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        status  query     string  false  "pending, approved hoặc spam (mặc định pending)"
+// @Param        limit   query     int     false  "Số bình luận tối đa (mặc định 50, tối đa 100)"
+// @Success      200     {object}  Response{data=[]models.Comment}
+// @Failure      400     {object}  Response
+// @Failure      401     {object}  Response
+// @Failure      403     {object}  Response
+// @Failure      503     {object}  Response
+// @Router       /admin/comments [get]
+func (h *CommentHandler) ListModerationQueue(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	if !canModerateComments(user) {
+		writeError(w, http.StatusForbidden, "Only editors and admins can moderate comments")
+		return
+	}
+
+	status := models.CommentPending
+	if value := r.URL.Query().Get("status"); value != "" {
+		status = models.CommentStatus(value)
+		if status != models.CommentPending && status != models.CommentApproved && status != models.CommentSpam {
+			writeError(w, http.StatusBadRequest, "status must be one of pending, approved, spam")
+			return
+		}
+	}
+
+	limit := defaultModerationLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = min(n, maxModerationLimit)
+	}
+
+	comments, err := h.comments.ListByStatus(r.Context(), status, limit)
+	if err != nil {
+		writeStoreError(w, err, "Comment not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{
+		Success: true,
+		Data:    comments,
+	})
+}
+
+// ApproveComment handles POST /admin/blogs/{id}/comments/{commentID}/approve
+// @Summary      Duyệt bình luận
+// @Description  Chuyển bình luận sang approved để hiển thị công khai. Chỉ editor/admin
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id         path      int  true  "Blog ID"
+// @Param        commentID  path      int  true  "Comment ID"
+// @Success      200        {object}  Response{data=models.Comment}
+// @Failure      400        {object}  Response
+// @Failure      401        {object}  Response
+// @Failure      403        {object}  Response
+// @Failure      404        {object}  Response
+// @Failure      503        {object}  Response
+// @Router       /admin/blogs/{id}/comments/{commentID}/approve [post]
+func (h *CommentHandler) ApproveComment(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, models.CommentApproved)
+}
+
+// RejectComment handles POST /admin/blogs/{id}/comments/{commentID}/reject
+// @Summary      Từ chối bình luận
+// @Description  Đánh dấu bình luận là spam, bình luận bị ẩn khỏi trang công khai. Chỉ editor/admin
+// @Tags         admin
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id         path      int  true  "Blog ID"
+// @Param        commentID  path      int  true  "Comment ID"
+// @Success      200        {object}  Response{data=models.Comment}
+// @Failure      400        {object}  Response
+// @Failure      401        {object}  Response
+// @Failure      403        {object}  Response
+// @Failure      404        {object}  Response
+// @Failure      503        {object}  Response
+// @Router       /admin/blogs/{id}/comments/{commentID}/reject [post]
+func (h *CommentHandler) RejectComment(w http.ResponseWriter, r *http.Request) {
+	h.moderate(w, r, models.CommentSpam)
+}
+
+// moderate sets the moderation status of the comment addressed by the request
+func (h *CommentHandler) moderate(w http.ResponseWriter, r *http.Request, status models.CommentStatus) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	if !canModerateComments(user) {
+		writeError(w, http.StatusForbidden, "Only editors and admins can moderate comments")
+		return
+	}
+	blogID, commentID, ok := commentIDs(w, r)
+	if !ok {
+		return
+	}
+
+	comment, err := h.comments.SetStatus(r.Context(), blogID, commentID, status)
+	if err != nil {
+		writeStoreError(w, err, "Comment not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{
+		Success: true,
+		Data:    comment,
+	})
+}
+
+// publishedBlog loads the blog addressed by the request, writing a 404 response
+// when it does not exist or is not published
+func (h *CommentHandler) publishedBlog(w http.ResponseWriter, r *http.Request) (*models.Blog, bool) {
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid blog ID")
+		return nil, false
+	}
+
+	blog, err := h.blogs.GetByID(r.Context(), id)
+	if err != nil {
+		writeStoreError(w, err, "Blog not found")
+		return nil, false
+	}
+	if !blog.Published {
+		writeError(w, http.StatusNotFound, "Blog not found")
+		return nil, false
+	}
+	return blog, true
+}
+
+// commentIDs parses the blog and comment IDs of the request path
+func commentIDs(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	vars := mux.Vars(r)
+	blogID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid blog ID")
+		return 0, 0, false
+	}
+	commentID, err := strconv.Atoi(vars["commentID"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid comment ID")
+		return 0, 0, false
+	}
+	return blogID, commentID, true
+}
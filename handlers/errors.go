@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"apigo1/store"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+)
+
+// requestError is an error caused by the request, answered with its own status code
+type requestError struct {
+	status  int
+	message string
+}
+
+func (e *requestError) Error() string {
+	return e.message
+}
+
+// badRequest returns a requestError answered with 400 Bad Request
+func badRequest(message string) error {
+	return &requestError{status: http.StatusBadRequest, message: message}
+}
+
+// forbidden returns a requestError answered with 403 Forbidden
+func forbidden(message string) error {
+	return &requestError{status: http.StatusForbidden, message: message}
+}
+
+// writeError writes a failed Response with the given status code
+func writeError(w http.ResponseWriter, status int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"error":   message,
+	})
+}
+
+// writeStoreError writes the response of a store or request error, with the status
+// code given by errorStatus
+func writeStoreError(w http.ResponseWriter, err error, notFoundMessage string) {
+	status, message := errorStatus(err, notFoundMessage)
+	writeError(w, status, message)
+}
+
+// writeTextStoreError writes the plain-text response of a store or request error.
+// The XML endpoints (feeds and sitemaps) answer feed readers and crawlers rather
+// than API clients, so their errors are plain text instead of a JSON Response.
+func writeTextStoreError(w http.ResponseWriter, err error, notFoundMessage string) {
+	status, message := errorStatus(err, notFoundMessage)
+	http.Error(w, message, status)
+}
+
+// errorStatus maps an error to the HTTP status code and message of its response:
+// requestError -> its status, ErrInvalidCursor/ErrInvalidQuery/ErrBatchTooLarge -> 400,
+// ErrNotFound -> 404, ErrConflict -> 409, ErrBatchAborted -> 424, ErrUnavailable -> 503,
+// anything else -> 500
+func errorStatus(err error, notFoundMessage string) (int, string) {
+	var reqErr *requestError
+	switch {
+	case errors.As(err, &reqErr):
+		return reqErr.status, reqErr.message
+	case errors.Is(err, store.ErrInvalidCursor):
+		return http.StatusBadRequest, "Invalid cursor"
+	case errors.Is(err, store.ErrInvalidQuery), errors.Is(err, store.ErrBatchTooLarge):
+		return http.StatusBadRequest, err.Error()
+	case errors.Is(err, store.ErrNotFound):
+		return http.StatusNotFound, notFoundMessage
+	case errors.Is(err, store.ErrConflict):
+		return http.StatusConflict, "The resource was modified concurrently, please retry"
+	case errors.Is(err, store.ErrBatchAborted):
+		return http.StatusFailedDependency, "Not applied because another operation of the atomic batch failed"
+	case errors.Is(err, store.ErrUnavailable):
+		return http.StatusServiceUnavailable, "Storage is temporarily unavailable"
+	}
+	log.Printf("Store error: %v", err)
+	return http.StatusInternalServerError, "Internal server error"
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"apigo1/feed"
+	"apigo1/models"
+	"apigo1/slug"
+	"apigo1/store"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+)
+
+// feedSize is the number of posts included in each feed
+const feedSize = 20
+
+// FeedHandler serves RSS and Atom feeds of published blogs. Like the sitemap, its
+// errors are plain text (see writeTextStoreError).
+type FeedHandler struct {
+	store   store.BlogStoreInterface
+	siteURL string
+	renders *renderCache
+}
+
+// NewFeedHandler creates a new FeedHandler. siteURL is the public URL of the blog
+// frontend; post links are built as {siteURL}/blog/{slug}.
+func NewFeedHandler(s store.BlogStoreInterface, siteURL string) *FeedHandler {
+	return &FeedHandler{
+		store:   s,
+		siteURL: strings.TrimRight(siteURL, "/"),
+		renders: newRenderCache(),
+	}
+}
+
+// RSS handles GET /feed.xml and GET /tags/{tag}/feed.xml
+func (h *FeedHandler) RSS(w http.ResponseWriter, r *http.Request) {
+	h.serveFeed(w, r, "application/rss+xml; charset=utf-8", feed.RSS)
+}
+
+// Atom handles GET /atom.xml and GET /tags/{tag}/atom.xml
+func (h *FeedHandler) Atom(w http.ResponseWriter, r *http.Request) {
+	h.serveFeed(w, r, "application/atom+xml; charset=utf-8", feed.Atom)
+}
+
+// serveFeed loads the newest published blogs (optionally for one tag) and writes them
+// with the given encoder
+func (h *FeedHandler) serveFeed(w http.ResponseWriter, r *http.Request, contentType string, encode func(feed.Channel) ([]byte, error)) {
+	// Stored tags are normalized, so /tags/Go/feed.xml is the feed of the tag "go"
+	tag, tagged := mux.Vars(r)["tag"]
+	tag = slug.Tag(tag)
+
+	opts := store.ListOptions{
+		Limit:   feedSize,
+		Filters: []store.Filter{{Field: "published", Op: "==", Value: true}},
+		Sort:    []store.SortField{{Field: "created_at", Desc: true}},
+	}
+	if tagged {
+		if tag == "" {
+			http.NotFound(w, r)
+			return
+		}
+		opts.Filters = append(opts.Filters, store.Filter{Field: "tags", Op: "array-contains", Value: tag})
+	}
+
+	blogs, _, err := h.store.List(r.Context(), opts)
+	if err != nil {
+		writeTextStoreError(w, err, "Blog not found")
+		return
+	}
+
+	body, err := encode(h.channel(r, tag, blogs))
+	if err != nil {
+		log.Printf("Failed to encode feed: %v", err)
+		http.Error(w, "Failed to generate feed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Write(body)
+}
+
+// channel builds the feed channel for a list of blogs
+func (h *FeedHandler) channel(r *http.Request, tag string, blogs []*models.Blog) feed.Channel {
+	title := h.siteTitle()
+	description := "Bài viết mới nhất từ " + title
+	if tag != "" {
+		title += " - #" + tag
+		description = "Bài viết mới nhất với tag " + tag
+	}
+
+	ch := feed.Channel{
+		Title:       title,
+		Link:        h.siteURL,
+		FeedURL:     h.siteURL + r.URL.EscapedPath(),
+		Description: description,
+	}
+	for _, blog := range blogs {
+		if blog.UpdatedAt.After(ch.Updated) {
+			ch.Updated = blog.UpdatedAt
+		}
+		ch.Items = append(ch.Items, feed.Item{
+			Title:      blog.Title,
+			Link:       h.postURL(blog),
+			Summary:    h.renders.excerpt(blog),
+			Author:     blog.Author,
+			Categories: blog.Tags,
+			Published:  blog.CreatedAt,
+			Updated:    blog.UpdatedAt,
+		})
+	}
+	return ch
+}
+
+// postURL returns the public URL of a blog post
+func (h *FeedHandler) postURL(blog *models.Blog) string {
+	return h.siteURL + "/blog/" + url.PathEscape(blog.Slug)
+}
+
+// siteTitle returns the host name of the site URL, used as the feed title
+func (h *FeedHandler) siteTitle() string {
+	if u, err := url.Parse(h.siteURL); err == nil && u.Host != "" {
+		return u.Host
+	}
+	return h.siteURL
+}
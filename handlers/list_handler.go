@@ -0,0 +1,341 @@
+package handlers
+
+import (
+	"apigo1/models"
+	"apigo1/store"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/gorilla/mux"
+)
+
+// maxListNameLength is the maximum length of a todo list name in characters
+const maxListNameLength = 100
+
+// ListHandler handles HTTP requests for todo lists
+type ListHandler struct {
+	store store.TodoStoreInterface
+}
+
+// NewListHandler creates a new ListHandler
+func NewListHandler(s store.TodoStoreInterface) *ListHandler {
+	return &ListHandler{store: s}
+}
+
+// GetAllLists handles GET /lists
+// @Summary      Lấy danh sách todo lists
+// @Description  Trả về các todo lists của user hiện tại theo thứ tự tạo
+// @Tags         lists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Success      200  {object}  Response{data=[]models.TodoList}
+// @Failure      401  {object}  Response
+// @Failure      503  {object}  Response
+// @Router       /lists [get]
+func (h *ListHandler) GetAllLists(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	lists, err := h.store.GetLists(r.Context(), user.UID)
+	if err != nil {
+		writeStoreError(w, err, "List not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{
+		Success: true,
+		Data:    lists,
+	})
+}
+
+// GetListByID handles GET /lists/{id}
+// @Summary      Lấy todo list theo ID
+// @Description  Trả về thông tin todo list theo ID (chỉ lists của user hiện tại)
+// @Tags         lists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "List ID"
+// @Success      200  {object}  Response{data=models.TodoList}
+// @Failure      400  {object}  Response
+// @Failure      401  {object}  Response
+// @Failure      404  {object}  Response
+// @Failure      503  {object}  Response
+// @Router       /lists/{id} [get]
+func (h *ListHandler) GetListByID(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid list ID")
+		return
+	}
+
+	list, err := h.store.GetList(r.Context(), user.UID, id)
+	if err != nil {
+		writeStoreError(w, err, "List not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{
+		Success: true,
+		Data:    list,
+	})
+}
+
+// GetListTodos handles GET /lists/{id}/todos
+// @Summary      Lấy todos của một list
+// @Description  Trả về todos thuộc list theo trang, với cùng các tham số lọc và sắp xếp như GET /todos
+// @Tags         lists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id         path      int     true   "List ID"
+// @Param        limit      query     int     false  "Số todos mỗi trang (mặc định 20, tối đa 100)"
+// @Param        cursor     query     string  false  "Cursor trả về từ trang trước (next_cursor)"
+// @Param        completed  query     bool    false  "Lọc theo trạng thái hoàn thành"
+// @Param        sort       query     string  false  "Sắp xếp như GET /todos (vd: -priority,due_at)"
+// @Success      200        {object}  Response{data=[]models.Todo}
+// @Failure      400        {object}  Response
+// @Failure      401        {object}  Response
+// @Failure      404        {object}  Response
+// @Failure      503        {object}  Response
+// @Router       /lists/{id}/todos [get]
+func (h *ListHandler) GetListTodos(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid list ID")
+		return
+	}
+
+	opts, err := parseListQuery(r, todoQueryFields)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	opts.Filters = append(opts.Filters, store.Filter{Field: "list_id", Op: "==", Value: id})
+
+	if _, err := h.store.GetList(r.Context(), user.UID, id); err != nil {
+		writeStoreError(w, err, "List not found")
+		return
+	}
+
+	todos, nextCursor, err := h.store.List(r.Context(), user.UID, opts)
+	if err != nil {
+		writeStoreError(w, err, "List not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{
+		Success:    true,
+		Data:       todos,
+		NextCursor: nextCursor,
+		HasMore:    nextCursor != "",
+	})
+}
+
+// CreateList handles POST /lists
+// @Summary      Tạo todo list
+// @Description  Tạo một todo list mới, ví dụ một project hay một sprint
+// @Tags         lists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        list  body      models.CreateTodoListRequest  true  "List information"
+// @Success      201   {object}  Response{data=models.TodoList}
+// @Failure      400   {object}  Response
+// @Failure      401   {object}  Response
+// @Failure      409   {object}  Response
+// @Failure      503   {object}  Response
+// @Router       /lists [post]
+func (h *ListHandler) CreateList(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.CreateTodoListRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	name, ok := validListName(w, req.Name)
+	if !ok {
+		return
+	}
+
+	list, err := h.store.CreateList(r.Context(), &models.TodoList{Name: name, OwnerID: user.UID})
+	if err != nil {
+		writeStoreError(w, err, "List not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(Response{
+		Success: true,
+		Data:    list,
+	})
+}
+
+// UpdateList handles PUT /lists/{id}
+// @Summary      Đổi tên todo list
+// @Description  Đổi tên todo list theo ID
+// @Tags         lists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int                           true  "List ID"
+// @Param        list  body      models.UpdateTodoListRequest  true  "Updated list information"
+// @Success      200   {object}  Response{data=models.TodoList}
+// @Failure      400   {object}  Response
+// @Failure      401   {object}  Response
+// @Failure      404   {object}  Response
+// @Failure      409   {object}  Response
+// @Failure      503   {object}  Response
+// @Router       /lists/{id} [put]
+func (h *ListHandler) UpdateList(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid list ID")
+		return
+	}
+
+	var req models.UpdateTodoListRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	name, ok := validListName(w, req.Name)
+	if !ok {
+		return
+	}
+
+	list, err := h.store.UpdateList(r.Context(), user.UID, id, &models.TodoList{Name: name})
+	if err != nil {
+		writeStoreError(w, err, "List not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{
+		Success: true,
+		Data:    list,
+	})
+}
+
+// DeleteList handles DELETE /lists/{id}
+// @Summary      Xóa todo list
+// @Description  Xóa todo list theo ID. Với cascade=true, todos của list bị xóa theo; nếu không, todos được chuyển sang list move_to, hoặc ra khỏi mọi list khi không có move_to
+// @Tags         lists
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id       path      int   true   "List ID"
+// @Param        cascade  query     bool  false  "Xóa cả todos của list"
+// @Param        move_to  query     int   false  "ID của list nhận todos (khi không cascade)"
+// @Success      200      {object}  Response{data=models.DeleteTodoListResult}
+// @Failure      400      {object}  Response
+// @Failure      401      {object}  Response
+// @Failure      404      {object}  Response
+// @Failure      409      {object}  Response
+// @Failure      503      {object}  Response
+// @Router       /lists/{id} [delete]
+func (h *ListHandler) DeleteList(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid list ID")
+		return
+	}
+
+	query := r.URL.Query()
+	cascade := false
+	if value := query.Get("cascade"); value != "" {
+		if cascade, err = strconv.ParseBool(value); err != nil {
+			writeError(w, http.StatusBadRequest, "cascade must be true or false")
+			return
+		}
+	}
+	moveTo := 0
+	if value := query.Get("move_to"); value != "" {
+		if cascade {
+			writeError(w, http.StatusBadRequest, "move_to cannot be combined with cascade=true")
+			return
+		}
+		if moveTo, err = strconv.Atoi(value); err != nil || moveTo == id {
+			writeError(w, http.StatusBadRequest, "move_to must be the ID of another list")
+			return
+		}
+		if _, err := h.store.GetList(r.Context(), user.UID, moveTo); err != nil {
+			if errors.Is(err, store.ErrNotFound) {
+				writeError(w, http.StatusBadRequest, "move_to must be the ID of another list")
+			} else {
+				writeStoreError(w, err, "List not found")
+			}
+			return
+		}
+	}
+
+	affected, err := h.store.DeleteList(r.Context(), user.UID, id, cascade, moveTo)
+	if err != nil {
+		writeStoreError(w, err, "List not found")
+		return
+	}
+
+	result := models.DeleteTodoListResult{MovedTo: moveTo}
+	if cascade {
+		result.Deleted = len(affected)
+	} else {
+		result.Moved = len(affected)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{
+		Success: true,
+		Data:    result,
+		Message: "List deleted successfully",
+	})
+}
+
+// validListName trims a list name and checks its length, writing a 400 response
+// when it is invalid
+func validListName(w http.ResponseWriter, name string) (string, bool) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		writeError(w, http.StatusBadRequest, "Name is required")
+		return "", false
+	}
+	if utf8.RuneCountInString(name) > maxListNameLength {
+		writeError(w, http.StatusBadRequest, "Name must be at most 100 characters")
+		return "", false
+	}
+	return name, true
+}
@@ -0,0 +1,36 @@
+package handlers
+
+import (
+	"apigo1/store"
+	"errors"
+	"net/http"
+	"strconv"
+)
+
+const (
+	// defaultPageSize is used when the limit query parameter is missing
+	defaultPageSize = 20
+	// maxPageSize caps the limit query parameter
+	maxPageSize = 100
+)
+
+// parseListOptions reads the limit and cursor query parameters
+func parseListOptions(r *http.Request) (store.ListOptions, error) {
+	opts := store.ListOptions{
+		Limit:  defaultPageSize,
+		Cursor: r.URL.Query().Get("cursor"),
+	}
+
+	if limit := r.URL.Query().Get("limit"); limit != "" {
+		n, err := strconv.Atoi(limit)
+		if err != nil || n < 1 {
+			return opts, errors.New("limit must be a positive integer")
+		}
+		if n > maxPageSize {
+			n = maxPageSize
+		}
+		opts.Limit = n
+	}
+
+	return opts, nil
+}
@@ -0,0 +1,213 @@
+package handlers
+
+import (
+	"apigo1/slug"
+	"apigo1/store"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// fieldType is the type of a query parameter value
+type fieldType int
+
+const (
+	boolField fieldType = iota
+	intField
+	stringField
+	timeField
+)
+
+// queryField describes a field that clients may filter or sort a list on
+type queryField struct {
+	Type fieldType
+	// Filter allows ?name=value filters on this field
+	Filter bool
+	// Sort allows ?sort=name and ?sort=-name on this field
+	Sort bool
+	// Op is the store operator used for filters, "==" when empty
+	Op string
+	// StoreField is the store field name when it differs from the query name
+	StoreField string
+	// Filters builds the store filters for a filter value, replacing the single
+	// filter on StoreField with Op
+	Filters func(value interface{}) ([]store.Filter, error)
+}
+
+// reservedParams are list query parameters that are not field filters
+var reservedParams = map[string]bool{
+	"limit":  true,
+	"cursor": true,
+	"sort":   true,
+}
+
+// todoQueryFields lists the fields GET /todos can filter and sort on
+var todoQueryFields = map[string]queryField{
+	"id":           {Type: intField, Sort: true},
+	"title":        {Type: stringField, Filter: true, Sort: true},
+	"completed":    {Type: boolField, Filter: true, Sort: true},
+	"priority":     {Type: stringField, Sort: true},
+	"due_at":       {Type: timeField, Sort: true},
+	"due_before":   {Type: timeField, Filter: true, Op: "<", StoreField: "due_at"},
+	"overdue":      {Type: boolField, Filter: true, Filters: overdueFilters},
+	"completed_at": {Type: timeField, Sort: true},
+	"list_id":      {Type: intField, Filter: true},
+	"position":     {Type: stringField, Sort: true},
+	"created_at":   {Type: timeField, Sort: true},
+	"updated_at":   {Type: timeField, Sort: true},
+}
+
+// overdueFilters selects the todos that are not completed and past their deadline
+func overdueFilters(value interface{}) ([]store.Filter, error) {
+	if overdue, _ := value.(bool); !overdue {
+		return nil, fmt.Errorf("overdue only supports true")
+	}
+	return []store.Filter{
+		{Field: "completed", Op: "==", Value: false},
+		{Field: "due_at", Op: "<", Value: time.Now()},
+	}, nil
+}
+
+// publishedOnlyFilters accepts published=true on lists that only contain
+// published blogs. It adds no filter since the handler already filters on published.
+func publishedOnlyFilters(value interface{}) ([]store.Filter, error) {
+	if published, _ := value.(bool); !published {
+		return nil, fmt.Errorf("published only supports true")
+	}
+	return nil, nil
+}
+
+// tagFilters selects the blogs having a tag, normalized like stored tags
+func tagFilters(value interface{}) ([]store.Filter, error) {
+	raw, _ := value.(string)
+	tag := slug.Tag(raw)
+	if tag == "" {
+		return nil, fmt.Errorf("invalid value %q for filter \"tag\"", raw)
+	}
+	return []store.Filter{{Field: "tags", Op: "array-contains", Value: tag}}, nil
+}
+
+// blogQueryFields lists the fields the public GET /blogs can filter and sort on.
+// Public lists only contain published blogs, so published only accepts true.
+var blogQueryFields = map[string]queryField{
+	"id":         {Type: intField, Sort: true},
+	"title":      {Type: stringField, Sort: true},
+	"author":     {Type: stringField, Filter: true, Sort: true},
+	"published":  {Type: boolField, Filter: true, Filters: publishedOnlyFilters},
+	"tag":        {Type: stringField, Filter: true, Filters: tagFilters},
+	"created_at": {Type: timeField, Sort: true},
+	"updated_at": {Type: timeField, Sort: true},
+}
+
+// tagBlogQueryFields lists the fields GET /tags/{tag}/blogs can filter and sort on.
+// The tag comes from the path, and stores allow only one tag filter per query.
+var tagBlogQueryFields = map[string]queryField{
+	"id":         {Type: intField, Sort: true},
+	"title":      {Type: stringField, Sort: true},
+	"author":     {Type: stringField, Filter: true, Sort: true},
+	"published":  {Type: boolField, Filter: true, Filters: publishedOnlyFilters},
+	"created_at": {Type: timeField, Sort: true},
+	"updated_at": {Type: timeField, Sort: true},
+}
+
+// adminBlogQueryFields lists the fields GET /admin/blogs can filter and sort on
+var adminBlogQueryFields = map[string]queryField{
+	"id":         {Type: intField, Sort: true},
+	"title":      {Type: stringField, Sort: true},
+	"author":     {Type: stringField, Filter: true, Sort: true},
+	"author_id":  {Type: stringField, Filter: true},
+	"published":  {Type: boolField, Filter: true},
+	"tag":        {Type: stringField, Filter: true, Filters: tagFilters},
+	"created_at": {Type: timeField, Sort: true},
+	"updated_at": {Type: timeField, Sort: true},
+}
+
+// parseListQuery reads paging, filter and sort query parameters, rejecting
+// any field that is not listed in fields
+func parseListQuery(r *http.Request, fields map[string]queryField) (store.ListOptions, error) {
+	opts, err := parseListOptions(r)
+	if err != nil {
+		return opts, err
+	}
+
+	query := r.URL.Query()
+	for name, values := range query {
+		if reservedParams[name] {
+			continue
+		}
+
+		field, ok := fields[name]
+		if !ok || !field.Filter {
+			return opts, fmt.Errorf("unknown filter field %q", name)
+		}
+		if len(values) != 1 {
+			return opts, fmt.Errorf("filter %q may only be given once", name)
+		}
+
+		value, err := parseFieldValue(field.Type, values[0])
+		if err != nil {
+			return opts, fmt.Errorf("invalid value %q for filter %q", values[0], name)
+		}
+
+		if field.Filters != nil {
+			filters, err := field.Filters(value)
+			if err != nil {
+				return opts, err
+			}
+			opts.Filters = append(opts.Filters, filters...)
+			continue
+		}
+
+		op := field.Op
+		if op == "" {
+			op = "=="
+		}
+		opts.Filters = append(opts.Filters, store.Filter{
+			Field: field.storeName(name),
+			Op:    op,
+			Value: value,
+		})
+	}
+
+	if sortParam := query.Get("sort"); sortParam != "" {
+		for _, name := range strings.Split(sortParam, ",") {
+			name = strings.TrimSpace(name)
+			desc := strings.HasPrefix(name, "-")
+			name = strings.TrimPrefix(name, "-")
+
+			field, ok := fields[name]
+			if !ok || !field.Sort {
+				return opts, fmt.Errorf("unknown sort field %q", name)
+			}
+			opts.Sort = append(opts.Sort, store.SortField{
+				Field: field.storeName(name),
+				Desc:  desc,
+			})
+		}
+	}
+
+	return opts, nil
+}
+
+// storeName returns the store field name for a query field
+func (f queryField) storeName(name string) string {
+	if f.StoreField != "" {
+		return f.StoreField
+	}
+	return name
+}
+
+// parseFieldValue converts a query parameter value to the field's type
+func parseFieldValue(t fieldType, raw string) (interface{}, error) {
+	switch t {
+	case boolField:
+		return strconv.ParseBool(raw)
+	case intField:
+		return strconv.Atoi(raw)
+	case timeField:
+		return time.Parse(time.RFC3339, raw)
+	}
+	return raw, nil
+}
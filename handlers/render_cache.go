@@ -0,0 +1,96 @@
+package handlers
+
+import (
+	"apigo1/markdown"
+	"apigo1/models"
+	"sync"
+	"time"
+)
+
+// maxRenderCacheEntries bounds the memory used by the render cache
+const maxRenderCacheEntries = 1000
+
+// renderCache caches rendered blog HTML and excerpts by blog ID. An entry is reused
+// only while the blog's UpdatedAt is unchanged, so edits are picked up without
+// explicit invalidation.
+type renderCache struct {
+	entries map[int]renderCacheEntry
+	mu      sync.Mutex
+}
+
+type renderCacheEntry struct {
+	updatedAt time.Time
+	rendered  *models.RenderedBlog // nil until the full blog is rendered
+	excerpt   *string              // nil until the excerpt is computed
+}
+
+// newRenderCache creates an empty renderCache
+func newRenderCache() *renderCache {
+	return &renderCache{
+		entries: make(map[int]renderCacheEntry),
+	}
+}
+
+// render returns the rendered form of a blog, from the cache when it is up to date
+func (c *renderCache) render(blog *models.Blog) *models.RenderedBlog {
+	if entry, ok := c.lookup(blog); ok && entry.rendered != nil {
+		return entry.rendered
+	}
+
+	doc := markdown.Render(blog.Content)
+	toc := make([]models.TOCEntry, 0, len(doc.TOC))
+	for _, h := range doc.TOC {
+		toc = append(toc, models.TOCEntry{Level: h.Level, Text: h.Text, ID: h.ID})
+	}
+	rendered := &models.RenderedBlog{
+		ID:        blog.ID,
+		Title:     blog.Title,
+		Slug:      blog.Slug,
+		HTML:      doc.HTML,
+		TOC:       toc,
+		TOCHTML:   doc.TOCHTML(),
+		UpdatedAt: blog.UpdatedAt,
+	}
+
+	c.store(blog, func(entry *renderCacheEntry) { entry.rendered = rendered })
+	return rendered
+}
+
+// excerpt returns the plain text of the first paragraph of a blog, from the cache
+// when it is up to date
+func (c *renderCache) excerpt(blog *models.Blog) string {
+	if entry, ok := c.lookup(blog); ok && entry.excerpt != nil {
+		return *entry.excerpt
+	}
+
+	excerpt := markdown.FirstParagraph(blog.Content)
+	c.store(blog, func(entry *renderCacheEntry) { entry.excerpt = &excerpt })
+	return excerpt
+}
+
+// lookup returns the cache entry of a blog if it is up to date
+func (c *renderCache) lookup(blog *models.Blog) (renderCacheEntry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[blog.ID]
+	if !ok || !entry.updatedAt.Equal(blog.UpdatedAt) {
+		return renderCacheEntry{}, false
+	}
+	return entry, true
+}
+
+// store updates the cache entry of a blog with set, starting from an empty entry
+// when the cached one is out of date
+func (c *renderCache) store(blog *models.Blog, set func(entry *renderCacheEntry)) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[blog.ID]
+	if !ok || !entry.updatedAt.Equal(blog.UpdatedAt) {
+		if !ok && len(c.entries) >= maxRenderCacheEntries {
+			c.entries = make(map[int]renderCacheEntry)
+		}
+		entry = renderCacheEntry{updatedAt: blog.UpdatedAt}
+	}
+	set(&entry)
+	c.entries[blog.ID] = entry
+}
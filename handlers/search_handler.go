@@ -0,0 +1,121 @@
+package handlers
+
+import (
+	"apigo1/middleware"
+	"apigo1/models"
+	"apigo1/search"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+const (
+	defaultSearchLimit = 20
+	maxSearchLimit     = 50
+)
+
+// SearchHandler handles full-text search over blogs and todos
+type SearchHandler struct {
+	index *search.Index
+}
+
+// NewSearchHandler creates a new SearchHandler
+func NewSearchHandler(index *search.Index) *SearchHandler {
+	return &SearchHandler{
+		index: index,
+	}
+}
+
+// Search handles GET /search
+// @Summary      Tìm kiếm blogs và todos
+// @Description  Tìm kiếm toàn văn trong title, tags và nội dung của blogs đã published, và trong title, description của todos của chính user. Không phân biệt dấu tiếng Việt ("huong dan" khớp "Hướng dẫn"), từ cuối có thể gõ dở (tìm theo tiền tố). Kết quả sắp xếp theo độ liên quan; title_html và snippet là HTML đã escape, phần khớp được bọc trong <mark>
+// @Tags         search
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        q      query     string  true   "Từ khóa tìm kiếm"
+// @Param        type   query     string  false  "Loại kết quả, cách nhau bởi dấu phẩy: blog, todo (mặc định blog,todo khi đã xác thực, ngược lại blog)"
+// @Param        limit  query     int     false  "Số kết quả tối đa (mặc định 20, tối đa 50)"
+// @Success      200    {object}  Response{data=[]models.SearchResult}
+// @Failure      400    {object}  Response
+// @Failure      401    {object}  Response
+// @Router       /search [get]
+func (h *SearchHandler) Search(w http.ResponseWriter, r *http.Request) {
+	text := strings.TrimSpace(r.URL.Query().Get("q"))
+	if text == "" {
+		writeError(w, http.StatusBadRequest, "Query parameter q is required")
+		return
+	}
+
+	limit := defaultSearchLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = min(n, maxSearchLimit)
+	}
+
+	user, authenticated := middleware.UserFromContext(r.Context())
+	types, err := parseSearchTypes(r.URL.Query().Get("type"), authenticated)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	query := search.Query{Text: text, Types: types, Limit: limit}
+	for _, t := range types {
+		if t == search.Todo && !authenticated {
+			writeError(w, http.StatusUnauthorized, "Authentication required to search todos")
+			return
+		}
+	}
+	if authenticated {
+		query.Owner = user.UID
+	}
+
+	matches := h.index.Search(query)
+	results := make([]models.SearchResult, 0, len(matches))
+	for _, m := range matches {
+		results = append(results, models.SearchResult{
+			Type:      string(m.Document.Type),
+			ID:        m.Document.ID,
+			Title:     m.Document.Title,
+			Slug:      m.Document.Slug,
+			Score:     m.Score,
+			TitleHTML: m.TitleHTML,
+			Snippet:   m.Snippet,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{
+		Success: true,
+		Data:    results,
+	})
+}
+
+// parseSearchTypes parses the comma-separated type parameter. Anonymous callers
+// only search blogs by default.
+func parseSearchTypes(value string, authenticated bool) ([]search.DocType, error) {
+	if value == "" {
+		if authenticated {
+			return []search.DocType{search.Blog, search.Todo}, nil
+		}
+		return []search.DocType{search.Blog}, nil
+	}
+
+	var types []search.DocType
+	for _, part := range strings.Split(value, ",") {
+		switch t := search.DocType(strings.TrimSpace(part)); t {
+		case search.Blog, search.Todo:
+			types = append(types, t)
+		default:
+			return nil, fmt.Errorf("unsupported type %q, expected blog or todo", part)
+		}
+	}
+	return types, nil
+}
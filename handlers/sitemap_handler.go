@@ -0,0 +1,207 @@
+package handlers
+
+import (
+	"apigo1/models"
+	"apigo1/sitemap"
+	"apigo1/store"
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// sitemapBatchSize is the page size used when loading blogs for the sitemap
+const sitemapBatchSize = 500
+
+// sitemapCacheTTL bounds how long a cached sitemap is served. Invalidate only sees
+// the blog changes made through this instance, so changes made by other instances
+// (and blogs they publish on schedule) show up once the cache expires.
+const sitemapCacheTTL = 5 * time.Minute
+
+// SitemapHandler serves the XML sitemap of published blogs and their tag pages.
+// The URL list is built once and cached until Invalidate is called or
+// sitemapCacheTTL has passed. Errors are plain text (see writeTextStoreError).
+type SitemapHandler struct {
+	store   store.BlogStoreInterface
+	siteURL string
+
+	mu      sync.Mutex
+	urls    []sitemap.URL // nil when the cache is empty
+	builtAt time.Time
+}
+
+// NewSitemapHandler creates a new SitemapHandler. siteURL is the public URL of the
+// blog frontend; posts are listed as {siteURL}/blog/{slug} and tag pages as {siteURL}/tags/{tag}.
+func NewSitemapHandler(s store.BlogStoreInterface, siteURL string) *SitemapHandler {
+	return &SitemapHandler{
+		store:   s,
+		siteURL: strings.TrimRight(siteURL, "/"),
+	}
+}
+
+// Invalidate drops the cached sitemap. It is a store.BlogListener, so it can be
+// subscribed to an ObservedBlogStore to rebuild the sitemap after blog changes.
+func (h *SitemapHandler) Invalidate(store.BlogEvent) {
+	h.mu.Lock()
+	h.urls = nil
+	h.mu.Unlock()
+}
+
+// Sitemap handles GET /sitemap.xml. Up to sitemap.MaxURLs URLs it is a plain sitemap,
+// beyond that it is a sitemap index pointing to /sitemap-{n}.xml.
+func (h *SitemapHandler) Sitemap(w http.ResponseWriter, r *http.Request) {
+	urls, err := h.load(r.Context())
+	if err != nil {
+		writeTextStoreError(w, err, "Blog not found")
+		return
+	}
+
+	if len(urls) <= sitemap.MaxURLs {
+		h.write(w, sitemap.URLSet, urls)
+		return
+	}
+
+	parts := (len(urls) + sitemap.MaxURLs - 1) / sitemap.MaxURLs
+	index := make([]sitemap.URL, 0, parts)
+	for n := 1; n <= parts; n++ {
+		index = append(index, sitemap.URL{
+			Loc:     fmt.Sprintf("%s/sitemap-%d.xml", h.siteURL, n),
+			LastMod: latestLastMod(sitemapPart(urls, n)),
+		})
+	}
+	h.write(w, sitemap.Index, index)
+}
+
+// SitemapPart handles GET /sitemap-{n}.xml, the n-th sitemap of the index (1-based)
+func (h *SitemapHandler) SitemapPart(w http.ResponseWriter, r *http.Request) {
+	n, err := strconv.Atoi(mux.Vars(r)["n"])
+	if err != nil || n < 1 {
+		http.NotFound(w, r)
+		return
+	}
+
+	urls, err := h.load(r.Context())
+	if err != nil {
+		writeTextStoreError(w, err, "Blog not found")
+		return
+	}
+
+	part := sitemapPart(urls, n)
+	if part == nil {
+		http.NotFound(w, r)
+		return
+	}
+	h.write(w, sitemap.URLSet, part)
+}
+
+func (h *SitemapHandler) write(w http.ResponseWriter, encode func([]sitemap.URL) ([]byte, error), urls []sitemap.URL) {
+	body, err := encode(urls)
+	if err != nil {
+		log.Printf("Failed to encode sitemap: %v", err)
+		http.Error(w, "Failed to generate sitemap", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/xml; charset=utf-8")
+	w.Header().Set("Cache-Control", "public, max-age=300")
+	w.Write(body)
+}
+
+// load returns the cached URL list, building it from the store when it is missing or expired
+func (h *SitemapHandler) load(ctx context.Context) ([]sitemap.URL, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.urls != nil && time.Since(h.builtAt) < sitemapCacheTTL {
+		return h.urls, nil
+	}
+
+	builtAt := time.Now()
+	urls, err := h.build(ctx)
+	if err != nil {
+		return nil, err
+	}
+	h.urls = urls
+	h.builtAt = builtAt
+	return urls, nil
+}
+
+// build lists the home page, every published blog and the tag page of every tag
+// used by a published blog
+func (h *SitemapHandler) build(ctx context.Context) ([]sitemap.URL, error) {
+	var blogURLs []sitemap.URL
+	tags := make(map[string]sitemap.URL)
+
+	opts := store.ListOptions{
+		Limit:   sitemapBatchSize,
+		Filters: []store.Filter{{Field: "published", Op: "==", Value: true}},
+		Sort:    []store.SortField{{Field: "id"}},
+	}
+	for {
+		blogs, nextCursor, err := h.store.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, blog := range blogs {
+			blogURLs = append(blogURLs, sitemap.URL{Loc: h.blogURL(blog), LastMod: blog.UpdatedAt})
+			for _, tag := range blog.Tags {
+				// A tag page changes whenever one of its blogs does
+				if entry, ok := tags[tag]; !ok || blog.UpdatedAt.After(entry.LastMod) {
+					tags[tag] = sitemap.URL{Loc: h.siteURL + "/tags/" + url.PathEscape(tag), LastMod: blog.UpdatedAt}
+				}
+			}
+		}
+		if nextCursor == "" {
+			break
+		}
+		opts.Cursor = nextCursor
+	}
+
+	urls := make([]sitemap.URL, 0, 1+len(blogURLs)+len(tags))
+	urls = append(urls, sitemap.URL{Loc: h.siteURL + "/", LastMod: latestLastMod(blogURLs)})
+	urls = append(urls, blogURLs...)
+
+	tagNames := make([]string, 0, len(tags))
+	for tag := range tags {
+		tagNames = append(tagNames, tag)
+	}
+	sort.Strings(tagNames)
+	for _, tag := range tagNames {
+		urls = append(urls, tags[tag])
+	}
+
+	return urls, nil
+}
+
+// blogURL returns the public URL of a blog post
+func (h *SitemapHandler) blogURL(blog *models.Blog) string {
+	return h.siteURL + "/blog/" + url.PathEscape(blog.Slug)
+}
+
+// sitemapPart returns the n-th (1-based) chunk of at most sitemap.MaxURLs URLs,
+// or nil when there is no such chunk
+func sitemapPart(urls []sitemap.URL, n int) []sitemap.URL {
+	start := (n - 1) * sitemap.MaxURLs
+	if start >= len(urls) {
+		return nil
+	}
+	return urls[start:min(start+sitemap.MaxURLs, len(urls))]
+}
+
+// latestLastMod returns the most recent LastMod of urls
+func latestLastMod(urls []sitemap.URL) (latest time.Time) {
+	for _, u := range urls {
+		if u.LastMod.After(latest) {
+			latest = u.LastMod
+		}
+	}
+	return latest
+}
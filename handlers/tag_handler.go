@@ -0,0 +1,254 @@
+package handlers
+
+import (
+	"apigo1/middleware"
+	"apigo1/models"
+	"apigo1/slug"
+	"apigo1/store"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/mux"
+)
+
+// tagBatchSize is the page size used when loading blogs to count tags
+const tagBatchSize = 500
+
+// maxMergeTags bounds the number of tags merged by one request
+const maxMergeTags = 100
+
+// tagCacheTTL bounds how long cached tag counts are served. Invalidate only sees the
+// blog changes made through this instance, so other instances' changes show up once
+// the cache expires.
+const tagCacheTTL = time.Minute
+
+// TagHandler handles HTTP requests for blog tags. Tag counts are computed once
+// and cached until Invalidate is called or tagCacheTTL has passed.
+type TagHandler struct {
+	store   store.BlogStoreInterface
+	renders *renderCache
+
+	mu        sync.Mutex
+	counts    []models.TagCount // nil when the cache is empty
+	countedAt time.Time
+}
+
+// NewTagHandler creates a new TagHandler
+func NewTagHandler(s store.BlogStoreInterface) *TagHandler {
+	return &TagHandler{
+		store:   s,
+		renders: newRenderCache(),
+	}
+}
+
+// Invalidate drops the cached tag counts. It is a store.BlogListener, so it can be
+// subscribed to an ObservedBlogStore to recount tags after blog changes.
+func (h *TagHandler) Invalidate(store.BlogEvent) {
+	h.mu.Lock()
+	h.counts = nil
+	h.mu.Unlock()
+}
+
+// GetAllTags handles GET /tags
+// @Summary      Lấy danh sách tags
+// @Description  Trả về tất cả tags của các blogs đã published kèm số blogs dùng mỗi tag, nhiều blogs nhất trước
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Success      200  {object}  Response{data=[]models.TagCount}
+// @Failure      503  {object}  Response
+// @Router       /tags [get]
+func (h *TagHandler) GetAllTags(w http.ResponseWriter, r *http.Request) {
+	counts, err := h.load(r.Context())
+	if err != nil {
+		writeStoreError(w, err, "Tag not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{
+		Success: true,
+		Data:    counts,
+	})
+}
+
+// GetTagBlogs handles GET /tags/{tag}/blogs
+// @Summary      Lấy danh sách blogs theo tag
+// @Description  Trả về các blogs đã published có tag này theo trang (mặc định mới nhất trước). Mỗi blog chỉ gồm tóm tắt (excerpt)
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Param        tag     path      string  true   "Tag (được chuẩn hóa, vd: Go → go)"
+// @Param        limit   query     int     false  "Số blogs mỗi trang (mặc định 20, tối đa 100)"
+// @Param        cursor  query     string  false  "Cursor trả về từ trang trước (next_cursor)"
+// @Param        sort    query     string  false  "Sắp xếp theo các field id, title, author, created_at, updated_at, cách nhau bởi dấu phẩy; thêm '-' để sắp xếp giảm dần (vd: -created_at)"
+// @Success      200     {object}  Response{data=[]models.BlogSummary}
+// @Failure      400     {object}  Response
+// @Failure      404     {object}  Response
+// @Failure      503     {object}  Response
+// @Router       /tags/{tag}/blogs [get]
+func (h *TagHandler) GetTagBlogs(w http.ResponseWriter, r *http.Request) {
+	// Stored tags are normalized, so /tags/Go/blogs lists the blogs tagged "go"
+	tag := slug.Tag(mux.Vars(r)["tag"])
+	if tag == "" {
+		writeError(w, http.StatusNotFound, "Tag not found")
+		return
+	}
+
+	opts, err := parseListQuery(r, tagBlogQueryFields)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+	opts.Filters = append(opts.Filters,
+		store.Filter{Field: "published", Op: "==", Value: true},
+		store.Filter{Field: "tags", Op: "array-contains", Value: tag},
+	)
+
+	blogs, nextCursor, err := h.store.List(r.Context(), opts)
+	if err != nil {
+		writeStoreError(w, err, "Blog not found")
+		return
+	}
+
+	summaries := make([]*models.BlogSummary, 0, len(blogs))
+	for _, blog := range blogs {
+		summaries = append(summaries, blogSummary(blog, h.renders))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success":     true,
+		"data":        summaries,
+		"next_cursor": nextCursor,
+		"has_more":    nextCursor != "",
+	})
+}
+
+// MergeTags handles POST /tags/merge
+// @Summary      Gộp tags
+// @Description  Thay các tags trong from bằng tag to (đã chuẩn hóa) trên mọi blog, kể cả bản nháp, và lưu revision cho mỗi blog thay đổi. Dùng để gộp các tags trùng nghĩa như "Go", "golang" vào "go". Chỉ admin
+// @Tags         tags
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        merge  body      models.MergeTagsRequest  true  "Tags cần gộp"
+// @Success      200    {object}  Response{data=models.MergeTagsResult}
+// @Failure      400    {object}  Response
+// @Failure      401    {object}  Response
+// @Failure      403    {object}  Response
+// @Failure      409    {object}  Response
+// @Failure      503    {object}  Response
+// @Router       /tags/merge [post]
+func (h *TagHandler) MergeTags(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+	if !user.HasRole(middleware.RoleAdmin) {
+		writeError(w, http.StatusForbidden, "Only admins can merge tags")
+		return
+	}
+
+	var req models.MergeTagsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	to := slug.Tag(req.To)
+	if to == "" {
+		writeError(w, http.StatusBadRequest, "to must be a non-empty tag")
+		return
+	}
+	// from lists tags as stored, so tags saved before normalization can be merged too
+	var from []string
+	for _, tag := range req.From {
+		if tag = strings.TrimSpace(tag); tag != "" {
+			from = append(from, tag)
+		}
+	}
+	if len(from) == 0 {
+		writeError(w, http.StatusBadRequest, "from must list at least one tag")
+		return
+	}
+	if len(from) > maxMergeTags {
+		writeError(w, http.StatusBadRequest, fmt.Sprintf("from lists more than %d tags", maxMergeTags))
+		return
+	}
+
+	merged, err := h.store.MergeTags(r.Context(), from, to, user.UID)
+	if err != nil {
+		writeStoreError(w, err, "Tag not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{
+		Success: true,
+		Data:    models.MergeTagsResult{Tag: to, Updated: len(merged)},
+	})
+}
+
+// load returns the cached tag counts, counting them from the store when they are missing or expired
+func (h *TagHandler) load(ctx context.Context) ([]models.TagCount, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.counts != nil && time.Since(h.countedAt) < tagCacheTTL {
+		return h.counts, nil
+	}
+
+	countedAt := time.Now()
+	counts, err := h.count(ctx)
+	if err != nil {
+		return nil, err
+	}
+	h.counts = counts
+	h.countedAt = countedAt
+	return counts, nil
+}
+
+// count counts the tags of every published blog, most used first
+func (h *TagHandler) count(ctx context.Context) ([]models.TagCount, error) {
+	byTag := make(map[string]int)
+
+	opts := store.ListOptions{
+		Limit:   tagBatchSize,
+		Filters: []store.Filter{{Field: "published", Op: "==", Value: true}},
+		Sort:    []store.SortField{{Field: "id"}},
+	}
+	for {
+		blogs, nextCursor, err := h.store.List(ctx, opts)
+		if err != nil {
+			return nil, err
+		}
+		for _, blog := range blogs {
+			for _, tag := range blog.Tags {
+				byTag[tag]++
+			}
+		}
+		if nextCursor == "" {
+			break
+		}
+		opts.Cursor = nextCursor
+	}
+
+	counts := make([]models.TagCount, 0, len(byTag))
+	for tag, count := range byTag {
+		counts = append(counts, models.TagCount{Tag: tag, Count: count})
+	}
+	sort.Slice(counts, func(i, j int) bool {
+		if counts[i].Count != counts[j].Count {
+			return counts[i].Count > counts[j].Count
+		}
+		return counts[i].Tag < counts[j].Tag
+	})
+	return counts, nil
+}
@@ -2,8 +2,11 @@ package handlers
 
 import (
 	"apigo1/models"
+	"apigo1/rrule"
 	"apigo1/store"
+	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"strconv"
 	"time"
@@ -11,12 +14,19 @@ import (
 	"github.com/gorilla/mux"
 )
 
+const (
+	defaultOccurrenceLimit = 5
+	maxOccurrenceLimit     = 100
+)
+
 // Response represents a standard API response
 type Response struct {
-	Success bool        `json:"success"`
-	Data    interface{} `json:"data,omitempty"`
-	Error   string      `json:"error,omitempty"`
-	Message string      `json:"message,omitempty"`
+	Success    bool        `json:"success"`
+	Data       interface{} `json:"data,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	Message    string      `json:"message,omitempty"`
+	NextCursor string      `json:"next_cursor,omitempty"` // Cursor of the next page (list endpoints)
+	HasMore    bool        `json:"has_more,omitempty"`    // Whether another page exists (list endpoints)
 }
 
 // TodoHandler handles todo-related HTTP requests
@@ -30,50 +40,82 @@ func NewTodoHandler(s store.TodoStoreInterface) *TodoHandler {
 }
 
 // GetAllTodos handles GET /todos
-// @Summary      Lấy tất cả todos
-// @Description  Trả về danh sách tất cả todos
+// @Summary      Lấy danh sách todos
+// @Description  Trả về danh sách todos của user hiện tại theo trang, có thể lọc và sắp xếp. Dùng next_cursor của trang trước làm cursor để lấy trang tiếp theo
 // @Tags         todos
 // @Accept       json
 // @Produce      json
-// @Success      200  {object}  Response{data=[]models.Todo}
+// @Security     BearerAuth
+// @Param        limit      query     int     false  "Số todos mỗi trang (mặc định 20, tối đa 100)"
+// @Param        cursor     query     string  false  "Cursor trả về từ trang trước (next_cursor)"
+// @Param        completed  query     bool    false  "Lọc theo trạng thái hoàn thành"
+// @Param        title      query     string  false  "Lọc theo tiêu đề (khớp chính xác)"
+// @Param        overdue    query     bool    false  "Chỉ lấy todos quá hạn: chưa hoàn thành và due_at trước thời điểm hiện tại (chỉ hỗ trợ true)"
+// @Param        due_before query     string  false  "Chỉ lấy todos có due_at trước thời điểm này (RFC3339)"
+// @Param        list_id    query     int     false  "Lọc theo list (0: todos không thuộc list nào)"
+// @Param        sort       query     string  false  "Sắp xếp theo các field id, title, completed, priority, due_at, completed_at, position, created_at, updated_at, cách nhau bởi dấu phẩy; thêm '-' để sắp xếp giảm dần (vd: -priority,due_at). Mặc định theo position (thứ tự kéo thả). Với overdue hoặc due_before, kết quả được sắp xếp theo due_at trước"
+// @Success      200        {object}  Response{data=[]models.Todo}
+// @Failure      400        {object}  Response
+// @Failure      401        {object}  Response
+// @Failure      503        {object}  Response
 // @Router       /todos [get]
 func (h *TodoHandler) GetAllTodos(w http.ResponseWriter, r *http.Request) {
-	todos := h.store.GetAll()
-	
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	opts, err := parseListQuery(r, todoQueryFields)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, err.Error())
+		return
+	}
+
+	todos, nextCursor, err := h.store.List(r.Context(), user.UID, opts)
+	if err != nil {
+		writeStoreError(w, err, "Todo not found")
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
-		"success": true,
-		"data":    todos,
+		"success":     true,
+		"data":        todos,
+		"next_cursor": nextCursor,
+		"has_more":    nextCursor != "",
 	})
 }
 
 // GetTodoByID handles GET /todos/{id}
 // @Summary      Lấy todo theo ID
-// @Description  Trả về thông tin todo theo ID
+// @Description  Trả về thông tin todo theo ID (chỉ todos của user hiện tại)
 // @Tags         todos
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
 // @Param        id   path      int  true  "Todo ID"
 // @Success      200  {object}  Response{data=models.Todo}
 // @Failure      400  {object}  Response
+// @Failure      401  {object}  Response
 // @Failure      404  {object}  Response
+// @Failure      503  {object}  Response
 // @Router       /todos/{id} [get]
 func (h *TodoHandler) GetTodoByID(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid todo ID", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, "Invalid todo ID")
 		return
 	}
 
-	todo, exists := h.store.GetByID(id)
-	if !exists {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Todo not found",
-		})
+	todo, err := h.store.GetByID(r.Context(), user.UID, id)
+	if err != nil {
+		writeStoreError(w, err, "Todo not found")
 		return
 	}
 
@@ -86,42 +128,42 @@ func (h *TodoHandler) GetTodoByID(w http.ResponseWriter, r *http.Request) {
 
 // CreateTodo handles POST /todos
 // @Summary      Tạo todo mới
-// @Description  Tạo một todo mới
+// @Description  Tạo một todo mới. priority mặc định là medium; gửi completed_at để tạo todo đã hoàn thành (vd: khi import). Với auto_complete, todo tự hoàn thành khi mọi mục checklist đã xong. recurrence (RRULE: FREQ=DAILY/WEEKLY/MONTHLY, INTERVAL, BYDAY, COUNT, UNTIL) tạo todo lặp lại, cần due_at
 // @Tags         todos
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
 // @Param        todo  body      models.CreateTodoRequest  true  "Todo information"
 // @Success      201   {object}  Response{data=models.Todo}
 // @Failure      400   {object}  Response
+// @Failure      401   {object}  Response
+// @Failure      409   {object}  Response
+// @Failure      503   {object}  Response
 // @Router       /todos [post]
 func (h *TodoHandler) CreateTodo(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
 	var req models.CreateTodoRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
-	if req.Title == "" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Title is required",
-		})
+	todo, err := h.newTodo(r.Context(), user.UID, &req)
+	if err != nil {
+		writeStoreError(w, err, "Todo not found")
 		return
 	}
 
-	now := time.Now()
-	todo := &models.Todo{
-		Title:       req.Title,
-		Description: req.Description,
-		Completed:   false,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+	createdTodo, err := h.store.Create(r.Context(), todo)
+	if err != nil {
+		writeStoreError(w, err, "Todo not found")
+		return
 	}
 
-	createdTodo := h.store.Create(todo)
-
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]interface{}{
@@ -132,62 +174,51 @@ func (h *TodoHandler) CreateTodo(w http.ResponseWriter, r *http.Request) {
 
 // UpdateTodo handles PUT /todos/{id}
 // @Summary      Cập nhật todo
-// @Description  Cập nhật thông tin todo theo ID
+// @Description  Cập nhật thông tin todo theo ID. Gửi "due_at": "" để bỏ hạn chót. completed_at được đặt tự động khi todo chuyển sang hoàn thành và bị xóa khi todo chưa hoàn thành. Bật auto_complete sẽ hoàn thành ngay todo có checklist đã xong hết. Khi một todo lặp lại được đánh dấu hoàn thành, lần lặp tiếp theo được tạo tự động (next_todo_id). Gửi "recurrence": "" để bỏ lặp lại. Gửi list_id để chuyển todo sang list khác (0: bỏ khỏi list)
 // @Tags         todos
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
 // @Param        id    path      int                      true  "Todo ID"
 // @Param        todo  body      models.UpdateTodoRequest  true  "Updated todo information"
 // @Success      200   {object}  Response{data=models.Todo}
 // @Failure      400   {object}  Response
+// @Failure      401   {object}  Response
 // @Failure      404   {object}  Response
+// @Failure      409   {object}  Response
+// @Failure      503   {object}  Response
 // @Router       /todos/{id} [put]
 func (h *TodoHandler) UpdateTodo(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid todo ID", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, "Invalid todo ID")
 		return
 	}
 
 	var req models.UpdateTodoRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, "Invalid request body")
 		return
 	}
 
-	existingTodo, exists := h.store.GetByID(id)
-	if !exists {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Todo not found",
-		})
+	change, err := h.todoChanges(r.Context(), user.UID, &req)
+	if err != nil {
+		writeStoreError(w, err, "Todo not found")
 		return
 	}
 
-	updatedTodo := &models.Todo{
-		ID:          existingTodo.ID,
-		Title:       req.Title,
-		Description: req.Description,
-		Completed:   existingTodo.Completed,
-		UpdatedAt:   time.Now(),
-	}
-
-	if req.Completed != nil {
-		updatedTodo.Completed = *req.Completed
-	}
-
-	if req.Title == "" {
-		updatedTodo.Title = existingTodo.Title
-	}
-	if req.Description == "" {
-		updatedTodo.Description = existingTodo.Description
+	todo, _, err := h.store.Update(r.Context(), user.UID, id, change)
+	if err != nil {
+		writeStoreError(w, err, "Todo not found")
+		return
 	}
 
-	todo, _ := h.store.Update(id, updatedTodo)
-
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]interface{}{
 		"success": true,
@@ -201,27 +232,29 @@ func (h *TodoHandler) UpdateTodo(w http.ResponseWriter, r *http.Request) {
 // @Tags         todos
 // @Accept       json
 // @Produce      json
+// @Security     BearerAuth
 // @Param        id   path      int  true  "Todo ID"
 // @Success      200  {object}  Response
 // @Failure      400  {object}  Response
+// @Failure      401  {object}  Response
 // @Failure      404  {object}  Response
+// @Failure      503  {object}  Response
 // @Router       /todos/{id} [delete]
 func (h *TodoHandler) DeleteTodo(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
 	vars := mux.Vars(r)
 	id, err := strconv.Atoi(vars["id"])
 	if err != nil {
-		http.Error(w, "Invalid todo ID", http.StatusBadRequest)
+		writeError(w, http.StatusBadRequest, "Invalid todo ID")
 		return
 	}
 
-	exists := h.store.Delete(id)
-	if !exists {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusNotFound)
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"success": false,
-			"error":   "Todo not found",
-		})
+	if err := h.store.Delete(r.Context(), user.UID, id); err != nil {
+		writeStoreError(w, err, "Todo not found")
 		return
 	}
 
@@ -232,3 +265,287 @@ func (h *TodoHandler) DeleteTodo(w http.ResponseWriter, r *http.Request) {
 	})
 }
 
+// MoveTodo handles POST /todos/{id}/move
+// @Summary      Di chuyển todo
+// @Description  Đặt todo ngay sau todo after và/hoặc ngay trước todo before (kéo thả). Không có mốc nào thì todo được chuyển xuống cuối. Chỉ todo được di chuyển bị ghi lại
+// @Tags         todos
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int                     true  "Todo ID"
+// @Param        move  body      models.MoveTodoRequest  true  "Anchors"
+// @Success      200   {object}  Response{data=models.Todo}
+// @Failure      400   {object}  Response
+// @Failure      401   {object}  Response
+// @Failure      404   {object}  Response
+// @Failure      409   {object}  Response
+// @Failure      503   {object}  Response
+// @Router       /todos/{id}/move [post]
+func (h *TodoHandler) MoveTodo(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid todo ID")
+		return
+	}
+
+	var req models.MoveTodoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.Before == id || req.After == id {
+		writeError(w, http.StatusBadRequest, "A todo cannot be moved next to itself")
+		return
+	}
+
+	// Anchors are checked here so that a bad request gets a 400; the store reports
+	// anchors that change meanwhile as a conflict
+	before, ok := h.moveAnchor(w, r, user.UID, req.Before, "before")
+	if !ok {
+		return
+	}
+	after, ok := h.moveAnchor(w, r, user.UID, req.After, "after")
+	if !ok {
+		return
+	}
+	if before != nil && after != nil && after.Position >= before.Position {
+		writeError(w, http.StatusBadRequest, "after must come before before")
+		return
+	}
+
+	todo, err := h.store.Move(r.Context(), user.UID, id, req.Before, req.After)
+	if err != nil {
+		writeStoreError(w, err, "Todo not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{
+		Success: true,
+		Data:    todo,
+	})
+}
+
+// GetOccurrences handles GET /todos/{id}/occurrences
+// @Summary      Xem trước các lần lặp của todo
+// @Description  Trả về hạn chót của các lần lặp tiếp theo của một todo lặp lại, tính từ due_at của todo và có tính COUNT, UNTIL. Todo không lặp lại trả về danh sách rỗng
+// @Tags         todos
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id     path      int  true   "Todo ID"
+// @Param        limit  query     int  false  "Số lần lặp (mặc định 5, tối đa 100)"
+// @Success      200    {object}  Response{data=[]models.TodoOccurrence}
+// @Failure      400    {object}  Response
+// @Failure      401    {object}  Response
+// @Failure      404    {object}  Response
+// @Failure      503    {object}  Response
+// @Router       /todos/{id}/occurrences [get]
+func (h *TodoHandler) GetOccurrences(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	id, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid todo ID")
+		return
+	}
+
+	limit := defaultOccurrenceLimit
+	if value := r.URL.Query().Get("limit"); value != "" {
+		n, err := strconv.Atoi(value)
+		if err != nil || n < 1 {
+			writeError(w, http.StatusBadRequest, "Invalid limit")
+			return
+		}
+		limit = min(n, maxOccurrenceLimit)
+	}
+
+	todo, err := h.store.GetByID(r.Context(), user.UID, id)
+	if err != nil {
+		writeStoreError(w, err, "Todo not found")
+		return
+	}
+
+	occurrences := []models.TodoOccurrence{}
+	for i, dueAt := range todo.NextOccurrences(limit) {
+		occurrences = append(occurrences, models.TodoOccurrence{
+			Occurrence: todo.Occurrence + i + 1,
+			DueAt:      dueAt,
+		})
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{
+		Success: true,
+		Data:    occurrences,
+	})
+}
+
+// newTodo builds the todo of a create request of ownerID, checking it like POST /todos
+func (h *TodoHandler) newTodo(ctx context.Context, ownerID string, req *models.CreateTodoRequest) (*models.Todo, error) {
+	if req.Title == "" {
+		return nil, badRequest("Title is required")
+	}
+
+	priority, ok := models.ParseTodoPriority(req.Priority)
+	if !ok {
+		return nil, badRequest("priority must be one of low, medium, high, urgent")
+	}
+
+	if err := h.checkList(ctx, ownerID, req.ListID); err != nil {
+		return nil, err
+	}
+
+	var recurrence string
+	if req.Recurrence != "" {
+		if req.DueAt == nil {
+			return nil, badRequest("recurrence requires due_at")
+		}
+		rule, err := rrule.Parse(req.Recurrence)
+		if err != nil {
+			return nil, badRequest(err.Error())
+		}
+		recurrence = rule.String()
+	}
+
+	now := time.Now()
+	return &models.Todo{
+		Title:        req.Title,
+		Description:  req.Description,
+		Completed:    req.CompletedAt != nil,
+		Priority:     priority,
+		DueAt:        req.DueAt,
+		CompletedAt:  req.CompletedAt,
+		AutoComplete: req.AutoComplete,
+		Recurrence:   recurrence,
+		ListID:       req.ListID,
+		OwnerID:      ownerID,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}, nil
+}
+
+// todoChanges checks an update request of ownerID like PUT /todos/{id} and returns
+// the change applied to the todo as stored. The change only touches the fields set
+// in the request, and the checks that depend on the todo run against that stored
+// todo, so concurrent updates are not overwritten.
+func (h *TodoHandler) todoChanges(ctx context.Context, ownerID string, req *models.UpdateTodoRequest) (store.TodoChange, error) {
+	var priority models.TodoPriority
+	if req.Priority != nil {
+		var ok bool
+		priority, ok = models.ParseTodoPriority(*req.Priority)
+		if !ok || *req.Priority == "" {
+			return nil, badRequest("priority must be one of low, medium, high, urgent")
+		}
+	}
+
+	var dueAt *time.Time
+	if req.DueAt != nil && *req.DueAt != "" {
+		parsed, err := time.Parse(time.RFC3339, *req.DueAt)
+		if err != nil {
+			return nil, badRequest("due_at must be an RFC3339 time")
+		}
+		dueAt = &parsed
+	}
+
+	var recurrence string
+	if req.Recurrence != nil && *req.Recurrence != "" {
+		rule, err := rrule.Parse(*req.Recurrence)
+		if err != nil {
+			return nil, badRequest(err.Error())
+		}
+		recurrence = rule.String()
+	}
+
+	if req.ListID != nil {
+		if err := h.checkList(ctx, ownerID, *req.ListID); err != nil {
+			return nil, err
+		}
+	}
+
+	return func(todo *models.Todo) error {
+		if req.Title != "" {
+			todo.Title = req.Title
+		}
+		if req.Description != "" {
+			todo.Description = req.Description
+		}
+		if req.Completed != nil {
+			todo.Completed = *req.Completed
+		}
+		if req.CompletedAt != nil {
+			if !todo.Completed {
+				return badRequest("completed_at can only be set on a completed todo")
+			}
+			todo.CompletedAt = req.CompletedAt
+		}
+		if req.AutoComplete != nil {
+			todo.AutoComplete = *req.AutoComplete
+		}
+		if req.Priority != nil {
+			todo.Priority = priority
+		}
+		if req.DueAt != nil {
+			todo.DueAt = dueAt
+		}
+		if req.ListID != nil {
+			todo.ListID = *req.ListID
+		}
+		if req.Recurrence != nil {
+			switch {
+			case recurrence == "":
+				todo.Recurrence = ""
+				todo.Occurrence = 0
+			case recurrence != todo.Recurrence:
+				// A new rule starts a new series from this todo
+				todo.Recurrence = recurrence
+				todo.Occurrence = 1
+			}
+		}
+		if todo.Recurrence != "" && todo.DueAt == nil {
+			return badRequest("recurrence requires due_at")
+		}
+		return nil
+	}, nil
+}
+
+// checkList checks that listID is 0 or one of the lists of ownerID
+func (h *TodoHandler) checkList(ctx context.Context, ownerID string, listID int) error {
+	if listID == 0 {
+		return nil
+	}
+	if _, err := h.store.GetList(ctx, ownerID, listID); err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			return badRequest("list_id does not match any of your lists")
+		}
+		return err
+	}
+	return nil
+}
+
+// moveAnchor returns the anchor todo of a move, nil when id is 0, writing an
+// error response when it is not one of the todos of ownerID
+func (h *TodoHandler) moveAnchor(w http.ResponseWriter, r *http.Request, ownerID string, id int, name string) (*models.Todo, bool) {
+	if id == 0 {
+		return nil, true
+	}
+	todo, err := h.store.GetByID(r.Context(), ownerID, id)
+	if err != nil {
+		if errors.Is(err, store.ErrNotFound) {
+			writeError(w, http.StatusBadRequest, name+" must be the ID of one of your todos")
+		} else {
+			writeStoreError(w, err, "Todo not found")
+		}
+		return nil, false
+	}
+	return todo, true
+}
+
@@ -0,0 +1,300 @@
+package handlers
+
+import (
+	"apigo1/models"
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+)
+
+// ListItems handles GET /todos/{id}/items
+// @Summary      Lấy checklist của todo
+// @Description  Trả về các mục checklist của todo theo thứ tự position
+// @Tags         todos
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id   path      int  true  "Todo ID"
+// @Success      200  {object}  Response{data=[]models.ChecklistItem}
+// @Failure      400  {object}  Response
+// @Failure      401  {object}  Response
+// @Failure      404  {object}  Response
+// @Failure      503  {object}  Response
+// @Router       /todos/{id}/items [get]
+func (h *TodoHandler) ListItems(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	todoID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid todo ID")
+		return
+	}
+
+	items, err := h.store.ListItems(r.Context(), user.UID, todoID)
+	if err != nil {
+		writeStoreError(w, err, "Todo not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{
+		Success: true,
+		Data:    items,
+	})
+}
+
+// CreateItem handles POST /todos/{id}/items
+// @Summary      Thêm mục checklist
+// @Description  Thêm một mục vào cuối checklist của todo. Trả về mục mới và todo với tiến độ đã cập nhật
+// @Tags         todos
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id    path      int                                true  "Todo ID"
+// @Param        item  body      models.CreateChecklistItemRequest  true  "Checklist item"
+// @Success      201   {object}  Response{data=models.ChecklistItemChange}
+// @Failure      400   {object}  Response
+// @Failure      401   {object}  Response
+// @Failure      404   {object}  Response
+// @Failure      409   {object}  Response
+// @Failure      503   {object}  Response
+// @Router       /todos/{id}/items [post]
+func (h *TodoHandler) CreateItem(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	todoID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid todo ID")
+		return
+	}
+
+	var req models.CreateChecklistItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+	if req.Title == "" {
+		writeError(w, http.StatusBadRequest, "Title is required")
+		return
+	}
+
+	item, todo, err := h.store.CreateItem(r.Context(), user.UID, todoID, &models.ChecklistItem{Title: req.Title})
+	if err != nil {
+		writeStoreError(w, err, "Todo not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(Response{
+		Success: true,
+		Data:    models.ChecklistItemChange{Item: item, Todo: todo},
+	})
+}
+
+// UpdateItem handles PUT /todos/{id}/items/{itemID}
+// @Summary      Cập nhật mục checklist
+// @Description  Đổi tiêu đề hoặc đánh dấu xong một mục checklist. Khi todo bật auto_complete và mọi mục đã xong, todo được hoàn thành
+// @Tags         todos
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path      int                                true  "Todo ID"
+// @Param        itemID  path      int                                true  "Checklist item ID"
+// @Param        item    body      models.UpdateChecklistItemRequest  true  "Updated checklist item"
+// @Success      200     {object}  Response{data=models.ChecklistItemChange}
+// @Failure      400     {object}  Response
+// @Failure      401     {object}  Response
+// @Failure      404     {object}  Response
+// @Failure      409     {object}  Response
+// @Failure      503     {object}  Response
+// @Router       /todos/{id}/items/{itemID} [put]
+func (h *TodoHandler) UpdateItem(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	todoID, itemID, ok := itemIDs(w, r)
+	if !ok {
+		return
+	}
+
+	var req models.UpdateChecklistItemRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	items, err := h.store.ListItems(r.Context(), user.UID, todoID)
+	if err != nil {
+		writeStoreError(w, err, "Todo not found")
+		return
+	}
+	var existing *models.ChecklistItem
+	for _, item := range items {
+		if item.ID == itemID {
+			existing = item
+			break
+		}
+	}
+	if existing == nil {
+		writeError(w, http.StatusNotFound, "Checklist item not found")
+		return
+	}
+
+	updatedItem := &models.ChecklistItem{
+		Title: req.Title,
+		Done:  existing.Done,
+	}
+	if req.Done != nil {
+		updatedItem.Done = *req.Done
+	}
+
+	item, todo, err := h.store.UpdateItem(r.Context(), user.UID, todoID, itemID, updatedItem)
+	if err != nil {
+		writeStoreError(w, err, "Checklist item not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{
+		Success: true,
+		Data:    models.ChecklistItemChange{Item: item, Todo: todo},
+	})
+}
+
+// DeleteItem handles DELETE /todos/{id}/items/{itemID}
+// @Summary      Xóa mục checklist
+// @Description  Xóa một mục checklist. Trả về todo với tiến độ đã cập nhật
+// @Tags         todos
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id      path      int  true  "Todo ID"
+// @Param        itemID  path      int  true  "Checklist item ID"
+// @Success      200     {object}  Response{data=models.ChecklistItemChange}
+// @Failure      400     {object}  Response
+// @Failure      401     {object}  Response
+// @Failure      404     {object}  Response
+// @Failure      409     {object}  Response
+// @Failure      503     {object}  Response
+// @Router       /todos/{id}/items/{itemID} [delete]
+func (h *TodoHandler) DeleteItem(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	todoID, itemID, ok := itemIDs(w, r)
+	if !ok {
+		return
+	}
+
+	todo, err := h.store.DeleteItem(r.Context(), user.UID, todoID, itemID)
+	if err != nil {
+		writeStoreError(w, err, "Checklist item not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{
+		Success: true,
+		Data:    models.ChecklistItemChange{Todo: todo},
+		Message: "Checklist item deleted successfully",
+	})
+}
+
+// ReorderItems handles POST /todos/{id}/items/reorder
+// @Summary      Sắp xếp lại checklist
+// @Description  Đặt thứ tự mới cho checklist. item_ids phải chứa mọi mục của todo đúng một lần
+// @Tags         todos
+// @Accept       json
+// @Produce      json
+// @Security     BearerAuth
+// @Param        id     path      int                                  true  "Todo ID"
+// @Param        order  body      models.ReorderChecklistItemsRequest  true  "New order"
+// @Success      200    {object}  Response{data=[]models.ChecklistItem}
+// @Failure      400    {object}  Response
+// @Failure      401    {object}  Response
+// @Failure      404    {object}  Response
+// @Failure      409    {object}  Response
+// @Failure      503    {object}  Response
+// @Router       /todos/{id}/items/reorder [post]
+func (h *TodoHandler) ReorderItems(w http.ResponseWriter, r *http.Request) {
+	user, ok := currentUser(w, r)
+	if !ok {
+		return
+	}
+
+	todoID, err := strconv.Atoi(mux.Vars(r)["id"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid todo ID")
+		return
+	}
+
+	var req models.ReorderChecklistItemsRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid request body")
+		return
+	}
+
+	// Check the order against the current checklist so that a bad request gets a 400;
+	// the store still rejects it with a conflict if the checklist changes meanwhile
+	items, err := h.store.ListItems(r.Context(), user.UID, todoID)
+	if err != nil {
+		writeStoreError(w, err, "Todo not found")
+		return
+	}
+	remaining := make(map[int]bool, len(items))
+	for _, item := range items {
+		remaining[item.ID] = true
+	}
+	for _, id := range req.ItemIDs {
+		if !remaining[id] {
+			writeError(w, http.StatusBadRequest, "item_ids must list every checklist item of the todo exactly once")
+			return
+		}
+		delete(remaining, id)
+	}
+	if len(remaining) > 0 {
+		writeError(w, http.StatusBadRequest, "item_ids must list every checklist item of the todo exactly once")
+		return
+	}
+
+	reordered, err := h.store.ReorderItems(r.Context(), user.UID, todoID, req.ItemIDs)
+	if err != nil {
+		writeStoreError(w, err, "Todo not found")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(Response{
+		Success: true,
+		Data:    reordered,
+	})
+}
+
+// itemIDs reads the todo and checklist item IDs from the URL
+func itemIDs(w http.ResponseWriter, r *http.Request) (int, int, bool) {
+	vars := mux.Vars(r)
+	todoID, err := strconv.Atoi(vars["id"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid todo ID")
+		return 0, 0, false
+	}
+	itemID, err := strconv.Atoi(vars["itemID"])
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "Invalid checklist item ID")
+		return 0, 0, false
+	}
+	return todoID, itemID, true
+}
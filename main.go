@@ -4,6 +4,10 @@ import (
 	"apigo1/docs"
 	"apigo1/firebase"
 	"apigo1/handlers"
+	"apigo1/middleware"
+	"apigo1/scheduler"
+	"apigo1/search"
+	"apigo1/slug"
 	"apigo1/store"
 	"context"
 	"log"
@@ -11,7 +15,10 @@ import (
 	"net/url"
 	"os"
 	"os/signal"
+	"strconv"
+	"strings"
 	"syscall"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/joho/godotenv"
@@ -33,6 +40,11 @@ import (
 // @BasePath  /api
 //
 // @schemes   http https
+//
+// @securityDefinitions.apikey  BearerAuth
+// @in                          header
+// @name                        Authorization
+// @description                 Firebase ID token, dạng "Bearer <token>"
 func main() {
 	// Load .env file
 	if err := godotenv.Load(); err != nil {
@@ -41,19 +53,135 @@ func main() {
 
 	ctx := context.Background()
 
-	// Initialize Firebase
-	if err := firebase.InitializeFirebase(ctx); err != nil {
-		log.Fatalf("Failed to initialize Firebase: %v", err)
+	var todoStore store.TodoStoreInterface
+	var blogStore store.BlogStoreInterface
+	var commentStore store.CommentStoreInterface
+	var verifier middleware.TokenVerifier
+	var roles middleware.RoleAssigner
+
+	// DEV_AUTH trusts any bearer token, so it is opt-in and limited to in-memory stores
+	memoryBackend := os.Getenv("STORE_BACKEND") == "memory"
+	devAuth := os.Getenv("DEV_AUTH") == "true"
+	if devAuth && !memoryBackend {
+		log.Fatalf("DEV_AUTH=true is only allowed with STORE_BACKEND=memory")
+	}
+
+	// Firebase is needed for Firestore and for verifying ID tokens
+	if !memoryBackend || !devAuth {
+		if err := firebase.InitializeFirebase(ctx); err != nil {
+			log.Fatalf("Failed to initialize Firebase: %v", err)
+		}
+		defer firebase.Close()
 	}
-	defer firebase.Close()
 
-	// Initialize Firestore stores
-	todoStore := store.NewFirestoreStore(ctx)
-	blogStore := store.NewBlogStore(ctx)
+	if memoryBackend {
+		// In-memory stores, useful for local development without Firestore
+		log.Println("Using in-memory stores (STORE_BACKEND=memory), data will not be persisted")
+		todoStore = store.NewTodoStore()
+		blogStore = store.NewMemoryBlogStore()
+		commentStore = store.NewMemoryCommentStore()
+	} else {
+		// Initialize Firestore stores
+		// Both stores share one allocator so IDs come from transactional counters
+		ids := store.NewFirestoreIDAllocator()
+		firestoreTodos := store.NewFirestoreStore(ids)
+		todoStore = firestoreTodos
+		blogStore = store.NewBlogStore(ids)
+		commentStore = store.NewCommentStore(ids)
+
+		// Todos created before ownership are reachable by nobody until they get an owner
+		legacyOwner := os.Getenv("LEGACY_TODO_OWNER")
+		if n, err := firestoreTodos.AssignMissingOwners(ctx, legacyOwner); err != nil {
+			log.Printf("Failed to assign owners to todos: %v", err)
+		} else if n > 0 && legacyOwner != "" {
+			log.Printf("Assigned %d todos without owner to %s", n, legacyOwner)
+		} else if n > 0 {
+			log.Printf("%d todos without owner are hidden from every user, set LEGACY_TODO_OWNER to assign them", n)
+		}
+
+		// Todos created before manual ordering are left out of lists sorted by position
+		if n, err := firestoreTodos.AssignMissingPositions(ctx); err != nil {
+			log.Printf("Failed to assign positions to todos: %v", err)
+		} else if n > 0 {
+			log.Printf("Assigned positions to %d todos", n)
+		}
+
+		// Todos created before priorities are left out of lists sorted by priority
+		if n, err := firestoreTodos.AssignMissingPriorityRanks(ctx); err != nil {
+			log.Printf("Failed to assign priority ranks to todos: %v", err)
+		} else if n > 0 {
+			log.Printf("Assigned priority ranks to %d todos", n)
+		}
+	}
+
+	if devAuth {
+		// The bearer token ("<uid>" or "<uid>:<role>") is trusted as is
+		log.Println("WARNING: using insecure development authentication (DEV_AUTH=true), any bearer token is accepted")
+		devVerifier := middleware.NewDevVerifier()
+		verifier = devVerifier
+		roles = devVerifier
+	} else {
+		// Write routes verify Firebase ID tokens, so the server cannot run without Auth
+		if firebase.AuthClient == nil {
+			log.Fatalf("Firebase Auth client is required to authenticate requests")
+		}
+		verifier = firebase.AuthClient
+		roles = firebase.AuthClient
+	}
+
+	// Listeners are notified of blog changes made through the handlers
+	observedBlogs := store.NewObservedBlogStore(blogStore)
+	blogStore = observedBlogs
+	observedTodos := store.NewObservedTodoStore(todoStore)
+	todoStore = observedTodos
+
+	// The search index is built from the stores at startup and then kept in sync
+	// with the writes made through this instance
+	searchIndex := search.NewIndex()
+	observedBlogs.Subscribe(searchIndex.OnBlogEvent)
+	observedTodos.Subscribe(searchIndex.OnTodoEvent)
+	if err := searchIndex.Rebuild(ctx, blogStore, todoStore); err != nil {
+		log.Printf("Failed to build search index: %v", err)
+	} else {
+		log.Printf("Search index built with %d documents", searchIndex.Len())
+	}
 
 	// Initialize handlers
 	todoHandler := handlers.NewTodoHandler(todoStore)
-	blogHandler := handlers.NewBlogHandler(blogStore)
+	listHandler := handlers.NewListHandler(todoStore)
+	// Optional comma-separated words left out of generated slugs
+	var stopWords []string
+	if envStopWords := os.Getenv("SLUG_STOP_WORDS"); envStopWords != "" {
+		stopWords = strings.Split(envStopWords, ",")
+	}
+	slugger := slug.New(slug.Options{StopWords: stopWords})
+
+	blogHandler := handlers.NewBlogHandler(blogStore, slugger)
+	adminHandler := handlers.NewAdminHandler(roles)
+
+	// Public URL of the blog frontend, used to build links in feeds
+	siteURL := "https://thanktoanf.online"
+	if envSiteURL := os.Getenv("SITE_URL"); envSiteURL != "" {
+		siteURL = envSiteURL
+	}
+	feedHandler := handlers.NewFeedHandler(blogStore, siteURL)
+	sitemapHandler := handlers.NewSitemapHandler(blogStore, siteURL)
+	observedBlogs.Subscribe(sitemapHandler.Invalidate)
+	searchHandler := handlers.NewSearchHandler(searchIndex)
+	tagHandler := handlers.NewTagHandler(blogStore)
+	observedBlogs.Subscribe(tagHandler.Invalidate)
+
+	// Anonymous comments are off unless ALLOW_ANONYMOUS_COMMENTS is set to true
+	allowAnonymousComments := false
+	if envAnonymous := os.Getenv("ALLOW_ANONYMOUS_COMMENTS"); envAnonymous != "" {
+		allow, err := strconv.ParseBool(envAnonymous)
+		if err != nil {
+			log.Fatalf("Invalid ALLOW_ANONYMOUS_COMMENTS %q: must be true or false", envAnonymous)
+		}
+		allowAnonymousComments = allow
+	}
+	commentHandler := handlers.NewCommentHandler(commentStore, blogStore, allowAnonymousComments)
+	observedBlogs.Subscribe(commentHandler.OnBlogEvent)
 
 	// Setup router
 	router := mux.NewRouter()
@@ -103,23 +231,99 @@ func main() {
 	// Apply CORS middleware to all routes
 	router.Use(corsMiddleware)
 
+	// Todo routes and mutating blog routes require a Firebase ID token (Authorization: Bearer <token>)
+	requireAuth := func(h http.HandlerFunc) http.Handler {
+		return middleware.Authenticate(verifier)(h)
+	}
+	// Search and comment creation are open to anonymous callers, who only see
+	// published blogs and may only comment when anonymous comments are allowed
+	optionalAuth := func(h http.HandlerFunc) http.Handler {
+		return middleware.OptionalAuthenticate(verifier)(h)
+	}
+
 	// API routes
 	api := router.PathPrefix("/api").Subrouter()
 	
 	// Todo routes
-	api.HandleFunc("/todos", todoHandler.GetAllTodos).Methods("GET")
-	api.HandleFunc("/todos/{id}", todoHandler.GetTodoByID).Methods("GET")
-	api.HandleFunc("/todos", todoHandler.CreateTodo).Methods("POST")
-	api.HandleFunc("/todos/{id}", todoHandler.UpdateTodo).Methods("PUT")
-	api.HandleFunc("/todos/{id}", todoHandler.DeleteTodo).Methods("DELETE")
+	api.Handle("/todos", requireAuth(todoHandler.GetAllTodos)).Methods("GET")
+	api.Handle("/todos/{id}", requireAuth(todoHandler.GetTodoByID)).Methods("GET")
+	api.Handle("/todos", requireAuth(todoHandler.CreateTodo)).Methods("POST")
+	api.Handle("/todos/{id}", requireAuth(todoHandler.UpdateTodo)).Methods("PUT")
+	api.Handle("/todos/{id}", requireAuth(todoHandler.DeleteTodo)).Methods("DELETE")
+	api.Handle("/todos:batch", requireAuth(todoHandler.BatchTodos)).Methods("POST")
+	api.Handle("/todos/{id}/move", requireAuth(todoHandler.MoveTodo)).Methods("POST")
+	api.Handle("/todos/{id}/occurrences", requireAuth(todoHandler.GetOccurrences)).Methods("GET")
+	api.Handle("/todos/{id}/items", requireAuth(todoHandler.ListItems)).Methods("GET")
+	api.Handle("/todos/{id}/items", requireAuth(todoHandler.CreateItem)).Methods("POST")
+	api.Handle("/todos/{id}/items/reorder", requireAuth(todoHandler.ReorderItems)).Methods("POST")
+	api.Handle("/todos/{id}/items/{itemID:[0-9]+}", requireAuth(todoHandler.UpdateItem)).Methods("PUT")
+	api.Handle("/todos/{id}/items/{itemID:[0-9]+}", requireAuth(todoHandler.DeleteItem)).Methods("DELETE")
+
+	// Todo list routes
+	api.Handle("/lists", requireAuth(listHandler.GetAllLists)).Methods("GET")
+	api.Handle("/lists", requireAuth(listHandler.CreateList)).Methods("POST")
+	api.Handle("/lists/{id}", requireAuth(listHandler.GetListByID)).Methods("GET")
+	api.Handle("/lists/{id}", requireAuth(listHandler.UpdateList)).Methods("PUT")
+	api.Handle("/lists/{id}", requireAuth(listHandler.DeleteList)).Methods("DELETE")
+	api.Handle("/lists/{id}/todos", requireAuth(listHandler.GetListTodos)).Methods("GET")
 
 	// Blog routes
 	api.HandleFunc("/blogs", blogHandler.GetAllBlogs).Methods("GET")
 	api.HandleFunc("/blogs/{id}", blogHandler.GetBlogByID).Methods("GET")
 	api.HandleFunc("/blogs/slug/{slug}", blogHandler.GetBlogBySlug).Methods("GET")
-	api.HandleFunc("/blogs", blogHandler.CreateBlog).Methods("POST")
-	api.HandleFunc("/blogs/{id}", blogHandler.UpdateBlog).Methods("PUT")
-	api.HandleFunc("/blogs/{id}", blogHandler.DeleteBlog).Methods("DELETE")
+	api.HandleFunc("/blogs/{id}/html", blogHandler.GetBlogHTML).Methods("GET")
+	api.Handle("/blogs", requireAuth(blogHandler.CreateBlog)).Methods("POST")
+	api.Handle("/blogs/{id}", requireAuth(blogHandler.UpdateBlog)).Methods("PUT")
+	api.Handle("/blogs/{id}", requireAuth(blogHandler.DeleteBlog)).Methods("DELETE")
+	api.Handle("/blogs:batch", requireAuth(blogHandler.BatchBlogs)).Methods("POST")
+	api.Handle("/blogs/{id}/revisions", requireAuth(blogHandler.ListBlogRevisions)).Methods("GET")
+	api.Handle("/blogs/{id}/revisions/diff", requireAuth(blogHandler.DiffBlogRevisions)).Methods("GET")
+	api.Handle("/blogs/{id}/revisions/{rev:[0-9]+}", requireAuth(blogHandler.GetBlogRevision)).Methods("GET")
+	api.Handle("/blogs/{id}/revisions/{rev:[0-9]+}/restore", requireAuth(blogHandler.RestoreBlogRevision)).Methods("POST")
+
+	// Comment routes
+	api.HandleFunc("/blogs/{id}/comments", commentHandler.GetComments).Methods("GET")
+	api.Handle("/blogs/{id}/comments", optionalAuth(commentHandler.CreateComment)).Methods("POST")
+	api.Handle("/blogs/{id}/comments/{commentID}", requireAuth(commentHandler.DeleteComment)).Methods("DELETE")
+
+	// Admin routes
+	api.Handle("/admin/blogs", requireAuth(blogHandler.ListAdminBlogs)).Methods("GET")
+	api.Handle("/admin/blogs/{id}", requireAuth(blogHandler.GetAdminBlog)).Methods("GET")
+	api.Handle("/admin/comments", requireAuth(commentHandler.ListModerationQueue)).Methods("GET")
+	api.Handle("/admin/blogs/{id}/comments/{commentID}/approve", requireAuth(commentHandler.ApproveComment)).Methods("POST")
+	api.Handle("/admin/blogs/{id}/comments/{commentID}/reject", requireAuth(commentHandler.RejectComment)).Methods("POST")
+	api.Handle("/admin/users/{uid}/role", requireAuth(adminHandler.SetUserRole)).Methods("PUT")
+
+	// Tag routes
+	api.HandleFunc("/tags", tagHandler.GetAllTags).Methods("GET")
+	api.HandleFunc("/tags/{tag}/blogs", tagHandler.GetTagBlogs).Methods("GET")
+	api.Handle("/tags/merge", requireAuth(tagHandler.MergeTags)).Methods("POST")
+
+	// Search routes
+	api.Handle("/search", optionalAuth(searchHandler.Search)).Methods("GET")
+
+	// Publish scheduled blogs in the background
+	publishInterval := time.Minute
+	if envInterval := os.Getenv("PUBLISH_INTERVAL"); envInterval != "" {
+		interval, err := time.ParseDuration(envInterval)
+		if err != nil || interval <= 0 {
+			log.Fatalf("Invalid PUBLISH_INTERVAL %q: must be a positive duration such as 30s or 1m", envInterval)
+		}
+		publishInterval = interval
+	}
+	schedulerCtx, stopScheduler := context.WithCancel(ctx)
+	defer stopScheduler()
+	go scheduler.NewPublisher(blogStore, publishInterval).Run(schedulerCtx)
+
+	// Feeds of published blogs
+	router.HandleFunc("/feed.xml", feedHandler.RSS).Methods("GET")
+	router.HandleFunc("/atom.xml", feedHandler.Atom).Methods("GET")
+	router.HandleFunc("/tags/{tag}/feed.xml", feedHandler.RSS).Methods("GET")
+	router.HandleFunc("/tags/{tag}/atom.xml", feedHandler.Atom).Methods("GET")
+
+	// Sitemap of published blogs and tag pages
+	router.HandleFunc("/sitemap.xml", sitemapHandler.Sitemap).Methods("GET")
+	router.HandleFunc("/sitemap-{n:[0-9]+}.xml", sitemapHandler.SitemapPart).Methods("GET")
 
 	// Health check endpoint
 	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
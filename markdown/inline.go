@@ -0,0 +1,684 @@
+package markdown
+
+import (
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+var autolinkRe = regexp.MustCompile(`^<((?:https?|mailto):[^\s<>]+)>`)
+
+// Limits that keep rendering time linear in the size of the source, whatever its shape
+const (
+	// maxNesting bounds the depth of nested block quotes and lists and of nested
+	// emphasis and links. Deeper content is rendered without the extra markup.
+	maxNesting = 32
+	// maxInlineLength is the size in bytes above which a paragraph or heading is
+	// escaped as plain text instead of being parsed for inline markup
+	maxInlineLength = 64 << 10
+	// maxLinkParens bounds the nesting of parentheses in a link destination
+	maxLinkParens = 32
+)
+
+// renderInline renders inline Markdown (emphasis, code spans, links, images,
+// line breaks) to HTML, escaping all other text
+func renderInline(s string) string {
+	if len(s) > maxInlineLength {
+		return html.EscapeString(s)
+	}
+	p := &inlineParser{s: s}
+	p.parse()
+	var b strings.Builder
+	writeInlines(&b, p.nodes.first)
+	return b.String()
+}
+
+// inlineKind is the type of an inline node
+type inlineKind int
+
+const (
+	textInline     inlineKind = iota // Literal text, escaped when written
+	codeInline                       // Code span
+	htmlInline                       // Markup written as is (line breaks)
+	emphasisInline                   // <em>, <strong> or <del> around the children
+	linkInline
+	imageInline
+)
+
+// inline is a node of parsed inline content. Siblings form a doubly linked list,
+// so closing emphasis or a link wraps a run of them in constant time.
+type inline struct {
+	kind     inlineKind
+	text     string // Text of text and code nodes, HTML of html nodes, tag of emphasis nodes
+	dest     string
+	title    string
+	children inlineList
+
+	prev, next *inline
+}
+
+// inlineList is a doubly linked list of sibling inline nodes
+type inlineList struct {
+	first, last *inline
+}
+
+func (l *inlineList) append(n *inline) {
+	n.prev = l.last
+	if l.last != nil {
+		l.last.next = n
+	} else {
+		l.first = n
+	}
+	l.last = n
+}
+
+// insertAfter inserts n after the node at
+func (l *inlineList) insertAfter(at, n *inline) {
+	n.prev, n.next = at, at.next
+	if at.next != nil {
+		at.next.prev = n
+	} else {
+		l.last = n
+	}
+	at.next = n
+}
+
+func (l *inlineList) remove(n *inline) {
+	if n.prev != nil {
+		n.prev.next = n.next
+	} else {
+		l.first = n.next
+	}
+	if n.next != nil {
+		n.next.prev = n.prev
+	} else {
+		l.last = n.prev
+	}
+	n.prev, n.next = nil, nil
+}
+
+// cut removes the nodes between from and to (the end of the list when to is nil)
+// and returns them as a list
+func (l *inlineList) cut(from, to *inline) inlineList {
+	first := from.next
+	if first == to {
+		return inlineList{}
+	}
+	last := l.last
+	if to != nil {
+		last = to.prev
+		to.prev = from
+	} else {
+		l.last = from
+	}
+	from.next = to
+	first.prev, last.next = nil, nil
+	return inlineList{first: first, last: last}
+}
+
+// delimiter is an entry of the delimiter stack: a run of *, _ or ~ that may open
+// or close emphasis
+type delimiter struct {
+	node     *inline // Text node holding the delimiter characters not used yet
+	char     byte
+	length   int // Length of the original run
+	canOpen  bool
+	canClose bool
+
+	prev, next *delimiter
+}
+
+// bracket is an entry of the bracket stack: the "[" or "![" of a possible link or image
+type bracket struct {
+	node   *inline
+	image  bool
+	links  int        // Number of links formed before the bracket
+	delims *delimiter // Top of the delimiter stack when the bracket was pushed
+	prev   *bracket
+}
+
+// inlineParser parses inline Markdown in a single pass. Emphasis and links are
+// matched with delimiter and bracket stacks as described by the CommonMark spec,
+// so unmatched delimiters never cause a rescan of the rest of the text.
+type inlineParser struct {
+	s        string
+	nodes    inlineList
+	delims   *delimiter // Top of the delimiter stack
+	brackets *bracket   // Top of the bracket stack
+	links    int        // Number of links formed so far
+
+	// backticks lists the positions of backtick runs by run length. It is built
+	// on the first backtick and consumed from the front as parsing moves on.
+	backticks map[int][]int
+}
+
+func (p *inlineParser) parse() {
+	s := p.s
+	for i := 0; i < len(s); {
+		c := s[i]
+		switch c {
+		case '\\':
+			if i+1 < len(s) && isASCIIPunct(s[i+1]) {
+				p.text(s[i+1 : i+2])
+				i += 2
+				continue
+			}
+			if i+1 < len(s) && s[i+1] == '\n' {
+				p.nodes.append(&inline{kind: htmlInline, text: "<br />"})
+				p.text("\n")
+				i += 2
+				continue
+			}
+
+		case '`':
+			if end, content, ok := p.codeSpan(i); ok {
+				p.nodes.append(&inline{kind: codeInline, text: content})
+				i = end
+				continue
+			}
+			// An unmatched backtick run is literal text
+			n := runLength(s, i, '`')
+			p.text(s[i : i+n])
+			i += n
+			continue
+
+		case '!':
+			if i+1 < len(s) && s[i+1] == '[' {
+				p.pushBracket("![", true)
+				i += 2
+				continue
+			}
+
+		case '[':
+			p.pushBracket("[", false)
+			i++
+			continue
+
+		case ']':
+			i = p.closeBracket(i)
+			continue
+
+		case '<':
+			if m := autolinkRe.FindStringSubmatch(s[i:]); m != nil {
+				link := &inline{kind: linkInline, dest: m[1]}
+				link.children.append(&inline{kind: textInline, text: m[1]})
+				p.nodes.append(link)
+				i += len(m[0])
+				continue
+			}
+
+		case '*', '_', '~':
+			i = p.delimiterRun(i)
+			continue
+
+		case ' ':
+			// Spaces before a newline are dropped; two or more make a hard line break
+			n := runLength(s, i, ' ')
+			if i+n < len(s) && s[i+n] == '\n' {
+				if n >= 2 {
+					p.nodes.append(&inline{kind: htmlInline, text: "<br />"})
+				}
+			} else {
+				p.text(s[i : i+n])
+			}
+			i += n
+			continue
+
+		default:
+			j := i + 1
+			for j < len(s) && !isInlineSpecial(s[j]) {
+				j++
+			}
+			p.text(s[i:j])
+			i = j
+			continue
+		}
+
+		p.text(s[i : i+1])
+		i++
+	}
+	p.processEmphasis(nil)
+}
+
+// text appends literal text
+func (p *inlineParser) text(s string) {
+	p.nodes.append(&inline{kind: textInline, text: s})
+}
+
+// codeSpan parses a code span opened by the backtick run at s[i]. The closing run
+// is looked up in the index of backtick runs instead of scanning the rest of s.
+func (p *inlineParser) codeSpan(i int) (end int, content string, ok bool) {
+	s := p.s
+	if p.backticks == nil {
+		p.backticks = make(map[int][]int)
+		for j := 0; j < len(s); {
+			if s[j] != '`' {
+				j++
+				continue
+			}
+			m := runLength(s, j, '`')
+			p.backticks[m] = append(p.backticks[m], j)
+			j += m
+		}
+	}
+
+	n := runLength(s, i, '`')
+	runs := p.backticks[n]
+	for len(runs) > 0 && runs[0] <= i {
+		runs = runs[1:]
+	}
+	p.backticks[n] = runs
+	if len(runs) == 0 {
+		return 0, "", false
+	}
+
+	j := runs[0]
+	content = strings.ReplaceAll(s[i+n:j], "\n", " ")
+	if len(content) > 2 && content[0] == ' ' && content[len(content)-1] == ' ' && strings.TrimSpace(content) != "" {
+		content = content[1 : len(content)-1]
+	}
+	return j + n, content, true
+}
+
+// delimiterRun appends the run of *, _ or ~ at s[i] as text and pushes it on the
+// delimiter stack when it can open or close emphasis. Only runs of exactly two
+// tildes delimit strikethrough.
+func (p *inlineParser) delimiterRun(i int) int {
+	s := p.s
+	c := s[i]
+	n := runLength(s, i, c)
+	node := &inline{kind: textInline, text: s[i : i+n]}
+	p.nodes.append(node)
+	if c == '~' && n != 2 {
+		return i + n
+	}
+
+	// A run opens when followed by text and closes when preceded by text
+	before, _ := utf8.DecodeLastRuneInString(s[:i])
+	after, _ := utf8.DecodeRuneInString(s[i+n:])
+	d := &delimiter{
+		node:     node,
+		char:     c,
+		length:   n,
+		canOpen:  i+n < len(s) && !unicode.IsSpace(after),
+		canClose: i > 0 && !unicode.IsSpace(before),
+	}
+	// Underscores inside words are literal
+	if c == '_' {
+		d.canOpen = d.canOpen && !(i > 0 && isWordByte(s[i-1]))
+		d.canClose = d.canClose && !(i+n < len(s) && isWordByte(s[i+n]))
+	}
+	if d.canOpen || d.canClose {
+		d.prev = p.delims
+		if p.delims != nil {
+			p.delims.next = d
+		}
+		p.delims = d
+	}
+	return i + n
+}
+
+func (p *inlineParser) removeDelimiter(d *delimiter) {
+	if d.prev != nil {
+		d.prev.next = d.next
+	}
+	if d.next != nil {
+		d.next.prev = d.prev
+	} else {
+		p.delims = d.prev
+	}
+}
+
+// processEmphasis matches the delimiters above bottom into emphasis nodes and
+// removes them from the stack. openersBottom records, per kind of closer, below
+// which delimiter no opener can be found, so no part of the stack is searched twice.
+func (p *inlineParser) processEmphasis(bottom *delimiter) {
+	var openersBottom [3][3][2]*delimiter
+	for i := range openersBottom {
+		for j := range openersBottom[i] {
+			openersBottom[i][j] = [2]*delimiter{bottom, bottom}
+		}
+	}
+
+	var closer *delimiter
+	for d := p.delims; d != bottom; d = d.prev {
+		closer = d
+	}
+
+	for closer != nil {
+		if !closer.canClose {
+			closer = closer.next
+			continue
+		}
+
+		ob := &openersBottom[strings.IndexByte("*_~", closer.char)][closer.length%3][boolIndex(closer.canOpen)]
+		opener := closer.prev
+		for opener != bottom && opener != *ob && !matchesCloser(opener, closer) {
+			opener = opener.prev
+		}
+		if opener == bottom || opener == *ob {
+			*ob = closer.prev
+			next := closer.next
+			if !closer.canOpen {
+				p.removeDelimiter(closer)
+			}
+			closer = next
+			continue
+		}
+
+		use, tag := 1, "em"
+		switch {
+		case closer.char == '~':
+			use, tag = 2, "del"
+		case len(opener.node.text) >= 2 && len(closer.node.text) >= 2:
+			use, tag = 2, "strong"
+		}
+		opener.node.text = opener.node.text[use:]
+		closer.node.text = closer.node.text[use:]
+
+		emphasis := &inline{kind: emphasisInline, text: tag, children: p.nodes.cut(opener.node, closer.node)}
+		p.nodes.insertAfter(opener.node, emphasis)
+		// Delimiters inside the emphasis can no longer match
+		opener.next, closer.prev = closer, opener
+
+		if opener.node.text == "" {
+			p.nodes.remove(opener.node)
+			p.removeDelimiter(opener)
+		}
+		if closer.node.text == "" {
+			next := closer.next
+			p.nodes.remove(closer.node)
+			p.removeDelimiter(closer)
+			closer = next
+		}
+	}
+
+	// Unmatched delimiters stay as literal text
+	for p.delims != bottom {
+		p.removeDelimiter(p.delims)
+	}
+}
+
+// matchesCloser reports whether opener can be closed by closer. Following
+// CommonMark's rule of three, a run that can both open and close does not match
+// a run when their lengths add up to a multiple of 3, unless both lengths are.
+func matchesCloser(opener, closer *delimiter) bool {
+	if opener.char != closer.char || !opener.canOpen {
+		return false
+	}
+	if (opener.canClose || closer.canOpen) && (opener.length+closer.length)%3 == 0 {
+		return opener.length%3 == 0 && closer.length%3 == 0
+	}
+	return true
+}
+
+func boolIndex(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func (p *inlineParser) pushBracket(text string, image bool) {
+	node := &inline{kind: textInline, text: text}
+	p.nodes.append(node)
+	p.brackets = &bracket{node: node, image: image, links: p.links, delims: p.delims, prev: p.brackets}
+}
+
+// closeBracket handles the ']' at s[i]: when the innermost open bracket is followed
+// by a link destination, the nodes after it become a link or image. It returns the
+// index after the parsed text.
+func (p *inlineParser) closeBracket(i int) int {
+	b := p.brackets
+	if b == nil {
+		p.text("]")
+		return i + 1
+	}
+	p.brackets = b.prev
+
+	// Links cannot contain other links
+	if !b.image && b.links != p.links {
+		p.text("]")
+		return i + 1
+	}
+	dest, title, end, ok := parseLinkTail(p.s, i+1)
+	if !ok {
+		p.text("]")
+		return i + 1
+	}
+
+	p.processEmphasis(b.delims)
+	link := &inline{kind: linkInline, dest: dest, title: title}
+	if b.image {
+		link.kind = imageInline
+	} else {
+		p.links++
+	}
+	link.children = p.nodes.cut(b.node, nil)
+	p.nodes.insertAfter(b.node, link)
+	p.nodes.remove(b.node)
+	return end
+}
+
+// parseLinkTail parses the (dest "title") part of an inline link starting at s[i]
+// and returns the index after it. Every scan stops at the first character that
+// cannot continue the part being parsed, so failed attempts stay short.
+func parseLinkTail(s string, i int) (dest, title string, end int, ok bool) {
+	if i >= len(s) || s[i] != '(' {
+		return "", "", 0, false
+	}
+
+	k := skipSpaces(s, i+1)
+	// Destination, optionally in angle brackets
+	if k < len(s) && s[k] == '<' {
+		e := strings.IndexAny(s[k+1:], "<>\n")
+		if e < 0 || s[k+1+e] != '>' {
+			return "", "", 0, false
+		}
+		dest = s[k+1 : k+1+e]
+		k += e + 2
+	} else {
+		start, parens := k, 0
+	scan:
+		for ; k < len(s); k++ {
+			switch s[k] {
+			case '(':
+				if parens++; parens > maxLinkParens {
+					return "", "", 0, false
+				}
+			case ')':
+				if parens == 0 {
+					break scan
+				}
+				parens--
+			case ' ', '\n':
+				break scan
+			}
+		}
+		dest = s[start:k]
+	}
+
+	// Optional title. Quoted titles end at the next quote of the same kind, and
+	// parenthesized titles cannot contain an opening parenthesis.
+	k = skipSpaces(s, k)
+	if k < len(s) && (s[k] == '"' || s[k] == '\'' || s[k] == '(') {
+		stops := s[k : k+1]
+		if s[k] == '(' {
+			stops = "()"
+		}
+		e := strings.IndexAny(s[k+1:], stops)
+		if e < 0 || s[k+1+e] == '(' {
+			return "", "", 0, false
+		}
+		title = s[k+1 : k+1+e]
+		k = skipSpaces(s, k+e+2)
+	}
+
+	if k >= len(s) || s[k] != ')' {
+		return "", "", 0, false
+	}
+	return dest, title, k + 1, true
+}
+
+// writeInlines writes parsed inline nodes as HTML. Nested nodes are walked with an
+// explicit stack; emphasis and links nested deeper than maxNesting are written as
+// their content only.
+func writeInlines(b *strings.Builder, first *inline) {
+	type frame struct {
+		next  *inline
+		close string
+	}
+	stack := []frame{{next: first}}
+	for len(stack) > 0 {
+		top := &stack[len(stack)-1]
+		n := top.next
+		if n == nil {
+			b.WriteString(top.close)
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		top.next = n.next
+		deep := len(stack) > maxNesting
+
+		switch n.kind {
+		case textInline:
+			b.WriteString(html.EscapeString(n.text))
+
+		case codeInline:
+			b.WriteString("<code>")
+			b.WriteString(html.EscapeString(n.text))
+			b.WriteString("</code>")
+
+		case htmlInline:
+			b.WriteString(n.text)
+
+		case emphasisInline:
+			var close string
+			if !deep {
+				b.WriteString("<" + n.text + ">")
+				close = "</" + n.text + ">"
+			}
+			stack = append(stack, frame{next: n.children.first, close: close})
+
+		case linkInline:
+			var close string
+			if !deep {
+				fmt.Fprintf(b, `<a href="%s"`, html.EscapeString(safeURL(n.dest)))
+				if n.title != "" {
+					fmt.Fprintf(b, ` title="%s"`, html.EscapeString(n.title))
+				}
+				b.WriteString(` rel="nofollow noopener">`)
+				close = "</a>"
+			}
+			stack = append(stack, frame{next: n.children.first, close: close})
+
+		case imageInline:
+			alt := strings.TrimSpace(inlineText(n.children.first))
+			if deep {
+				b.WriteString(html.EscapeString(alt))
+				break
+			}
+			fmt.Fprintf(b, `<img src="%s" alt="%s"`, html.EscapeString(safeURL(n.dest)), html.EscapeString(alt))
+			if n.title != "" {
+				fmt.Fprintf(b, ` title="%s"`, html.EscapeString(n.title))
+			}
+			b.WriteString(" />")
+		}
+	}
+}
+
+// inlineText returns the text of parsed inline nodes without markup, as used for
+// the alt text of images
+func inlineText(first *inline) string {
+	var b strings.Builder
+	stack := []*inline{first}
+	for len(stack) > 0 {
+		n := stack[len(stack)-1]
+		if n == nil {
+			stack = stack[:len(stack)-1]
+			continue
+		}
+		stack[len(stack)-1] = n.next
+
+		switch n.kind {
+		case textInline, codeInline:
+			b.WriteString(n.text)
+		case emphasisInline, linkInline, imageInline:
+			stack = append(stack, n.children.first)
+		}
+	}
+	return b.String()
+}
+
+// runLength counts consecutive occurrences of c starting at s[i]
+func runLength(s string, i int, c byte) int {
+	n := 0
+	for i+n < len(s) && s[i+n] == c {
+		n++
+	}
+	return n
+}
+
+func skipSpaces(s string, i int) int {
+	for i < len(s) && (s[i] == ' ' || s[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+// safeURL returns the URL when it is relative or uses the http, https or mailto
+// scheme, and "#" otherwise (javascript:, data:, vbscript: ...)
+func safeURL(raw string) string {
+	u := strings.TrimSpace(raw)
+	// Browsers ignore control characters and spaces when reading the scheme
+	cleaned := strings.Map(func(r rune) rune {
+		if r <= ' ' || r == 0x7f {
+			return -1
+		}
+		return r
+	}, u)
+	if i := strings.IndexAny(cleaned, ":/?#"); i >= 0 && cleaned[i] == ':' {
+		switch strings.ToLower(cleaned[:i]) {
+		case "http", "https", "mailto":
+		default:
+			return "#"
+		}
+	}
+	return u
+}
+
+// anchorID converts heading text to an ID: lowercase letters and digits
+// separated by single hyphens
+func anchorID(text string) string {
+	var b strings.Builder
+	pendingHyphen := false
+	for _, r := range strings.ToLower(text) {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			if pendingHyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			pendingHyphen = false
+			b.WriteRune(r)
+		case unicode.IsSpace(r) || r == '-' || r == '_':
+			pendingHyphen = true
+		}
+	}
+	return b.String()
+}
+
+func isASCIIPunct(c byte) bool {
+	return strings.IndexByte("!\"#$%&'()*+,-./:;<=>?@[\\]^_`{|}~", c) >= 0
+}
+
+// isInlineSpecial reports whether c may start inline markup
+func isInlineSpecial(c byte) bool {
+	return strings.IndexByte("\\`![]<*_~ ", c) >= 0
+}
+
+func isWordByte(c byte) bool {
+	return c >= 0x80 || c == '_' || (c >= '0' && c <= '9') || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
@@ -0,0 +1,133 @@
+package markdown
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRenderInline(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"em", "*em* and _em_", "<em>em</em> and <em>em</em>"},
+		{"strong", "**strong** and __strong__", "<strong>strong</strong> and <strong>strong</strong>"},
+		{"strikethrough", "~~del~~ ~single~", "<del>del</del> ~single~"},
+		{"nested emphasis", "*a **b** c*", "<em>a <strong>b</strong> c</em>"},
+		{"strong and em", "***both***", "<em><strong>both</strong></em>"},
+		{"intraword underscores", "snake_case_name", "snake_case_name"},
+		{"intraword stars", "2*3*4", "2<em>3</em>4"},
+		{"spaced delimiters", "a * b * c", "a * b * c"},
+		{"unclosed", "**a *b [c", "**a *b [c"},
+		{"overlapping", "*a _b* c_", "<em>a _b</em> c_"},
+		{"code span", "`a *b*` and ``x ` y``", "<code>a *b*</code> and <code>x ` y</code>"},
+		{"unmatched backticks", "``a`", "``a`"},
+		{"link", `[Go](https://go.dev "The Go site")`, `<a href="https://go.dev" title="The Go site" rel="nofollow noopener">Go</a>`},
+		{"link with emphasis", "[*a*](/b) *[c](/d)*", `<a href="/b" rel="nofollow noopener"><em>a</em></a> <em><a href="/d" rel="nofollow noopener">c</a></em>`},
+		{"link with brackets", "[a [b] c](/p)", `<a href="/p" rel="nofollow noopener">a [b] c</a>`},
+		{"link with parens", "[a](/b(c)d)", `<a href="/b(c)d" rel="nofollow noopener">a</a>`},
+		{"angle destination", "[a](<b c>)", `<a href="b c" rel="nofollow noopener">a</a>`},
+		{"no nested links", "[a [b](/c)](/d)", `[a <a href="/c" rel="nofollow noopener">b</a>](/d)`},
+		{"not a link", "[a] (b) [c](d", "[a] (b) [c](d"},
+		{"image", `![a *b*](/i.png "t")`, `<img src="/i.png" alt="a b" title="t" />`},
+		{"autolink", "<https://go.dev>", `<a href="https://go.dev" rel="nofollow noopener">https://go.dev</a>`},
+		{"html escaped", `<script>alert("x")</script> & co`, "&lt;script&gt;alert(&#34;x&#34;)&lt;/script&gt; &amp; co"},
+		{"escaped punctuation", `\*a\* \[b\](c) \<d>`, "*a* [b](c) &lt;d&gt;"},
+		{"hard breaks", "a  \nb\\\nc", "a<br />\nb<br />\nc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := renderInline(tt.in); got != tt.want {
+				t.Errorf("renderInline(%q) =\n%s\nwant\n%s", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderUnsafeURLs(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"[a](javascript:alert(1))", `<a href="#" rel="nofollow noopener">a</a>`},
+		{"[a](JavaScript:alert(1))", `<a href="#" rel="nofollow noopener">a</a>`},
+		{"[a](<java\x01script:alert(1)>)", `<a href="#" rel="nofollow noopener">a</a>`},
+		{"[a](data:text/html;base64,PHNjcmlwdD4=)", `<a href="#" rel="nofollow noopener">a</a>`},
+		{"![a](data:image/svg+xml,x)", `<img src="#" alt="a" />`},
+		{"[a](vbscript:x)", `<a href="#" rel="nofollow noopener">a</a>`},
+		{`[a](/x?q="><script>)`, `<a href="/x?q=&#34;&gt;&lt;script&gt;" rel="nofollow noopener">a</a>`},
+	}
+	for _, tt := range tests {
+		if got := renderInline(tt.in); got != tt.want {
+			t.Errorf("renderInline(%q) = %s, want %s", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestSafeURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want string
+	}{
+		{"https://go.dev/doc", "https://go.dev/doc"},
+		{"http://example.com", "http://example.com"},
+		{"mailto:a@b.c", "mailto:a@b.c"},
+		{"/blog/a", "/blog/a"},
+		{"#section", "#section"},
+		{"a/b:c", "a/b:c"},
+		{"?q=a:b", "?q=a:b"},
+		{"javascript:alert(1)", "#"},
+		{" JAVASCRIPT:alert(1)", "#"},
+		{"java\tscript:alert(1)", "#"},
+		{"java\nscript:alert(1)", "#"},
+		{"data:text/html,<script>", "#"},
+		{"vbscript:msgbox", "#"},
+		{"file:///etc/passwd", "#"},
+	}
+	for _, tt := range tests {
+		if got := safeURL(tt.url); got != tt.want {
+			t.Errorf("safeURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestRenderNestingLimit(t *testing.T) {
+	deepList := strings.Repeat("- ", 100) + "x"
+	html := Render(deepList).HTML
+	if got := strings.Count(html, "<ul>"); got != maxNesting+1 {
+		t.Errorf("rendered %d nested lists, want %d", got, maxNesting+1)
+	}
+
+	deepEmphasis := strings.Repeat("*a ", 100) + "b" + strings.Repeat(" c*", 100)
+	html = renderInline(deepEmphasis)
+	if got := strings.Count(html, "<em>"); got != maxNesting {
+		t.Errorf("rendered %d nested <em>, want %d", got, maxNesting)
+	}
+	if got := strings.Count(html, "</em>"); got != maxNesting {
+		t.Errorf("rendered %d closing </em>, want %d", got, maxNesting)
+	}
+}
+
+// TestRenderLinearTime renders inputs that made the previous renderer rescan the
+// rest of the text after every failed match
+func TestRenderLinearTime(t *testing.T) {
+	inputs := map[string]string{
+		"unclosed emphasis": strings.Repeat("*a ", 20000),
+		"unclosed brackets": strings.Repeat("[", 50000),
+		"unclosed links":    strings.Repeat("[a](", 20000),
+		"unclosed code":     strings.Repeat("`a ``b ", 5000),
+		"nested lists":      strings.Repeat("- ", 5000) + "x",
+		"long nested list":  strings.Repeat("- ", 50000) + "x",
+		"nested quotes":     strings.Repeat(">", 5000) + " x",
+	}
+	for name, source := range inputs {
+		start := time.Now()
+		Render(source)
+		FirstParagraph(source)
+		if elapsed := time.Since(start); elapsed > 2*time.Second {
+			t.Errorf("%s: rendering took %v", name, elapsed)
+		}
+	}
+}
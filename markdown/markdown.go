@@ -0,0 +1,602 @@
+// Package markdown renders blog Markdown to sanitized HTML.
+//
+// It supports the CommonMark subset used by our posts: ATX and setext headings,
+// paragraphs, emphasis, strikethrough, code spans, fenced and indented code blocks,
+// block quotes, ordered and unordered lists, horizontal rules, links and images.
+// Raw HTML is never passed through: it is escaped like any other text, and link
+// and image URLs are restricted to safe schemes.
+package markdown
+
+import (
+	"errors"
+	"fmt"
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Heading is an entry of a document's table of contents
+type Heading struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	ID    string `json:"id"` // Anchor ID set on the rendered heading element
+}
+
+// Document is the result of rendering Markdown
+type Document struct {
+	HTML string
+	TOC  []Heading
+}
+
+// Limits checked by Validate on the Markdown of new and edited posts. Rendering stays
+// bounded without them, but they keep every render cheap.
+const (
+	// MaxLength is the maximum size of a document in bytes
+	MaxLength = 200 << 10
+	// MaxDepth is the maximum number of block quotes and list items around a block
+	MaxDepth = 16
+)
+
+var (
+	// ErrTooLong is returned by Validate for documents longer than MaxLength
+	ErrTooLong = errors.New("markdown: document too long")
+	// ErrTooDeep is returned by Validate for documents nested deeper than MaxDepth
+	ErrTooDeep = errors.New("markdown: block quotes and lists nested too deeply")
+)
+
+// Validate checks that source is at most MaxLength bytes long and does not nest
+// block quotes and lists more than MaxDepth levels deep
+func Validate(source string) error {
+	if len(source) > MaxLength {
+		return ErrTooLong
+	}
+	r := &renderer{usedIDs: make(map[string]int)}
+	r.renderBlocks(splitLines(source), false)
+	if r.deepest > MaxDepth {
+		return ErrTooDeep
+	}
+	return nil
+}
+
+// Render converts Markdown source to sanitized HTML and collects the headings
+func Render(source string) *Document {
+	r := &renderer{usedIDs: make(map[string]int)}
+	r.renderBlocks(splitLines(source), false)
+	return &Document{
+		HTML: r.out.String(),
+		TOC:  r.toc,
+	}
+}
+
+// TOCHTML renders the table of contents as nested unordered lists of anchor links
+func (d *Document) TOCHTML() string {
+	var b strings.Builder
+	var levels []int
+	for _, h := range d.TOC {
+		for len(levels) > 0 && levels[len(levels)-1] > h.Level {
+			b.WriteString("</li></ul>")
+			levels = levels[:len(levels)-1]
+		}
+		if len(levels) > 0 && levels[len(levels)-1] == h.Level {
+			b.WriteString("</li>")
+		} else {
+			b.WriteString("<ul>")
+			levels = append(levels, h.Level)
+		}
+		fmt.Fprintf(&b, `<li><a href="#%s">%s</a>`, html.EscapeString(h.ID), html.EscapeString(h.Text))
+	}
+	for range levels {
+		b.WriteString("</li></ul>")
+	}
+	return b.String()
+}
+
+// FirstParagraph returns the plain text of the first paragraph of Markdown source
+func FirstParagraph(source string) string {
+	lines := splitLines(source)
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		if isBlank(line) || isBlockStart(line) || indentOf(line) >= 4 {
+			if _, _, ok := fenceStart(line); ok {
+				i = skipFence(lines, i)
+			}
+			continue
+		}
+
+		var para []string
+		for ; i < len(lines) && !isBlank(lines[i]) && !isBlockStart(lines[i]); i++ {
+			para = append(para, strings.TrimSpace(lines[i]))
+		}
+		if i < len(lines) && setextLevel(lines[i]) > 0 {
+			// Setext headings are not paragraphs
+			continue
+		}
+		return strings.Join(strings.Fields(plainText(renderInline(strings.Join(para, "\n")))), " ")
+	}
+	return ""
+}
+
+// PlainText returns the text of Markdown source without markup, with whitespace
+// collapsed. Code blocks are kept as text.
+func PlainText(source string) string {
+	return strings.Join(strings.Fields(plainText(Render(source).HTML)), " ")
+}
+
+// renderer accumulates the HTML output and table of contents of one document
+type renderer struct {
+	out     strings.Builder
+	toc     []Heading
+	usedIDs map[string]int
+	depth   int // Number of enclosing block quotes and list items
+	deepest int // Largest depth of the blocks rendered so far
+}
+
+var (
+	atxHeadingRe = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+	tagRe        = regexp.MustCompile(`<[^>]*>`)
+)
+
+// splitLines normalizes line endings and tabs and splits source into lines
+func splitLines(source string) []string {
+	source = strings.ReplaceAll(source, "\r\n", "\n")
+	source = strings.ReplaceAll(source, "\r", "\n")
+	source = strings.ReplaceAll(source, "\t", "    ")
+	return strings.Split(source, "\n")
+}
+
+// matchListItem matches the first line of a list item: up to three spaces, a bullet
+// (-, * or +) or an ordered marker (up to nine digits and . or )), then spaces or
+// the end of the line. It returns the line, the indentation, the marker, the spaces
+// and the content, or nil. Only the start of the line is scanned, so that deeply
+// nested items on one line do not rescan it at every level.
+func matchListItem(line string) []string {
+	i := 0
+	for i < 3 && i < len(line) && line[i] == ' ' {
+		i++
+	}
+	j := i
+	if j < len(line) && (line[j] == '-' || line[j] == '*' || line[j] == '+') {
+		j++
+	} else {
+		for j < len(line) && j-i < 9 && line[j] >= '0' && line[j] <= '9' {
+			j++
+		}
+		if j == i || j == len(line) || (line[j] != '.' && line[j] != ')') {
+			return nil
+		}
+		j++
+	}
+	k := j
+	for k < len(line) && line[k] == ' ' {
+		k++
+	}
+	if k == j && k < len(line) {
+		return nil
+	}
+	return []string{line, line[:i], line[i:j], line[j:k], line[k:]}
+}
+
+func isBlank(line string) bool {
+	return strings.TrimSpace(line) == ""
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+// isBlockStart reports whether a line starts a block that interrupts a paragraph
+func isBlockStart(line string) bool {
+	if indentOf(line) >= 4 {
+		return false
+	}
+	trimmed := strings.TrimSpace(line)
+	if atxHeadingRe.MatchString(line) || isHorizontalRule(line) || strings.HasPrefix(trimmed, ">") {
+		return true
+	}
+	if _, _, ok := fenceStart(line); ok {
+		return true
+	}
+	if m := matchListItem(line); m != nil && strings.TrimSpace(m[4]) != "" {
+		return true
+	}
+	return false
+}
+
+// isHorizontalRule reports whether a line is a thematic break (---, ***, ___)
+func isHorizontalRule(line string) bool {
+	if indentOf(line) >= 4 {
+		return false
+	}
+	var c byte
+	count := 0
+	for i := 0; i < len(line); i++ {
+		switch {
+		case line[i] == ' ':
+		case count == 0 && (line[i] == '-' || line[i] == '*' || line[i] == '_'):
+			c, count = line[i], 1
+		case count > 0 && line[i] == c:
+			count++
+		default:
+			return false
+		}
+	}
+	return count >= 3
+}
+
+// setextLevel returns 1 or 2 for setext heading underlines (=== or ---), 0 otherwise
+func setextLevel(line string) int {
+	if indentOf(line) >= 4 {
+		return 0
+	}
+	trimmed := strings.TrimSpace(line)
+	switch {
+	case trimmed == "":
+		return 0
+	case strings.Trim(trimmed, "=") == "":
+		return 1
+	case strings.Trim(trimmed, "-") == "":
+		return 2
+	}
+	return 0
+}
+
+// fenceStart parses an opening code fence, returning the fence marker and info string
+func fenceStart(line string) (fence string, info string, ok bool) {
+	if indentOf(line) >= 4 {
+		return "", "", false
+	}
+	trimmed := strings.TrimLeft(line, " ")
+	for _, c := range []string{"`", "~"} {
+		n := len(trimmed) - len(strings.TrimLeft(trimmed, c))
+		if n >= 3 {
+			info = strings.TrimSpace(trimmed[n:])
+			if c == "`" && strings.Contains(info, "`") {
+				return "", "", false
+			}
+			return strings.Repeat(c, n), info, true
+		}
+	}
+	return "", "", false
+}
+
+// isFenceEnd reports whether a line closes a code block opened with fence
+func isFenceEnd(line, fence string) bool {
+	if indentOf(line) >= 4 {
+		return false
+	}
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, fence) && strings.Trim(trimmed, fence[:1]) == ""
+}
+
+// skipFence returns the index of the line closing the code fence opened at lines[start]
+func skipFence(lines []string, start int) int {
+	fence, _, _ := fenceStart(lines[start])
+	for i := start + 1; i < len(lines); i++ {
+		if isFenceEnd(lines[i], fence) {
+			return i
+		}
+	}
+	return len(lines)
+}
+
+// renderBlocks renders block-level Markdown. In tight mode (items of tight lists)
+// paragraphs are rendered without <p> tags.
+func (r *renderer) renderBlocks(lines []string, tight bool) {
+	r.deepest = max(r.deepest, r.depth)
+	if r.depth > maxNesting {
+		// Content nested too deeply for more containers is kept as a paragraph
+		r.writeParagraph(strings.TrimSpace(strings.Join(lines, "\n")), tight)
+		return
+	}
+	for i := 0; i < len(lines); {
+		line := lines[i]
+
+		switch {
+		case isBlank(line):
+			i++
+
+		case indentOf(line) >= 4:
+			i = r.renderIndentedCode(lines, i)
+
+		case atxHeadingRe.MatchString(line):
+			m := atxHeadingRe.FindStringSubmatch(line)
+			r.renderHeading(len(m[1]), m[2])
+			i++
+
+		case isHorizontalRule(line):
+			r.out.WriteString("<hr />\n")
+			i++
+
+		case strings.HasPrefix(strings.TrimLeft(line, " "), ">"):
+			i = r.renderBlockquote(lines, i)
+
+		default:
+			if _, _, ok := fenceStart(line); ok {
+				i = r.renderFencedCode(lines, i)
+			} else if matchListItem(line) != nil {
+				i = r.renderList(lines, i)
+			} else {
+				i = r.renderParagraph(lines, i, tight)
+			}
+		}
+	}
+}
+
+// renderHeading writes a heading with a unique anchor ID and records it in the TOC
+func (r *renderer) renderHeading(level int, source string) {
+	content := renderInline(strings.TrimSpace(source))
+	text := plainText(content)
+	id := r.uniqueID(text)
+
+	r.toc = append(r.toc, Heading{Level: level, Text: text, ID: id})
+	fmt.Fprintf(&r.out, "<h%d id=\"%s\">%s</h%d>\n", level, html.EscapeString(id), content, level)
+}
+
+// uniqueID derives an anchor ID from heading text, suffixing duplicates with -1, -2, ...
+func (r *renderer) uniqueID(text string) string {
+	base := anchorID(text)
+	if base == "" {
+		base = "section"
+	}
+	n := r.usedIDs[base]
+	r.usedIDs[base] = n + 1
+	if n == 0 {
+		return base
+	}
+	return fmt.Sprintf("%s-%d", base, n)
+}
+
+// renderFencedCode writes a fenced code block and returns the index after it
+func (r *renderer) renderFencedCode(lines []string, start int) int {
+	fence, info, _ := fenceStart(lines[start])
+	indent := indentOf(lines[start])
+
+	var code []string
+	i := start + 1
+	for ; i < len(lines); i++ {
+		if isFenceEnd(lines[i], fence) {
+			i++
+			break
+		}
+		// Remove up to the opening fence's indentation from content lines
+		line := lines[i]
+		line = line[min(indent, indentOf(line)):]
+		code = append(code, line)
+	}
+
+	lang := strings.Fields(info)
+	if len(lang) > 0 {
+		fmt.Fprintf(&r.out, "<pre><code class=\"language-%s\">", html.EscapeString(lang[0]))
+	} else {
+		r.out.WriteString("<pre><code>")
+	}
+	for _, line := range code {
+		r.out.WriteString(html.EscapeString(line))
+		r.out.WriteString("\n")
+	}
+	r.out.WriteString("</code></pre>\n")
+	return i
+}
+
+// renderIndentedCode writes an indented code block and returns the index after it
+func (r *renderer) renderIndentedCode(lines []string, start int) int {
+	var code []string
+	i := start
+	for ; i < len(lines); i++ {
+		if isBlank(lines[i]) {
+			code = append(code, "")
+			continue
+		}
+		if indentOf(lines[i]) < 4 {
+			break
+		}
+		code = append(code, lines[i][4:])
+	}
+	// Trailing blank lines belong to the surrounding document
+	for len(code) > 0 && code[len(code)-1] == "" {
+		code = code[:len(code)-1]
+	}
+
+	r.out.WriteString("<pre><code>")
+	for _, line := range code {
+		r.out.WriteString(html.EscapeString(line))
+		r.out.WriteString("\n")
+	}
+	r.out.WriteString("</code></pre>\n")
+	return i
+}
+
+// renderBlockquote writes a block quote and returns the index after it
+func (r *renderer) renderBlockquote(lines []string, start int) int {
+	var inner []string
+	i := start
+	for ; i < len(lines); i++ {
+		trimmed := strings.TrimLeft(lines[i], " ")
+		if strings.HasPrefix(trimmed, ">") {
+			content := strings.TrimPrefix(trimmed, ">")
+			content = strings.TrimPrefix(content, " ")
+			inner = append(inner, content)
+			continue
+		}
+		// Lazy continuation of a quoted paragraph
+		if !isBlank(lines[i]) && !isBlockStart(lines[i]) && len(inner) > 0 && !isBlank(inner[len(inner)-1]) {
+			inner = append(inner, lines[i])
+			continue
+		}
+		break
+	}
+
+	r.out.WriteString("<blockquote>\n")
+	r.depth++
+	r.renderBlocks(inner, false)
+	r.depth--
+	r.out.WriteString("</blockquote>\n")
+	return i
+}
+
+// listItem holds the dedented lines of one list item
+type listItem struct {
+	lines []string
+}
+
+// renderList writes an ordered or unordered list and returns the index after it
+func (r *renderer) renderList(lines []string, start int) int {
+	first := matchListItem(lines[start])
+	ordered := isOrderedMarker(first[2])
+
+	var items []listItem
+	tight := true
+	i := start
+	for i < len(lines) {
+		m := matchListItem(lines[i])
+		if m == nil || isHorizontalRule(lines[i]) {
+			break
+		}
+		if !sameListType(first[2], m[2]) {
+			break
+		}
+
+		// Content indentation is the marker width plus the following spaces; an item
+		// starting with an indented code block only counts one space
+		spaces := len(m[3])
+		firstLine := strings.TrimRight(m[4], " ")
+		if spaces == 0 {
+			spaces = 1
+		} else if spaces > 4 {
+			firstLine = strings.Repeat(" ", spaces-1) + firstLine
+			spaces = 1
+		}
+		contentIndent := len(m[1]) + len(m[2]) + spaces
+
+		item := listItem{lines: []string{firstLine}}
+		i++
+
+		for i < len(lines) {
+			line := lines[i]
+			if isBlank(line) {
+				// A blank line continues the item only if indented content follows
+				j := i
+				for j < len(lines) && isBlank(lines[j]) {
+					j++
+				}
+				if j < len(lines) && indentOf(lines[j]) >= contentIndent {
+					for ; i < j; i++ {
+						item.lines = append(item.lines, "")
+					}
+					tight = false
+					continue
+				}
+				break
+			}
+			if indentOf(line) >= contentIndent {
+				item.lines = append(item.lines, line[contentIndent:])
+				i++
+				continue
+			}
+			// Lazy paragraph continuation
+			if !isBlockStart(line) && matchListItem(line) == nil && len(item.lines) > 0 && !isBlank(item.lines[len(item.lines)-1]) {
+				item.lines = append(item.lines, strings.TrimLeft(line, " "))
+				i++
+				continue
+			}
+			break
+		}
+		items = append(items, item)
+
+		// Blank lines between items make the list loose
+		j := i
+		for j < len(lines) && isBlank(lines[j]) {
+			j++
+		}
+		if j > i && j < len(lines) && indentOf(lines[j]) < contentIndent {
+			if next := matchListItem(lines[j]); next != nil && sameListType(first[2], next[2]) {
+				tight = false
+				i = j
+			}
+		}
+	}
+
+	tag := "ul"
+	if ordered {
+		tag = "ol"
+		startNum := strings.TrimRight(first[2], ".)")
+		if startNum != "1" {
+			fmt.Fprintf(&r.out, "<ol start=\"%s\">\n", strings.TrimLeft(startNum, "0"))
+		} else {
+			r.out.WriteString("<ol>\n")
+		}
+	} else {
+		r.out.WriteString("<ul>\n")
+	}
+	for _, item := range items {
+		// Items are rendered separately so the trailing newline can be dropped,
+		// which renders tight items as <li>text</li>
+		sub := &renderer{toc: r.toc, usedIDs: r.usedIDs, depth: r.depth + 1}
+		sub.renderBlocks(item.lines, tight)
+		r.toc = sub.toc
+		r.deepest = max(r.deepest, sub.deepest)
+
+		r.out.WriteString("<li>")
+		r.out.WriteString(strings.TrimSuffix(sub.out.String(), "\n"))
+		r.out.WriteString("</li>\n")
+	}
+	fmt.Fprintf(&r.out, "</%s>\n", tag)
+	return i
+}
+
+// isOrderedMarker reports whether a list marker is numbered (1. or 1))
+func isOrderedMarker(marker string) bool {
+	return marker[0] >= '0' && marker[0] <= '9'
+}
+
+// sameListType reports whether two list markers belong to the same list:
+// both numbered with the same delimiter, or the same bullet character
+func sameListType(a, b string) bool {
+	if isOrderedMarker(a) != isOrderedMarker(b) {
+		return false
+	}
+	return a[len(a)-1] == b[len(b)-1]
+}
+
+// renderParagraph writes a paragraph (or setext heading) and returns the index after it
+func (r *renderer) renderParagraph(lines []string, start int, tight bool) int {
+	var para []string
+	i := start
+	for ; i < len(lines); i++ {
+		line := lines[i]
+		if isBlank(line) || (i > start && isBlockStart(line) && setextLevel(line) == 0) {
+			break
+		}
+		if i > start {
+			if level := setextLevel(line); level > 0 {
+				r.renderHeading(level, strings.Join(para, " "))
+				return i + 1
+			}
+		}
+		para = append(para, strings.TrimLeft(line, " "))
+	}
+
+	r.writeParagraph(strings.TrimRight(strings.Join(para, "\n"), " "), tight)
+	return i
+}
+
+// writeParagraph writes the inline content of a paragraph, without <p> tags in tight mode
+func (r *renderer) writeParagraph(source string, tight bool) {
+	if source == "" {
+		return
+	}
+	content := renderInline(source)
+	if tight {
+		r.out.WriteString(content)
+		r.out.WriteString("\n")
+	} else {
+		r.out.WriteString("<p>")
+		r.out.WriteString(content)
+		r.out.WriteString("</p>\n")
+	}
+}
+
+// plainText strips tags from rendered inline HTML and decodes entities
+func plainText(rendered string) string {
+	return strings.TrimSpace(html.UnescapeString(tagRe.ReplaceAllString(rendered, "")))
+}
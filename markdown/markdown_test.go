@@ -0,0 +1,64 @@
+package markdown
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestRenderBlocks(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+		want string
+	}{
+		{"bullet list", "- a\n- b", "<ul>\n<li>a</li>\n<li>b</li>\n</ul>\n"},
+		{"ordered list", "3. a\n4. b", "<ol start=\"3\">\n<li>a</li>\n<li>b</li>\n</ol>\n"},
+		{"nested list", "- a\n  - b", "<ul>\n<li>a\n<ul>\n<li>b</li>\n</ul></li>\n</ul>\n"},
+		{"empty item", "-", "<ul>\n<li></li>\n</ul>\n"},
+		{"no space after marker", "-a", "<p>-a</p>\n"},
+		{"too many digits", "1234567890. a", "<p>1234567890. a</p>\n"},
+		{"horizontal rules", "***\n- - -\n_ _ _", "<hr />\n<hr />\n<hr />\n"},
+		{"not a rule", "--x", "<p>--x</p>\n"},
+		{"quote", "> a", "<blockquote>\n<p>a</p>\n</blockquote>\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Render(tt.in).HTML; got != tt.want {
+				t.Errorf("Render(%q) = %q, want %q", tt.in, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	nested := func(depth int) string {
+		var b strings.Builder
+		for i := 0; i < depth; i++ {
+			b.WriteString(strings.Repeat("  ", i) + "- item\n")
+		}
+		return b.String()
+	}
+	tests := []struct {
+		name    string
+		in      string
+		wantErr error
+	}{
+		{"empty", "", nil},
+		{"post", "# Title\n\n- a\n  - b\n\n> quote", nil},
+		{"deepest allowed list", nested(MaxDepth), nil},
+		{"too deep list", nested(MaxDepth + 1), ErrTooDeep},
+		{"too deep one-line list", strings.Repeat("- ", MaxDepth+1) + "x", ErrTooDeep},
+		{"too deep quote", strings.Repeat(">", MaxDepth+1) + " x", ErrTooDeep},
+		{"deep indented code", "```\n" + strings.Repeat(" ", 200) + "x\n```", nil},
+		{"longest allowed", strings.Repeat("a", MaxLength), nil},
+		{"too long", strings.Repeat("a", MaxLength+1), ErrTooLong},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := Validate(tt.in); !errors.Is(err, tt.wantErr) {
+				t.Errorf("Validate() = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"net/http"
+	"strings"
+
+	"firebase.google.com/go/v4/auth"
+)
+
+// TokenVerifier verifies Firebase ID tokens.
+// *auth.Client implements it; tests can pass a fake verifier instead.
+type TokenVerifier interface {
+	VerifyIDToken(ctx context.Context, idToken string) (*auth.Token, error)
+}
+
+// User is the authenticated caller of a request
+type User struct {
+	UID    string
+	Claims map[string]interface{}
+}
+
+// contextKey is the type of keys stored on the request context by this package
+type contextKey int
+
+const userContextKey contextKey = iota
+
+// WithUser returns a copy of ctx carrying the authenticated user
+func WithUser(ctx context.Context, user *User) context.Context {
+	return context.WithValue(ctx, userContextKey, user)
+}
+
+// UserFromContext returns the authenticated user stored on ctx, if any
+func UserFromContext(ctx context.Context) (*User, bool) {
+	user, ok := ctx.Value(userContextKey).(*User)
+	return user, ok && user != nil
+}
+
+// Authenticate returns middleware that requires a valid "Authorization: Bearer <Firebase ID token>"
+// header and stores the verified user on the request context. Requests without a
+// valid token are rejected with 401.
+func Authenticate(verifier TokenVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			idToken, ok := bearerToken(r)
+			if !ok {
+				writeUnauthorized(w, "Missing bearer token")
+				return
+			}
+			serveVerified(verifier, idToken, next, w, r)
+		})
+	}
+}
+
+// OptionalAuthenticate returns middleware like Authenticate for endpoints that are
+// also open to anonymous callers: requests without an Authorization header continue
+// without a user on the context, while an invalid token is still rejected with 401.
+func OptionalAuthenticate(verifier TokenVerifier) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+			idToken, ok := bearerToken(r)
+			if !ok {
+				writeUnauthorized(w, "Missing bearer token")
+				return
+			}
+			serveVerified(verifier, idToken, next, w, r)
+		})
+	}
+}
+
+// serveVerified verifies idToken and calls next with the user on the request context
+func serveVerified(verifier TokenVerifier, idToken string, next http.Handler, w http.ResponseWriter, r *http.Request) {
+	token, err := verifier.VerifyIDToken(r.Context(), idToken)
+	if err != nil {
+		log.Printf("ID token verification failed: %v", err)
+		writeUnauthorized(w, "Invalid or expired token")
+		return
+	}
+
+	user := &User{
+		UID:    token.UID,
+		Claims: token.Claims,
+	}
+	next.ServeHTTP(w, r.WithContext(WithUser(r.Context(), user)))
+}
+
+// bearerToken extracts the token from an "Authorization: Bearer <token>" header
+func bearerToken(r *http.Request) (string, bool) {
+	header := r.Header.Get("Authorization")
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
+
+// writeUnauthorized writes a 401 response in the API's JSON envelope
+func writeUnauthorized(w http.ResponseWriter, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("WWW-Authenticate", `Bearer realm="api"`)
+	w.WriteHeader(http.StatusUnauthorized)
+	json.NewEncoder(w).Encode(map[string]interface{}{
+		"success": false,
+		"error":   message,
+	})
+}
@@ -0,0 +1,115 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"firebase.google.com/go/v4/auth"
+)
+
+// fakeVerifier accepts the tokens it holds and rejects any other token
+type fakeVerifier map[string]*auth.Token
+
+func (v fakeVerifier) VerifyIDToken(ctx context.Context, idToken string) (*auth.Token, error) {
+	if token, ok := v[idToken]; ok {
+		return token, nil
+	}
+	return nil, errors.New("invalid token")
+}
+
+var testVerifier = fakeVerifier{
+	"valid-token": {UID: "alice", Claims: map[string]interface{}{RoleClaim: "editor"}},
+}
+
+func TestAuthenticate(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantUID       string
+	}{
+		{"missing header", "", http.StatusUnauthorized, ""},
+		{"other scheme", "Basic dXNlcjpwYXNz", http.StatusUnauthorized, ""},
+		{"no token", "Bearer", http.StatusUnauthorized, ""},
+		{"blank token", "Bearer   ", http.StatusUnauthorized, ""},
+		{"rejected token", "Bearer expired-token", http.StatusUnauthorized, ""},
+		{"valid token", "Bearer valid-token", http.StatusOK, "alice"},
+		{"lowercase scheme", "bearer valid-token", http.StatusOK, "alice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, user := serve(Authenticate(testVerifier), tt.authorization)
+			checkResponse(t, status, user, tt.wantStatus, tt.wantUID)
+		})
+	}
+}
+
+func TestOptionalAuthenticate(t *testing.T) {
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantUID       string
+	}{
+		{"anonymous", "", http.StatusOK, ""},
+		{"other scheme", "Basic dXNlcjpwYXNz", http.StatusUnauthorized, ""},
+		{"blank token", "Bearer   ", http.StatusUnauthorized, ""},
+		{"rejected token", "Bearer expired-token", http.StatusUnauthorized, ""},
+		{"valid token", "Bearer valid-token", http.StatusOK, "alice"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, user := serve(OptionalAuthenticate(testVerifier), tt.authorization)
+			checkResponse(t, status, user, tt.wantStatus, tt.wantUID)
+		})
+	}
+}
+
+func TestAuthenticateClaims(t *testing.T) {
+	_, user := serve(Authenticate(testVerifier), "Bearer valid-token")
+	if user == nil {
+		t.Fatal("no user on the request context")
+	}
+	if !user.HasRole(RoleEditor) || user.HasRole(RoleAdmin) {
+		t.Errorf("got role %q, want %q", user.Role(), RoleEditor)
+	}
+}
+
+// serve sends a request with the given Authorization header through middleware and
+// returns the response status and the user the next handler saw, if it was called
+func serve(middleware func(http.Handler) http.Handler, authorization string) (int, *User) {
+	var user *User
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		user, _ = UserFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})
+
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if authorization != "" {
+		r.Header.Set("Authorization", authorization)
+	}
+	w := httptest.NewRecorder()
+	middleware(next).ServeHTTP(w, r)
+
+	return w.Code, user
+}
+
+// checkResponse compares the outcome of serve with the wanted status and UID ("" for
+// no user on the context)
+func checkResponse(t *testing.T, status int, user *User, wantStatus int, wantUID string) {
+	t.Helper()
+	if status != wantStatus {
+		t.Fatalf("got status %d, want %d", status, wantStatus)
+	}
+	switch {
+	case wantUID == "" && user != nil:
+		t.Errorf("got user %q, want none", user.UID)
+	case wantUID != "" && user == nil:
+		t.Errorf("got no user, want %q", wantUID)
+	case wantUID != "" && user.UID != wantUID:
+		t.Errorf("got user %q, want %q", user.UID, wantUID)
+	}
+}
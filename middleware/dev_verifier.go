@@ -0,0 +1,72 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+
+	"firebase.google.com/go/v4/auth"
+)
+
+// DevVerifier is an insecure TokenVerifier and RoleAssigner for local development
+// without Firebase. It accepts any non-empty token of the form "<uid>" or
+// "<uid>:<role>" and keeps assigned claims in memory. Never use it in production.
+type DevVerifier struct {
+	claims map[string]map[string]interface{}
+	mu     sync.RWMutex
+}
+
+// NewDevVerifier creates a new DevVerifier
+func NewDevVerifier() *DevVerifier {
+	return &DevVerifier{
+		claims: make(map[string]map[string]interface{}),
+	}
+}
+
+// VerifyIDToken treats the token itself as the UID, optionally followed by a role
+func (v *DevVerifier) VerifyIDToken(ctx context.Context, idToken string) (*auth.Token, error) {
+	uid, role, hasRole := strings.Cut(idToken, ":")
+	if uid == "" {
+		return nil, errors.New("empty token")
+	}
+
+	claims := map[string]interface{}{}
+	v.mu.RLock()
+	for k, val := range v.claims[uid] {
+		claims[k] = val
+	}
+	v.mu.RUnlock()
+	if hasRole {
+		claims[RoleClaim] = role
+	}
+
+	return &auth.Token{
+		UID:    uid,
+		Claims: claims,
+	}, nil
+}
+
+// GetUser returns uid with the claims assigned to it; every UID exists
+func (v *DevVerifier) GetUser(ctx context.Context, uid string) (*auth.UserRecord, error) {
+	v.mu.RLock()
+	defer v.mu.RUnlock()
+
+	claims := make(map[string]interface{}, len(v.claims[uid]))
+	for k, val := range v.claims[uid] {
+		claims[k] = val
+	}
+	return &auth.UserRecord{
+		UserInfo:     &auth.UserInfo{UID: uid},
+		CustomClaims: claims,
+	}, nil
+}
+
+// SetCustomUserClaims replaces the claims returned for uid by later VerifyIDToken calls
+func (v *DevVerifier) SetCustomUserClaims(ctx context.Context, uid string, customClaims map[string]interface{}) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.claims[uid] = customClaims
+	return nil
+}
@@ -0,0 +1,74 @@
+package middleware
+
+import (
+	"context"
+
+	"firebase.google.com/go/v4/auth"
+)
+
+// Role is a user role, stored in the "role" Firebase custom claim
+type Role string
+
+// Roles, from least to most privileged
+const (
+	RoleReader Role = "reader"
+	RoleAuthor Role = "author"
+	RoleEditor Role = "editor"
+	RoleAdmin  Role = "admin"
+)
+
+// RoleClaim is the custom claim that holds the user's role
+const RoleClaim = "role"
+
+// roleRanks orders roles by privilege
+var roleRanks = map[Role]int{
+	RoleReader: 0,
+	RoleAuthor: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+}
+
+// ParseRole returns the role with the given name
+func ParseRole(name string) (Role, bool) {
+	role := Role(name)
+	_, ok := roleRanks[role]
+	return role, ok
+}
+
+// Role returns the user's role from the token claims, defaulting to RoleReader
+func (u *User) Role() Role {
+	if name, ok := u.Claims[RoleClaim].(string); ok {
+		if role, ok := ParseRole(name); ok {
+			return role
+		}
+	}
+	return RoleReader
+}
+
+// HasRole reports whether the user has at least the given role
+func (u *User) HasRole(min Role) bool {
+	return roleRanks[u.Role()] >= roleRanks[min]
+}
+
+// RoleAssigner stores roles as custom user claims.
+// *auth.Client implements it through GetUser and SetCustomUserClaims.
+type RoleAssigner interface {
+	GetUser(ctx context.Context, uid string) (*auth.UserRecord, error)
+	SetCustomUserClaims(ctx context.Context, uid string, customClaims map[string]interface{}) error
+}
+
+// AssignRole sets the role claim of uid. SetCustomUserClaims replaces every custom
+// claim, so the user's other claims are read first and written back with the role.
+func AssignRole(ctx context.Context, roles RoleAssigner, uid string, role Role) error {
+	user, err := roles.GetUser(ctx, uid)
+	if err != nil {
+		return err
+	}
+
+	claims := make(map[string]interface{}, len(user.CustomClaims)+1)
+	for k, v := range user.CustomClaims {
+		claims[k] = v
+	}
+	claims[RoleClaim] = string(role)
+	return roles.SetCustomUserClaims(ctx, uid, claims)
+}
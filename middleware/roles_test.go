@@ -0,0 +1,26 @@
+package middleware
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestAssignRoleKeepsOtherClaims(t *testing.T) {
+	ctx := context.Background()
+	roles := NewDevVerifier()
+	roles.SetCustomUserClaims(ctx, "alice", map[string]interface{}{"plan": "pro", RoleClaim: "reader"})
+
+	if err := AssignRole(ctx, roles, "alice", RoleEditor); err != nil {
+		t.Fatal(err)
+	}
+
+	user, err := roles.GetUser(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]interface{}{"plan": "pro", RoleClaim: "editor"}
+	if !reflect.DeepEqual(user.CustomClaims, want) {
+		t.Errorf("got claims %v, want %v", user.CustomClaims, want)
+	}
+}
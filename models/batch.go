@@ -0,0 +1,56 @@
+package models
+
+import "encoding/json"
+
+// BatchOp is the kind of a batch operation
+type BatchOp string
+
+const (
+	BatchCreate BatchOp = "create"
+	BatchUpdate BatchOp = "update"
+	BatchDelete BatchOp = "delete"
+)
+
+// TodoBatchRequest represents the request body of a todo batch
+type TodoBatchRequest struct {
+	Atomic     bool                 `json:"atomic"` // Apply every operation or none of them
+	Operations []TodoBatchOperation `json:"operations"`
+}
+
+// TodoBatchOperation is one operation of a todo batch
+type TodoBatchOperation struct {
+	Op   BatchOp         `json:"op"`                                  // create, update or delete
+	ID   int             `json:"id,omitempty"`                        // Todo to update or delete
+	Todo json.RawMessage `json:"todo,omitempty" swaggertype:"object"` // CreateTodoRequest (create) or UpdateTodoRequest (update)
+}
+
+// BlogBatchRequest represents the request body of a blog batch
+type BlogBatchRequest struct {
+	Atomic     bool                 `json:"atomic"` // Apply every operation or none of them
+	Operations []BlogBatchOperation `json:"operations"`
+}
+
+// BlogBatchOperation is one operation of a blog batch
+type BlogBatchOperation struct {
+	Op   BatchOp         `json:"op"`                                  // create, update or delete
+	ID   int             `json:"id,omitempty"`                        // Blog to update or delete
+	Blog json.RawMessage `json:"blog,omitempty" swaggertype:"object"` // CreateBlogRequest (create) or UpdateBlogRequest (update)
+}
+
+// BatchResult is the outcome of one operation of a batch
+type BatchResult struct {
+	Index  int         `json:"index"`           // Position of the operation in the request
+	Op     BatchOp     `json:"op"`              // create, update or delete
+	ID     int         `json:"id,omitempty"`    // ID of the created, updated or deleted item
+	Status int         `json:"status"`          // HTTP status the operation would get as a single request
+	Data   interface{} `json:"data,omitempty"`  // Created or updated item
+	Error  string      `json:"error,omitempty"` // Why the operation failed or was not applied
+}
+
+// BatchResponse is the result of a batch
+type BatchResponse struct {
+	Atomic    bool          `json:"atomic"`
+	Succeeded int           `json:"succeeded"` // Number of applied operations
+	Failed    int           `json:"failed"`    // Number of operations that failed or were not applied
+	Results   []BatchResult `json:"results"`   // One result per operation, in request order
+}
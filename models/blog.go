@@ -4,25 +4,56 @@ import "time"
 
 // Blog represents a blog post
 type Blog struct {
-	ID          int       `json:"id"`
-	Title       string    `json:"title"`
-	Content     string    `json:"content"`     // Markdown content
-	Slug        string    `json:"slug"`        // URL-friendly identifier
-	Author      string    `json:"author"`
-	Published   bool      `json:"published"`
-	Tags        []string  `json:"tags"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID            int        `json:"id"`
+	Title         string     `json:"title"`
+	Content       string     `json:"content"` // Markdown content
+	Slug          string     `json:"slug"`    // URL-friendly identifier, unique across blogs
+	Author        string     `json:"author"`
+	AuthorID      string     `json:"author_id"` // UID of the user who owns the post
+	Published     bool       `json:"published"`
+	PublishAt     *time.Time `json:"publish_at,omitempty"` // Scheduled publishing time, only set while the post is unpublished
+	Tags          []string   `json:"tags"`
+	PreviousSlugs []string   `json:"previous_slugs,omitempty"` // Former slugs, still reserved and redirecting to Slug
+	Revision      int        `json:"revision"`                 // Number of the latest revision
+	UpdatedBy     string     `json:"updated_by,omitempty"`     // UID of the user who made the latest change
+	CreatedAt     time.Time  `json:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+}
+
+// PublicBlog is the public view of a published blog, without authoring metadata
+type PublicBlog struct {
+	ID        int       `json:"id"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"` // Markdown content
+	Slug      string    `json:"slug"`
+	Author    string    `json:"author"`
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// BlogSummary is the public view of a published blog in lists, with an excerpt
+// instead of the full content
+type BlogSummary struct {
+	ID        int       `json:"id"`
+	Title     string    `json:"title"`
+	Slug      string    `json:"slug"`
+	Author    string    `json:"author"`
+	Excerpt   string    `json:"excerpt"` // First paragraph of the content, as plain text
+	Tags      []string  `json:"tags"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
 }
 
 // CreateBlogRequest represents the request body for creating a blog
 type CreateBlogRequest struct {
-	Title     string   `json:"title"`
-	Content   string   `json:"content"`
-	Slug      string   `json:"slug"`
-	Author    string   `json:"author"`
-	Published bool     `json:"published"`
-	Tags      []string `json:"tags"`
+	Title     string     `json:"title"`
+	Content   string     `json:"content"`
+	Slug      string     `json:"slug"`
+	Author    string     `json:"author"`
+	Published bool       `json:"published"`
+	PublishAt *time.Time `json:"publish_at"` // Publish automatically at this time (RFC3339)
+	Tags      []string   `json:"tags"`
 }
 
 // UpdateBlogRequest represents the request body for updating a blog
@@ -32,6 +63,54 @@ type UpdateBlogRequest struct {
 	Slug      *string   `json:"slug"`
 	Author    *string   `json:"author"`
 	Published *bool     `json:"published"`
+	PublishAt *string   `json:"publish_at"` // RFC3339 time to publish automatically, or "" to cancel the schedule
 	Tags      *[]string `json:"tags"`
 }
 
+// TOCEntry is one heading of a rendered blog's table of contents
+type TOCEntry struct {
+	Level int    `json:"level"`
+	Text  string `json:"text"`
+	ID    string `json:"id"` // Anchor ID of the heading in the rendered HTML
+}
+
+// RenderedBlog represents a blog whose Markdown content was rendered to sanitized HTML
+type RenderedBlog struct {
+	ID        int        `json:"id"`
+	Title     string     `json:"title"`
+	Slug      string     `json:"slug"`
+	HTML      string     `json:"html"`
+	TOC       []TOCEntry `json:"toc"`
+	TOCHTML   string     `json:"toc_html"` // Table of contents as nested <ul> lists
+	UpdatedAt time.Time  `json:"updated_at"`
+}
+
+// BlogRevision is an immutable snapshot of a blog, saved on every create, update, restore and tag merge
+type BlogRevision struct {
+	BlogID    int       `json:"blog_id"`
+	Revision  int       `json:"revision"`
+	Title     string    `json:"title"`
+	Content   string    `json:"content"`
+	Slug      string    `json:"slug"`
+	Author    string    `json:"author"`
+	Published bool      `json:"published"`
+	Tags      []string  `json:"tags"`
+	EditorID  string    `json:"editor_id,omitempty"` // UID of the user who saved the revision, when known
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// DiffLine is one line of a line-level diff
+type DiffLine struct {
+	Op   string `json:"op"` // equal, insert or delete
+	Text string `json:"text"`
+}
+
+// BlogRevisionDiff is the line-level diff of the content of two blog revisions
+type BlogRevisionDiff struct {
+	BlogID  int        `json:"blog_id"`
+	From    int        `json:"from"`
+	To      int        `json:"to"`
+	Added   int        `json:"added"`
+	Removed int        `json:"removed"`
+	Lines   []DiffLine `json:"lines"`
+}
@@ -0,0 +1,47 @@
+package models
+
+import "time"
+
+// CommentStatus is the moderation state of a comment
+type CommentStatus string
+
+const (
+	CommentPending  CommentStatus = "pending"  // Waiting for moderation, not shown publicly
+	CommentApproved CommentStatus = "approved" // Shown under the blog
+	CommentSpam     CommentStatus = "spam"     // Rejected by a moderator
+)
+
+// Comment represents a comment on a blog post. Replies point to a top-level
+// comment through ParentID; replies to replies are not allowed.
+type Comment struct {
+	ID         int           `json:"id"`
+	BlogID     int           `json:"blog_id"`
+	ParentID   int           `json:"parent_id,omitempty"` // ID of the comment replied to, 0 for top-level comments
+	AuthorID   string        `json:"author_id,omitempty"` // UID of the commenter, empty for anonymous comments
+	AuthorName string        `json:"author_name"`
+	Content    string        `json:"content"` // Plain text
+	Status     CommentStatus `json:"status"`
+	CreatedAt  time.Time     `json:"created_at"`
+	UpdatedAt  time.Time     `json:"updated_at"`
+}
+
+// PublicComment is the public view of an approved comment
+type PublicComment struct {
+	ID         int       `json:"id"`
+	AuthorName string    `json:"author_name"`
+	Content    string    `json:"content"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// CommentThread is an approved top-level comment with its approved replies, oldest first
+type CommentThread struct {
+	PublicComment
+	Replies []PublicComment `json:"replies"`
+}
+
+// CreateCommentRequest represents the request body for creating a comment
+type CreateCommentRequest struct {
+	ParentID   int    `json:"parent_id,omitempty"`   // Top-level comment to reply to
+	AuthorName string `json:"author_name,omitempty"` // Required for anonymous comments
+	Content    string `json:"content"`
+}
@@ -0,0 +1,29 @@
+package models
+
+import "time"
+
+// TodoList is a named group of todos, such as a project or a sprint
+type TodoList struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	OwnerID   string    `json:"owner_id"` // UID of the user who owns the list
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateTodoListRequest represents the request body for creating a todo list
+type CreateTodoListRequest struct {
+	Name string `json:"name"`
+}
+
+// UpdateTodoListRequest represents the request body for renaming a todo list
+type UpdateTodoListRequest struct {
+	Name string `json:"name"`
+}
+
+// DeleteTodoListResult reports what happened to the todos of a deleted list
+type DeleteTodoListResult struct {
+	Deleted int `json:"deleted"`            // Todos deleted with the list (cascade=true)
+	Moved   int `json:"moved"`              // Todos moved to move_to, or out of any list
+	MovedTo int `json:"moved_to,omitempty"` // List the todos were moved to, absent for no list
+}
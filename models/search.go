@@ -0,0 +1,12 @@
+package models
+
+// SearchResult represents a blog or todo matching a search query
+type SearchResult struct {
+	Type      string  `json:"type"` // "blog" or "todo"
+	ID        int     `json:"id"`
+	Title     string  `json:"title"`
+	Slug      string  `json:"slug,omitempty"`
+	Score     float64 `json:"score"`
+	TitleHTML string  `json:"title_html"` // HTML-escaped title with matches wrapped in <mark>
+	Snippet   string  `json:"snippet"`    // HTML-escaped excerpt with matches wrapped in <mark>
+}
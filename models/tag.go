@@ -0,0 +1,19 @@
+package models
+
+// TagCount is a tag with the number of published blogs using it
+type TagCount struct {
+	Tag   string `json:"tag"`
+	Count int    `json:"count"`
+}
+
+// MergeTagsRequest represents the request body for merging tags
+type MergeTagsRequest struct {
+	From []string `json:"from"` // Tags to replace, as stored on blogs
+	To   string   `json:"to"`   // Replacement tag, normalized before use
+}
+
+// MergeTagsResult reports the outcome of a tag merge
+type MergeTagsResult struct {
+	Tag     string `json:"tag"`
+	Updated int    `json:"updated"` // Number of blogs rewritten
+}
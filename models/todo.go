@@ -1,27 +1,161 @@
 package models
 
-import "time"
+import (
+	"apigo1/rrule"
+	"time"
+)
+
+// TodoPriority is the priority of a todo
+type TodoPriority string
+
+const (
+	PriorityLow    TodoPriority = "low"
+	PriorityMedium TodoPriority = "medium" // Default priority
+	PriorityHigh   TodoPriority = "high"
+	PriorityUrgent TodoPriority = "urgent"
+)
+
+// priorityRanks orders priorities from low (1) to urgent (4)
+var priorityRanks = map[TodoPriority]int{
+	PriorityLow:    1,
+	PriorityMedium: 2,
+	PriorityHigh:   3,
+	PriorityUrgent: 4,
+}
+
+// ParseTodoPriority returns the priority named s, or PriorityMedium when s is empty
+func ParseTodoPriority(s string) (TodoPriority, bool) {
+	if s == "" {
+		return PriorityMedium, true
+	}
+	p := TodoPriority(s)
+	_, ok := priorityRanks[p]
+	return p, ok
+}
+
+// Rank returns the sort rank of the priority, higher is more urgent; 0 for an unknown priority
+func (p TodoPriority) Rank() int {
+	return priorityRanks[p]
+}
 
 // Todo represents a todo item
 type Todo struct {
-	ID          int       `json:"id"`
-	Title       string    `json:"title"`
-	Description string    `json:"description"`
-	Completed   bool      `json:"completed"`
-	CreatedAt   time.Time `json:"created_at"`
-	UpdatedAt   time.Time `json:"updated_at"`
+	ID           int          `json:"id"`
+	Title        string       `json:"title"`
+	Description  string       `json:"description"`
+	Completed    bool         `json:"completed"`
+	Priority     TodoPriority `json:"priority"`               // low, medium, high or urgent
+	PriorityRank int          `json:"-"`                      // Rank of Priority, stored so lists can sort by priority
+	DueAt        *time.Time   `json:"due_at,omitempty"`       // Deadline, if any
+	CompletedAt  *time.Time   `json:"completed_at,omitempty"` // When the todo was completed, only set while it is completed
+	AutoComplete bool         `json:"auto_complete"`          // Complete the todo once all its checklist items are done
+	ItemCount    int          `json:"item_count"`             // Number of checklist items
+	ItemsDone    int          `json:"items_done"`             // Number of done checklist items
+	Progress     int          `json:"progress"`               // Percentage of done checklist items, 0 without items
+	Recurrence   string       `json:"recurrence,omitempty"`   // RFC 5545 RRULE subset, e.g. FREQ=WEEKLY;BYDAY=MO
+	Occurrence   int          `json:"occurrence,omitempty"`   // Index of this todo in its recurring series, from 1
+	NextTodoID   int          `json:"next_todo_id,omitempty"` // Todo created for the next occurrence when this one was completed
+	ListID       int          `json:"list_id,omitempty"`      // List the todo belongs to, 0 for none
+	Position     string       `json:"position"`               // Fractional index key ordering the todos of the owner
+	OwnerID      string       `json:"owner_id"`               // UID of the user who owns the todo
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
 }
 
 // CreateTodoRequest represents the request body for creating a todo
 type CreateTodoRequest struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	Priority     string     `json:"priority,omitempty"`      // low, medium (default), high or urgent
+	DueAt        *time.Time `json:"due_at,omitempty"`        // RFC3339 deadline
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`  // Creates the todo as completed at this time, e.g. when importing
+	AutoComplete bool       `json:"auto_complete,omitempty"` // Complete the todo once all its checklist items are done
+	Recurrence   string     `json:"recurrence,omitempty"`    // RRULE (FREQ=DAILY/WEEKLY/MONTHLY, INTERVAL, BYDAY, COUNT, UNTIL), requires due_at
+	ListID       int        `json:"list_id,omitempty"`       // List to add the todo to
 }
 
 // UpdateTodoRequest represents the request body for updating a todo
 type UpdateTodoRequest struct {
-	Title       string `json:"title"`
-	Description string `json:"description"`
-	Completed   *bool  `json:"completed"`
+	Title        string     `json:"title"`
+	Description  string     `json:"description"`
+	Completed    *bool      `json:"completed"`
+	Priority     *string    `json:"priority,omitempty"`      // low, medium, high or urgent
+	DueAt        *string    `json:"due_at,omitempty"`        // RFC3339 deadline, empty string removes it
+	CompletedAt  *time.Time `json:"completed_at,omitempty"`  // Completion time, only for completed todos; defaults to the time of completion
+	AutoComplete *bool      `json:"auto_complete,omitempty"` // Complete the todo once all its checklist items are done
+	Recurrence   *string    `json:"recurrence,omitempty"`    // RRULE restarting the series from this todo, empty string stops the recurrence
+	ListID       *int       `json:"list_id,omitempty"`       // Moves the todo to this list, 0 takes it out of its list
+}
+
+// NextOccurrences returns the due dates of up to n occurrences following this one
+// in its recurring series. The series continues from the due date of this todo,
+// so moving the due date of an occurrence moves the following ones too.
+func (t *Todo) NextOccurrences(n int) []time.Time {
+	if t.Recurrence == "" || t.DueAt == nil || n < 1 {
+		return nil
+	}
+	rule, err := rrule.Parse(t.Recurrence)
+	if err != nil {
+		return nil
+	}
+	if rule.Count > 0 {
+		// This occurrence is the first of the rest of the series
+		rule.Count -= t.Occurrence - 1
+		if rule.Count < 1 {
+			return nil
+		}
+	}
+
+	occurrences := rule.Occurrences(*t.DueAt, n+1)
+	if len(occurrences) < 2 {
+		return nil
+	}
+	return occurrences[1:]
+}
+
+// MoveTodoRequest represents the request body for moving a todo. Without anchors
+// the todo moves to the end.
+type MoveTodoRequest struct {
+	Before int `json:"before,omitempty"` // ID of the todo to move in front of
+	After  int `json:"after,omitempty"`  // ID of the todo to move behind
+}
+
+// TodoOccurrence is an upcoming occurrence of a recurring todo
+type TodoOccurrence struct {
+	Occurrence int       `json:"occurrence"` // Index in the series, from 1
+	DueAt      time.Time `json:"due_at"`
+}
+
+// ChecklistItem is one step of a todo
+type ChecklistItem struct {
+	ID        int       `json:"id"`
+	TodoID    int       `json:"todo_id"`
+	Title     string    `json:"title"`
+	Done      bool      `json:"done"`
+	Position  int       `json:"position"` // Order of the item in the checklist, from 0
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// ChecklistItemChange is the result of a checklist change: the item (absent after
+// a delete) and its todo with updated progress
+type ChecklistItemChange struct {
+	Item *ChecklistItem `json:"item,omitempty"`
+	Todo *Todo          `json:"todo"`
+}
+
+// CreateChecklistItemRequest represents the request body for adding a checklist item
+type CreateChecklistItemRequest struct {
+	Title string `json:"title"`
 }
 
+// UpdateChecklistItemRequest represents the request body for updating a checklist item
+type UpdateChecklistItemRequest struct {
+	Title string `json:"title"`
+	Done  *bool  `json:"done"`
+}
+
+// ReorderChecklistItemsRequest represents the request body for reordering a checklist
+type ReorderChecklistItemsRequest struct {
+	ItemIDs []int `json:"item_ids"` // Every item ID of the todo, in the new order
+}
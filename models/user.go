@@ -0,0 +1,6 @@
+package models
+
+// SetRoleRequest represents the request body for assigning a role to a user
+type SetRoleRequest struct {
+	Role string `json:"role"` // reader, author, editor or admin
+}
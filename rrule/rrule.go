@@ -0,0 +1,320 @@
+// Package rrule parses and expands the subset of RFC 5545 recurrence rules used by
+// recurring todos: FREQ=DAILY, WEEKLY or MONTHLY with INTERVAL, BYDAY, COUNT and
+// UNTIL, e.g. "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH" or "FREQ=MONTHLY;BYDAY=-1FR".
+// Weeks start on Monday.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalid is returned for rules that cannot be parsed or use unsupported parts
+var ErrInvalid = errors.New("invalid recurrence rule")
+
+// maxPeriods bounds the expansion of rules whose BYDAY never matches, such as
+// FREQ=DAILY;INTERVAL=7;BYDAY=TU starting on a Monday
+const maxPeriods = 10000
+
+// Frequency is the FREQ of a rule
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+)
+
+// Day is a BYDAY entry. N is only used by monthly rules: 1 is the first such
+// weekday of the month, -1 the last, and 0 every one.
+type Day struct {
+	N       int
+	Weekday time.Weekday
+}
+
+// Rule is a parsed recurrence rule
+type Rule struct {
+	Freq     Frequency
+	Interval int   // Number of periods between occurrences, at least 1
+	ByDay    []Day // Weekdays the occurrences fall on, if restricted
+	Count    int   // Number of occurrences of the series, 0 when unlimited
+	// Until is the time of the last possible occurrence, zero when unlimited.
+	// A date-only UNTIL includes the whole day (UTC).
+	Until time.Time
+}
+
+var weekdays = map[string]time.Weekday{
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+	"SU": time.Sunday,
+}
+
+var weekdayNames = [...]string{"SU", "MO", "TU", "WE", "TH", "FR", "SA"}
+
+// Parse parses a rule such as "FREQ=WEEKLY;BYDAY=MO,WE;COUNT=10". An "RRULE:"
+// prefix is accepted, and names are case-insensitive.
+func Parse(s string) (*Rule, error) {
+	s = strings.ToUpper(strings.TrimSpace(s))
+	s = strings.TrimPrefix(s, "RRULE:")
+	if s == "" {
+		return nil, fmt.Errorf("%w: empty rule", ErrInvalid)
+	}
+
+	rule := &Rule{Interval: 1}
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		name, value, ok := strings.Cut(part, "=")
+		if !ok || value == "" {
+			return nil, fmt.Errorf("%w: malformed part %q", ErrInvalid, part)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%w: %s is given more than once", ErrInvalid, name)
+		}
+		seen[name] = true
+
+		var err error
+		switch name {
+		case "FREQ":
+			rule.Freq = Frequency(value)
+			if rule.Freq != Daily && rule.Freq != Weekly && rule.Freq != Monthly {
+				err = fmt.Errorf("%w: FREQ must be DAILY, WEEKLY or MONTHLY", ErrInvalid)
+			}
+		case "INTERVAL":
+			rule.Interval, err = parsePositive(name, value)
+		case "COUNT":
+			rule.Count, err = parsePositive(name, value)
+		case "UNTIL":
+			rule.Until, err = parseUntil(value)
+		case "BYDAY":
+			rule.ByDay, err = parseDays(value)
+		default:
+			err = fmt.Errorf("%w: %s is not supported", ErrInvalid, name)
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if rule.Freq == "" {
+		return nil, fmt.Errorf("%w: FREQ is required", ErrInvalid)
+	}
+	if rule.Count > 0 && !rule.Until.IsZero() {
+		return nil, fmt.Errorf("%w: COUNT and UNTIL cannot be combined", ErrInvalid)
+	}
+	if rule.Freq != Monthly {
+		for _, day := range rule.ByDay {
+			if day.N != 0 {
+				return nil, fmt.Errorf("%w: BYDAY ordinals are only supported with FREQ=MONTHLY", ErrInvalid)
+			}
+		}
+	}
+	return rule, nil
+}
+
+func parsePositive(name, value string) (int, error) {
+	n, err := strconv.Atoi(value)
+	if err != nil || n < 1 {
+		return 0, fmt.Errorf("%w: %s must be a positive integer", ErrInvalid, name)
+	}
+	return n, nil
+}
+
+// parseUntil accepts the UTC date-time ("20250131T090000Z"), floating date-time
+// (read as UTC) and date forms of UNTIL
+func parseUntil(value string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	if t, err := time.Parse("20060102", value); err == nil {
+		return t.Add(24*time.Hour - time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("%w: UNTIL must be a date (20060102) or UTC date-time (20060102T150405Z)", ErrInvalid)
+}
+
+// parseDays parses a BYDAY list such as "MO,WE" or "1MO,-1FR"
+func parseDays(value string) ([]Day, error) {
+	var days []Day
+	for _, entry := range strings.Split(value, ",") {
+		if len(entry) < 2 {
+			return nil, fmt.Errorf("%w: invalid BYDAY entry %q", ErrInvalid, entry)
+		}
+		weekday, ok := weekdays[entry[len(entry)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("%w: invalid BYDAY entry %q", ErrInvalid, entry)
+		}
+		day := Day{Weekday: weekday}
+		if ordinal := entry[:len(entry)-2]; ordinal != "" {
+			n, err := strconv.Atoi(ordinal)
+			if err != nil || n == 0 || n < -5 || n > 5 {
+				return nil, fmt.Errorf("%w: invalid BYDAY entry %q", ErrInvalid, entry)
+			}
+			day.N = n
+		}
+		days = append(days, day)
+	}
+	return days, nil
+}
+
+// String returns the rule in its canonical form, as accepted by Parse
+func (r *Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, len(r.ByDay))
+		for i, day := range r.ByDay {
+			days[i] = weekdayNames[day.Weekday]
+			if day.N != 0 {
+				days[i] = strconv.Itoa(day.N) + days[i]
+			}
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if !r.Until.IsZero() {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+	return strings.Join(parts, ";")
+}
+
+// Occurrences returns up to n occurrences of the series starting at start (the
+// DTSTART of RFC 5545), in order. Like DTSTART, start is always the first
+// occurrence, even when it does not match BYDAY. Later occurrences keep the
+// clock time and location of start.
+func (r *Rule) Occurrences(start time.Time, n int) []time.Time {
+	occurrences := []time.Time{}
+	// add appends t and reports whether more occurrences are wanted
+	add := func(t time.Time) bool {
+		if !r.Until.IsZero() && t.After(r.Until) {
+			return false
+		}
+		occurrences = append(occurrences, t)
+		return len(occurrences) < n && (r.Count == 0 || len(occurrences) < r.Count)
+	}
+
+	if n < 1 || !add(start) {
+		return occurrences
+	}
+	for period := 0; period < maxPeriods; period++ {
+		for _, t := range r.candidates(start, period*r.interval()) {
+			if t.After(start) && !add(t) {
+				return occurrences
+			}
+		}
+	}
+	return occurrences
+}
+
+func (r *Rule) interval() int {
+	if r.Interval < 1 {
+		return 1
+	}
+	return r.Interval
+}
+
+// candidates returns the possible occurrences, in order, of the period that is
+// offset days, weeks or months after the period of start
+func (r *Rule) candidates(start time.Time, offset int) []time.Time {
+	switch r.Freq {
+	case Daily:
+		t := start.AddDate(0, 0, offset)
+		if len(r.ByDay) > 0 && !r.onWeekday(t.Weekday()) {
+			return nil
+		}
+		return []time.Time{t}
+
+	case Weekly:
+		monday := start.AddDate(0, 0, 7*offset-daysSinceMonday(start.Weekday()))
+		days := []time.Weekday{start.Weekday()}
+		if len(r.ByDay) > 0 {
+			days = days[:0]
+			for _, day := range r.ByDay {
+				days = append(days, day.Weekday)
+			}
+		}
+		var times []time.Time
+		for _, day := range days {
+			times = append(times, monday.AddDate(0, 0, daysSinceMonday(day)))
+		}
+		return sortedUnique(times)
+
+	case Monthly:
+		year, month := start.Year(), start.Month()+time.Month(offset)
+		// time.Date normalizes the month, so first is the first day of the period
+		first := at(start, year, month, 1)
+		length := daysIn(first.Year(), first.Month())
+		if len(r.ByDay) == 0 {
+			// Months without the day of start, like the 31st, are skipped
+			if start.Day() > length {
+				return nil
+			}
+			return []time.Time{at(start, first.Year(), first.Month(), start.Day())}
+		}
+
+		var times []time.Time
+		for _, day := range r.ByDay {
+			var matching []time.Time
+			for d := 1; d <= length; d++ {
+				if t := at(start, first.Year(), first.Month(), d); t.Weekday() == day.Weekday {
+					matching = append(matching, t)
+				}
+			}
+			switch {
+			case day.N == 0:
+				times = append(times, matching...)
+			case day.N > 0 && day.N <= len(matching):
+				times = append(times, matching[day.N-1])
+			case day.N < 0 && -day.N <= len(matching):
+				times = append(times, matching[len(matching)+day.N])
+			}
+		}
+		return sortedUnique(times)
+	}
+	return nil
+}
+
+func (r *Rule) onWeekday(weekday time.Weekday) bool {
+	for _, day := range r.ByDay {
+		if day.Weekday == weekday {
+			return true
+		}
+	}
+	return false
+}
+
+// at returns the given day at the clock time and in the location of start
+func at(start time.Time, year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, start.Hour(), start.Minute(), start.Second(), start.Nanosecond(), start.Location())
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
+
+func daysSinceMonday(weekday time.Weekday) int {
+	return (int(weekday) + 6) % 7
+}
+
+func sortedUnique(times []time.Time) []time.Time {
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+	unique := times[:0]
+	for i, t := range times {
+		if i == 0 || !t.Equal(times[i-1]) {
+			unique = append(unique, t)
+		}
+	}
+	return unique
+}
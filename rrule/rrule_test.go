@@ -0,0 +1,156 @@
+package rrule
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Rule
+	}{
+		{"FREQ=DAILY", Rule{Freq: Daily, Interval: 1}},
+		{"RRULE:freq=weekly;interval=2", Rule{Freq: Weekly, Interval: 2}},
+		{"FREQ=WEEKLY;BYDAY=MO,TH;COUNT=10", Rule{
+			Freq: Weekly, Interval: 1, Count: 10,
+			ByDay: []Day{{Weekday: time.Monday}, {Weekday: time.Thursday}},
+		}},
+		{"FREQ=MONTHLY;BYDAY=1MO,-1FR", Rule{
+			Freq: Monthly, Interval: 1,
+			ByDay: []Day{{N: 1, Weekday: time.Monday}, {N: -1, Weekday: time.Friday}},
+		}},
+		{"FREQ=DAILY;UNTIL=20250131T090000Z", Rule{
+			Freq: Daily, Interval: 1, Until: time.Date(2025, 1, 31, 9, 0, 0, 0, time.UTC),
+		}},
+		{"FREQ=DAILY;UNTIL=20250131", Rule{
+			Freq: Daily, Interval: 1, Until: time.Date(2025, 1, 31, 23, 59, 59, 0, time.UTC),
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			got, err := Parse(tt.in)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Parse(%q) = %+v, want %+v", tt.in, *got, tt.want)
+			}
+			// The canonical form parses to the same rule
+			again, err := Parse(got.String())
+			if err != nil || !reflect.DeepEqual(again, got) {
+				t.Errorf("Parse(%q) = %+v, %v, want %+v", got.String(), again, err, got)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"empty", ""},
+		{"missing FREQ", "INTERVAL=2"},
+		{"unsupported FREQ", "FREQ=YEARLY"},
+		{"unsupported part", "FREQ=MONTHLY;BYMONTHDAY=15"},
+		{"malformed part", "FREQ=DAILY;COUNT"},
+		{"repeated part", "FREQ=DAILY;FREQ=WEEKLY"},
+		{"zero INTERVAL", "FREQ=DAILY;INTERVAL=0"},
+		{"negative COUNT", "FREQ=DAILY;COUNT=-1"},
+		{"COUNT and UNTIL", "FREQ=DAILY;COUNT=2;UNTIL=20250101"},
+		{"malformed UNTIL", "FREQ=DAILY;UNTIL=2025-01-01"},
+		{"unknown weekday", "FREQ=WEEKLY;BYDAY=XX"},
+		{"ordinal out of range", "FREQ=MONTHLY;BYDAY=6MO"},
+		{"weekly ordinal", "FREQ=WEEKLY;BYDAY=1MO"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if rule, err := Parse(tt.in); !errors.Is(err, ErrInvalid) {
+				t.Errorf("Parse(%q) = %+v, %v, want ErrInvalid", tt.in, rule, err)
+			}
+		})
+	}
+}
+
+func TestOccurrences(t *testing.T) {
+	utc := func(month time.Month, day int) time.Time {
+		return time.Date(2025, month, day, 9, 0, 0, 0, time.UTC)
+	}
+	tests := []struct {
+		name  string
+		rule  string
+		start time.Time
+		n     int
+		want  []time.Time
+	}{
+		{"daily", "FREQ=DAILY;INTERVAL=2", utc(1, 30), 3, []time.Time{utc(1, 30), utc(2, 1), utc(2, 3)}},
+		{"weekly by day", "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,TH", utc(1, 6), 4, []time.Time{utc(1, 6), utc(1, 9), utc(1, 20), utc(1, 23)}},
+		{"start off BYDAY", "FREQ=WEEKLY;BYDAY=FR", utc(1, 6), 3, []time.Time{utc(1, 6), utc(1, 10), utc(1, 17)}},
+		{"month end skips short months", "FREQ=MONTHLY", utc(1, 31), 4, []time.Time{utc(1, 31), utc(3, 31), utc(5, 31), utc(7, 31)}},
+		{"last friday", "FREQ=MONTHLY;BYDAY=-1FR", utc(1, 31), 4, []time.Time{utc(1, 31), utc(2, 28), utc(3, 28), utc(4, 25)}},
+		{"COUNT stops the series", "FREQ=DAILY;COUNT=3", utc(1, 1), 10, []time.Time{utc(1, 1), utc(1, 2), utc(1, 3)}},
+		{"COUNT of one", "FREQ=DAILY;COUNT=1", utc(1, 1), 10, []time.Time{utc(1, 1)}},
+		{"date UNTIL includes the day", "FREQ=DAILY;UNTIL=20250103", utc(1, 1), 10, []time.Time{utc(1, 1), utc(1, 2), utc(1, 3)}},
+		{"UNTIL before start", "FREQ=DAILY;UNTIL=20241231", utc(1, 1), 10, []time.Time{}},
+		{"BYDAY never matching", "FREQ=DAILY;INTERVAL=7;BYDAY=TU", utc(1, 6), 3, []time.Time{utc(1, 6)}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := rule.Occurrences(tt.start, tt.n); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Occurrences(%v, %d) = %v, want %v", tt.start, tt.n, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestOccurrencesKeepClockTimeAcrossDST(t *testing.T) {
+	tests := []struct {
+		location string
+		rule     string
+		start    time.Time
+	}{
+		// Clocks move forward on 2025-03-30 and back on 2025-10-26 in Berlin,
+		// and forward on 2025-03-09 in New York
+		{"Europe/Berlin", "FREQ=DAILY", time.Date(2025, 3, 28, 9, 0, 0, 0, time.UTC)},
+		{"Europe/Berlin", "FREQ=DAILY", time.Date(2025, 10, 24, 9, 0, 0, 0, time.UTC)},
+		{"America/New_York", "FREQ=WEEKLY", time.Date(2025, 3, 2, 9, 0, 0, 0, time.UTC)},
+		{"America/New_York", "FREQ=MONTHLY;BYDAY=2SU", time.Date(2025, 2, 9, 9, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.location+" "+tt.rule, func(t *testing.T) {
+			location, err := time.LoadLocation(tt.location)
+			if err != nil {
+				t.Fatal(err)
+			}
+			start := time.Date(tt.start.Year(), tt.start.Month(), tt.start.Day(), 9, 0, 0, 0, location)
+			rule, err := Parse(tt.rule)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			occurrences := rule.Occurrences(start, 4)
+			if len(occurrences) != 4 {
+				t.Fatalf("got %d occurrences, want 4", len(occurrences))
+			}
+			offsets := make(map[int]bool)
+			for _, occurrence := range occurrences {
+				if occurrence.Hour() != 9 || occurrence.Minute() != 0 || occurrence.Location() != location {
+					t.Errorf("occurrence %v is not at 09:00 %s", occurrence, tt.location)
+				}
+				_, offset := occurrence.Zone()
+				offsets[offset] = true
+			}
+			if len(offsets) != 2 {
+				t.Errorf("occurrences %v do not cross a DST transition", occurrences)
+			}
+		})
+	}
+}
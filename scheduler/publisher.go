@@ -0,0 +1,51 @@
+// Package scheduler runs background jobs of the API server.
+package scheduler
+
+import (
+	"apigo1/store"
+	"context"
+	"log"
+	"time"
+)
+
+// Publisher periodically publishes blogs whose scheduled publishing time has come.
+// Several server instances can run a Publisher at the same time: the store
+// publishes each blog transactionally, so it is published only once.
+type Publisher struct {
+	store    store.BlogStoreInterface
+	interval time.Duration
+}
+
+// NewPublisher creates a new Publisher checking for due blogs every interval
+func NewPublisher(s store.BlogStoreInterface, interval time.Duration) *Publisher {
+	return &Publisher{
+		store:    s,
+		interval: interval,
+	}
+}
+
+// Run publishes due blogs immediately and then every interval, until ctx is cancelled
+func (p *Publisher) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		p.publishDue(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (p *Publisher) publishDue(ctx context.Context) {
+	blogs, err := p.store.PublishDue(ctx, time.Now())
+	for _, blog := range blogs {
+		log.Printf("Published scheduled blog %d (%s)", blog.ID, blog.Slug)
+	}
+	if err != nil && ctx.Err() == nil {
+		log.Printf("Failed to publish scheduled blogs: %v", err)
+	}
+}
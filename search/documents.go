@@ -0,0 +1,110 @@
+package search
+
+import (
+	"apigo1/markdown"
+	"apigo1/models"
+	"apigo1/store"
+	"context"
+	"strings"
+)
+
+// Field weights: a match in a title counts more than one in tags, and one in tags
+// more than one in the body
+const (
+	titleWeight = 3
+	tagsWeight  = 2
+	bodyWeight  = 1
+)
+
+// BlogDocument returns the index document of a blog. Blogs are public documents.
+func BlogDocument(blog *models.Blog) *Document {
+	return &Document{
+		Key:   Key{Type: Blog, ID: blog.ID},
+		Title: blog.Title,
+		Slug:  blog.Slug,
+		Fields: []Field{
+			{Name: "title", Text: blog.Title, Weight: titleWeight},
+			{Name: "tags", Text: strings.Join(blog.Tags, " "), Weight: tagsWeight},
+			{Name: "content", Text: markdown.PlainText(blog.Content), Weight: bodyWeight, Snippet: true},
+		},
+		UpdatedAt: blog.UpdatedAt,
+	}
+}
+
+// TodoDocument returns the index document of a todo, private to its owner
+func TodoDocument(todo *models.Todo) *Document {
+	return &Document{
+		Key:   Key{Type: Todo, ID: todo.ID},
+		Owner: todo.OwnerID,
+		Title: todo.Title,
+		Fields: []Field{
+			{Name: "title", Text: todo.Title, Weight: titleWeight},
+			{Name: "description", Text: todo.Description, Weight: bodyWeight, Snippet: true},
+		},
+		UpdatedAt: todo.UpdatedAt,
+	}
+}
+
+// IndexBlog indexes a published blog, or removes an unpublished one from the index
+func (ix *Index) IndexBlog(blog *models.Blog) {
+	if !blog.Published {
+		ix.Remove(Key{Type: Blog, ID: blog.ID})
+		return
+	}
+	ix.Add(BlogDocument(blog))
+}
+
+// OnBlogEvent keeps the index in sync with blog changes. It is a store.BlogListener.
+func (ix *Index) OnBlogEvent(event store.BlogEvent) {
+	if event.Type == store.BlogDeleted {
+		ix.Remove(Key{Type: Blog, ID: event.ID})
+		return
+	}
+	ix.IndexBlog(event.Blog)
+}
+
+// OnTodoEvent keeps the index in sync with todo changes. It is a store.TodoListener.
+func (ix *Index) OnTodoEvent(event store.TodoEvent) {
+	if event.Type == store.TodoDeleted {
+		ix.Remove(Key{Type: Todo, ID: event.ID})
+		return
+	}
+	ix.IndexTodo(event.Todo)
+}
+
+// IndexTodo indexes a todo, or removes it from the index when it has no owner:
+// nobody may search ownerless todos
+func (ix *Index) IndexTodo(todo *models.Todo) {
+	if todo.OwnerID == "" {
+		ix.Remove(Key{Type: Todo, ID: todo.ID})
+		return
+	}
+	ix.Add(TodoDocument(todo))
+}
+
+// Rebuild replaces the content of the index with the published blogs and the
+// owned todos currently in the stores
+func (ix *Index) Rebuild(ctx context.Context, blogs store.BlogStoreInterface, todos store.TodoStoreInterface) error {
+	allBlogs, err := blogs.GetAll(ctx)
+	if err != nil {
+		return err
+	}
+	allTodos, err := todos.GetAllOwners(ctx)
+	if err != nil {
+		return err
+	}
+
+	docs := make([]*Document, 0, len(allBlogs)+len(allTodos))
+	for _, blog := range allBlogs {
+		if blog.Published {
+			docs = append(docs, BlogDocument(blog))
+		}
+	}
+	for _, todo := range allTodos {
+		if todo.OwnerID != "" {
+			docs = append(docs, TodoDocument(todo))
+		}
+	}
+	ix.Replace(docs)
+	return nil
+}
@@ -0,0 +1,296 @@
+// Package search implements an in-process full-text index over blogs and todos,
+// with relevance ranking, diacritic folding, prefix matching and highlighted snippets.
+package search
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// prefixBoost scales the score of terms that only match a query term by prefix
+	prefixBoost = 0.5
+	// maxPrefixExpansions bounds the number of indexed terms a query term expands to
+	maxPrefixExpansions = 50
+)
+
+// DocType is the kind of an indexed document
+type DocType string
+
+const (
+	Blog DocType = "blog"
+	Todo DocType = "todo"
+)
+
+// Key identifies an indexed document
+type Key struct {
+	Type DocType
+	ID   int
+}
+
+// Field is a searchable text of a document. Matches in fields with a higher
+// weight rank higher.
+type Field struct {
+	Name    string
+	Text    string
+	Weight  float64
+	Snippet bool // Whether result snippets are cut from this field
+}
+
+// Document is an item of the index
+type Document struct {
+	Key
+	Owner     string // UID of the owner of a private document, "" for public documents
+	Title     string
+	Slug      string
+	Fields    []Field
+	UpdatedAt time.Time
+}
+
+// Query is a search request
+type Query struct {
+	Text  string
+	Types []DocType // Document types to search, all when empty
+	Owner string    // Private documents of this owner are searched too
+	Limit int
+}
+
+// Result is a document matching a query
+type Result struct {
+	Document  *Document
+	Score     float64
+	TitleHTML string // HTML-escaped title with matches wrapped in <mark>
+	Snippet   string // HTML-escaped excerpt with matches wrapped in <mark>
+}
+
+// Index is an inverted index of documents, safe for concurrent use
+type Index struct {
+	mu       sync.RWMutex
+	docs     map[Key]*Document
+	docTerms map[Key][]string
+	postings map[string]map[Key]float64 // term -> document -> weighted term frequency
+
+	// sortedTerms lists the indexed terms for prefix matching, rebuilt lazily
+	sortedTerms []string
+	dirty       bool
+}
+
+// NewIndex creates an empty Index
+func NewIndex() *Index {
+	return &Index{
+		docs:     make(map[Key]*Document),
+		docTerms: make(map[Key][]string),
+		postings: make(map[string]map[Key]float64),
+	}
+}
+
+// Add indexes a document, replacing any document with the same key
+func (ix *Index) Add(doc *Document) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.add(doc)
+}
+
+// Remove removes a document from the index
+func (ix *Index) Remove(key Key) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	ix.remove(key)
+}
+
+// Replace replaces the whole content of the index with docs
+func (ix *Index) Replace(docs []*Document) {
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+
+	ix.docs = make(map[Key]*Document, len(docs))
+	ix.docTerms = make(map[Key][]string, len(docs))
+	ix.postings = make(map[string]map[Key]float64)
+	ix.dirty = true
+	for _, doc := range docs {
+		ix.add(doc)
+	}
+}
+
+// Len returns the number of indexed documents
+func (ix *Index) Len() int {
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+	return len(ix.docs)
+}
+
+func (ix *Index) add(doc *Document) {
+	ix.remove(doc.Key)
+
+	weights := make(map[string]float64)
+	for _, field := range doc.Fields {
+		for _, tok := range tokenize(field.Text) {
+			weights[tok.term] += field.Weight
+		}
+	}
+
+	terms := make([]string, 0, len(weights))
+	for term, weight := range weights {
+		postings, ok := ix.postings[term]
+		if !ok {
+			postings = make(map[Key]float64)
+			ix.postings[term] = postings
+			ix.dirty = true
+		}
+		postings[doc.Key] = weight
+		terms = append(terms, term)
+	}
+	ix.docs[doc.Key] = doc
+	ix.docTerms[doc.Key] = terms
+}
+
+func (ix *Index) remove(key Key) {
+	for _, term := range ix.docTerms[key] {
+		delete(ix.postings[term], key)
+		if len(ix.postings[term]) == 0 {
+			delete(ix.postings, term)
+			ix.dirty = true
+		}
+	}
+	delete(ix.docs, key)
+	delete(ix.docTerms, key)
+}
+
+// Search returns the documents matching every word of the query, best first.
+// A query word matches indexed words equal to it or starting with it (with a
+// lower score), after folding both to lowercase ASCII.
+func (ix *Index) Search(q Query) []Result {
+	terms := queryTerms(q.Text)
+	if len(terms) == 0 {
+		return []Result{}
+	}
+
+	ix.ensureSorted()
+	ix.mu.RLock()
+	defer ix.mu.RUnlock()
+
+	total := float64(len(ix.docs))
+	scores := make(map[Key]float64)
+	matched := make(map[Key]int)
+	for _, qt := range terms {
+		// A document scores its best matching expansion of each query term
+		best := make(map[Key]float64)
+		for _, m := range ix.expand(qt) {
+			postings := ix.postings[m.term]
+			idf := math.Log(1 + total/float64(len(postings)))
+			for key, weight := range postings {
+				if score := m.boost * weight * idf; score > best[key] {
+					best[key] = score
+				}
+			}
+		}
+		for key, score := range best {
+			scores[key] += score
+			matched[key]++
+		}
+	}
+
+	results := []Result{}
+	for key, score := range scores {
+		doc := ix.docs[key]
+		if matched[key] != len(terms) || !visible(doc, q) {
+			continue
+		}
+		results = append(results, Result{Document: doc, Score: score})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		a, b := results[i], results[j]
+		if a.Score != b.Score {
+			return a.Score > b.Score
+		}
+		if !a.Document.UpdatedAt.Equal(b.Document.UpdatedAt) {
+			return a.Document.UpdatedAt.After(b.Document.UpdatedAt)
+		}
+		if a.Document.Type != b.Document.Type {
+			return a.Document.Type < b.Document.Type
+		}
+		return a.Document.ID < b.Document.ID
+	})
+	if q.Limit > 0 && len(results) > q.Limit {
+		results = results[:q.Limit]
+	}
+
+	for i := range results {
+		results[i].TitleHTML = highlight(results[i].Document.Title, terms)
+		results[i].Snippet = snippet(results[i].Document, terms)
+	}
+	return results
+}
+
+// termMatch is an indexed term matching a query term
+type termMatch struct {
+	term  string
+	boost float64
+}
+
+// expand returns the indexed terms matching a query term: the term itself and
+// the terms it is a prefix of. The caller must hold the read lock.
+func (ix *Index) expand(qt string) []termMatch {
+	var matches []termMatch
+	if _, ok := ix.postings[qt]; ok {
+		matches = append(matches, termMatch{term: qt, boost: 1})
+	}
+	for i := sort.SearchStrings(ix.sortedTerms, qt); i < len(ix.sortedTerms) && len(matches) < maxPrefixExpansions; i++ {
+		term := ix.sortedTerms[i]
+		if !strings.HasPrefix(term, qt) {
+			break
+		}
+		if term != qt {
+			matches = append(matches, termMatch{term: term, boost: prefixBoost})
+		}
+	}
+	return matches
+}
+
+// ensureSorted rebuilds the sorted term list after terms were added or removed
+func (ix *Index) ensureSorted() {
+	ix.mu.RLock()
+	dirty := ix.dirty
+	ix.mu.RUnlock()
+	if !dirty {
+		return
+	}
+
+	ix.mu.Lock()
+	defer ix.mu.Unlock()
+	if !ix.dirty {
+		return
+	}
+	terms := make([]string, 0, len(ix.postings))
+	for term := range ix.postings {
+		terms = append(terms, term)
+	}
+	sort.Strings(terms)
+	ix.sortedTerms = terms
+	ix.dirty = false
+}
+
+// visible reports whether a document may be returned for a query
+func visible(doc *Document, q Query) bool {
+	if len(q.Types) > 0 {
+		found := false
+		for _, t := range q.Types {
+			if t == doc.Type {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	// Todos are always private, including ones stored without an owner
+	if doc.Type == Todo {
+		return q.Owner != "" && doc.Owner == q.Owner
+	}
+	return doc.Owner == "" || doc.Owner == q.Owner
+}
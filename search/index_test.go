@@ -0,0 +1,178 @@
+package search
+
+import (
+	"apigo1/models"
+	"apigo1/store"
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// resultKeys returns the keys of results, in order
+func resultKeys(results []Result) []Key {
+	keys := []Key{}
+	for _, result := range results {
+		keys = append(keys, result.Document.Key)
+	}
+	return keys
+}
+
+func TestTokenize(t *testing.T) {
+	text := "Hướng dẫn: Go-lang!"
+	var terms []string
+	for _, tok := range tokenize(text) {
+		terms = append(terms, tok.term)
+		// Offsets point into the original, unfolded text
+		if span := tokenize(text[tok.start:tok.end]); len(span) != 1 || span[0].term != tok.term {
+			t.Errorf("token %q spans %q", tok.term, text[tok.start:tok.end])
+		}
+	}
+	want := []string{"huong", "dan", "go", "lang"}
+	if !reflect.DeepEqual(terms, want) {
+		t.Errorf("tokenize(%q) = %q, want %q", text, terms, want)
+	}
+}
+
+func TestSearchFoldsDiacritics(t *testing.T) {
+	ix := NewIndex()
+	ix.IndexBlog(&models.Blog{ID: 1, Title: "Hướng dẫn lập trình Go", Content: "Bài viết về **Đường** ống", Published: true})
+	ix.IndexBlog(&models.Blog{ID: 2, Title: "Rust cơ bản", Content: "Không liên quan", Published: true})
+
+	tests := []struct {
+		query string
+		want  []Key
+	}{
+		{"huong dan", []Key{{Blog, 1}}},
+		{"Hướng Dẫn", []Key{{Blog, 1}}},
+		{"HUONG", []Key{{Blog, 1}}},
+		{"duong", []Key{{Blog, 1}}},
+		{"lap tri", []Key{{Blog, 1}}},
+		{"co ban", []Key{{Blog, 2}}},
+		{"huong rust", []Key{}},
+		{"!!!", []Key{}},
+	}
+	for _, tt := range tests {
+		if got := resultKeys(ix.Search(Query{Text: tt.query})); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Search(%q) = %v, want %v", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestSearchRanksTitleMatchesFirst(t *testing.T) {
+	ix := NewIndex()
+	ix.IndexBlog(&models.Blog{ID: 1, Title: "Notes", Content: "golang tips", Published: true})
+	ix.IndexBlog(&models.Blog{ID: 2, Title: "Golang tips", Content: "notes", Published: true})
+
+	got := resultKeys(ix.Search(Query{Text: "golang"}))
+	if want := []Key{{Blog, 2}, {Blog, 1}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Search(golang) = %v, want %v", got, want)
+	}
+}
+
+func TestUnpublishedBlogsAreNotIndexed(t *testing.T) {
+	ctx := context.Background()
+	ix := NewIndex()
+	blogs := store.NewObservedBlogStore(store.NewMemoryBlogStore())
+	blogs.Subscribe(ix.OnBlogEvent)
+
+	draft, err := blogs.Create(ctx, &models.Blog{Title: "Bản nháp bí mật", Slug: "ban-nhap"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := ix.Search(Query{Text: "bi mat"}); len(got) != 0 {
+		t.Fatalf("draft is searchable: %v", resultKeys(got))
+	}
+
+	setPublished := func(published bool) {
+		t.Helper()
+		_, err := blogs.Update(ctx, draft.ID, func(blog *models.Blog) error {
+			blog.Published = published
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	setPublished(true)
+	if got := resultKeys(ix.Search(Query{Text: "bi mat"})); !reflect.DeepEqual(got, []Key{{Blog, draft.ID}}) {
+		t.Fatalf("published blog not found: %v", got)
+	}
+	setPublished(false)
+	if got := ix.Search(Query{Text: "bi mat"}); len(got) != 0 {
+		t.Errorf("unpublished blog is still searchable: %v", resultKeys(got))
+	}
+	if ix.Len() != 0 {
+		t.Errorf("index holds %d documents, want 0", ix.Len())
+	}
+
+	// Rebuilding from the stores skips drafts too
+	todos := store.NewTodoStore()
+	if err := ix.Rebuild(ctx, blogs, todos); err != nil {
+		t.Fatal(err)
+	}
+	if ix.Len() != 0 {
+		t.Errorf("rebuilt index holds %d documents, want 0", ix.Len())
+	}
+}
+
+func TestTodoResultsAreScopedToTheOwner(t *testing.T) {
+	ix := NewIndex()
+	ix.OnTodoEvent(store.TodoEvent{Type: store.TodoCreated, ID: 1, Todo: &models.Todo{ID: 1, Title: "Mua sữa", OwnerID: "alice"}})
+	ix.OnTodoEvent(store.TodoEvent{Type: store.TodoCreated, ID: 2, Todo: &models.Todo{ID: 2, Title: "Mua sữa chua", OwnerID: "bob"}})
+	ix.IndexBlog(&models.Blog{ID: 1, Title: "Sữa tươi", Published: true})
+
+	tests := []struct {
+		owner string
+		types []DocType
+		want  []Key
+	}{
+		{"alice", []DocType{Todo}, []Key{{Todo, 1}}},
+		{"bob", []DocType{Todo}, []Key{{Todo, 2}}},
+		{"carol", []DocType{Todo}, []Key{}},
+		{"", nil, []Key{{Blog, 1}}},
+		{"alice", nil, []Key{{Blog, 1}, {Todo, 1}}},
+	}
+	for _, tt := range tests {
+		got := resultKeys(ix.Search(Query{Text: "sua", Owner: tt.owner, Types: tt.types}))
+		sort.Slice(got, func(i, j int) bool {
+			return got[i].Type < got[j].Type || (got[i].Type == got[j].Type && got[i].ID < got[j].ID)
+		})
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Search(sua) for %q in %v = %v, want %v", tt.owner, tt.types, got, tt.want)
+		}
+	}
+
+	ix.OnTodoEvent(store.TodoEvent{Type: store.TodoDeleted, ID: 1, OwnerID: "alice"})
+	if got := ix.Search(Query{Text: "sua", Owner: "alice", Types: []DocType{Todo}}); len(got) != 0 {
+		t.Errorf("deleted todo is still searchable: %v", resultKeys(got))
+	}
+}
+
+func TestOwnerlessTodosAreNeverSearchable(t *testing.T) {
+	ownerless := &models.Todo{ID: 1, Title: "Mật khẩu wifi"}
+
+	// Documents added directly are still filtered out of every search
+	ix := NewIndex()
+	ix.Add(TodoDocument(ownerless))
+	for _, owner := range []string{"", "alice"} {
+		if got := ix.Search(Query{Text: "mat khau", Owner: owner}); len(got) != 0 {
+			t.Errorf("Search(mat khau) for %q = %v, want no results", owner, resultKeys(got))
+		}
+	}
+
+	ix = NewIndex()
+	ix.OnTodoEvent(store.TodoEvent{Type: store.TodoCreated, ID: 1, Todo: ownerless})
+	if ix.Len() != 0 {
+		t.Errorf("index holds %d documents after an ownerless todo event, want 0", ix.Len())
+	}
+
+	todos := store.NewTodoStore()
+	todos.Create(context.Background(), &models.Todo{Title: "Mật khẩu wifi"})
+	if err := ix.Rebuild(context.Background(), store.NewMemoryBlogStore(), todos); err != nil {
+		t.Fatal(err)
+	}
+	if ix.Len() != 0 {
+		t.Errorf("rebuilt index holds %d documents, want 0", ix.Len())
+	}
+}
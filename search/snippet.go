@@ -0,0 +1,115 @@
+package search
+
+import (
+	"html"
+	"strings"
+)
+
+const (
+	// snippetLead is the number of bytes of context shown before the first match
+	snippetLead = 60
+	// snippetLength is the maximum length of a snippet in bytes, before escaping
+	snippetLength = 200
+)
+
+// highlight returns text HTML-escaped, with the words matching the query terms
+// wrapped in <mark>
+func highlight(text string, terms []string) string {
+	return markRange(text, tokenize(text), 0, len(text), terms)
+}
+
+// snippet returns an excerpt around the first match in the snippet fields of a
+// document, or the beginning of its first non-empty snippet field when none matches
+func snippet(doc *Document, terms []string) string {
+	var fallback string
+	for _, field := range doc.Fields {
+		if !field.Snippet || field.Text == "" {
+			continue
+		}
+		tokens := tokenize(field.Text)
+		for i, tok := range tokens {
+			if matchesQuery(tok.term, terms) {
+				return excerpt(field.Text, tokens, i, terms)
+			}
+		}
+		if fallback == "" {
+			fallback = excerpt(field.Text, tokens, 0, terms)
+		}
+	}
+	return fallback
+}
+
+// excerpt cuts a window of text around tokens[first], on word boundaries
+func excerpt(text string, tokens []token, first int, terms []string) string {
+	if len(tokens) == 0 {
+		return html.EscapeString(truncate(text, snippetLength))
+	}
+
+	// Start at the first word at most snippetLead bytes before the match
+	start := tokens[first].start - snippetLead
+	if start <= 0 {
+		start = 0
+	} else {
+		i := first
+		for i > 0 && tokens[i-1].start >= start {
+			i--
+		}
+		start = tokens[i].start
+	}
+
+	// End after the last word that fits in snippetLength
+	end := len(text)
+	if end-start > snippetLength {
+		end = tokens[first].end
+		for _, tok := range tokens[first:] {
+			if tok.end-start > snippetLength {
+				break
+			}
+			end = tok.end
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+	b.WriteString(markRange(text, tokens, start, end, terms))
+	if end < len(text) {
+		b.WriteString("…")
+	}
+	return b.String()
+}
+
+// markRange HTML-escapes text[start:end], wrapping the tokens that match the
+// query terms in <mark>
+func markRange(text string, tokens []token, start, end int, terms []string) string {
+	var b strings.Builder
+	pos := start
+	for _, tok := range tokens {
+		if tok.start < start || tok.end > end || !matchesQuery(tok.term, terms) {
+			continue
+		}
+		b.WriteString(html.EscapeString(text[pos:tok.start]))
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(text[tok.start:tok.end]))
+		b.WriteString("</mark>")
+		pos = tok.end
+	}
+	b.WriteString(html.EscapeString(text[pos:end]))
+	return b.String()
+}
+
+// truncate cuts s to at most n bytes without splitting a UTF-8 sequence
+func truncate(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
+
+func utf8RuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,63 @@
+package search
+
+import (
+	"apigo1/fold"
+	"strings"
+)
+
+// token is a folded word of a text, with its byte offsets in the original text
+type token struct {
+	term       string
+	start, end int
+}
+
+// tokenize splits text into folded words
+func tokenize(text string) []token {
+	var tokens []token
+	var term strings.Builder
+	start := -1
+
+	for i, r := range text {
+		folded, inWord := fold.Rune(r)
+		if !inWord {
+			if term.Len() > 0 {
+				tokens = append(tokens, token{term: term.String(), start: start, end: i})
+				term.Reset()
+			}
+			start = -1
+			continue
+		}
+		if start < 0 {
+			start = i
+		}
+		term.WriteString(folded)
+	}
+	if term.Len() > 0 {
+		tokens = append(tokens, token{term: term.String(), start: start, end: len(text)})
+	}
+	return tokens
+}
+
+// queryTerms returns the distinct folded words of a query, in order
+func queryTerms(text string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	for _, tok := range tokenize(text) {
+		if !seen[tok.term] {
+			seen[tok.term] = true
+			terms = append(terms, tok.term)
+		}
+	}
+	return terms
+}
+
+// matchesQuery reports whether an indexed term matches one of the query terms,
+// exactly or by prefix
+func matchesQuery(term string, terms []string) bool {
+	for _, qt := range terms {
+		if strings.HasPrefix(term, qt) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,66 @@
+// Package sitemap encodes sitemap and sitemap index documents (sitemaps.org protocol 0.9).
+package sitemap
+
+import (
+	"encoding/xml"
+	"time"
+)
+
+// MaxURLs is the maximum number of URLs a single sitemap may list
+const MaxURLs = 50000
+
+const namespace = "http://www.sitemaps.org/schemas/sitemap/0.9"
+
+// URL is one entry of a sitemap or sitemap index
+type URL struct {
+	Loc     string
+	LastMod time.Time // omitted when zero
+}
+
+type urlSet struct {
+	XMLName xml.Name   `xml:"urlset"`
+	XMLNS   string     `xml:"xmlns,attr"`
+	URLs    []urlEntry `xml:"url"`
+}
+
+type sitemapIndex struct {
+	XMLName  xml.Name   `xml:"sitemapindex"`
+	XMLNS    string     `xml:"xmlns,attr"`
+	Sitemaps []urlEntry `xml:"sitemap"`
+}
+
+type urlEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod,omitempty"`
+}
+
+// URLSet encodes a sitemap listing urls
+func URLSet(urls []URL) ([]byte, error) {
+	return encode(urlSet{XMLNS: namespace, URLs: entries(urls)})
+}
+
+// Index encodes a sitemap index listing the given sitemaps
+func Index(sitemaps []URL) ([]byte, error) {
+	return encode(sitemapIndex{XMLNS: namespace, Sitemaps: entries(sitemaps)})
+}
+
+func entries(urls []URL) []urlEntry {
+	out := make([]urlEntry, 0, len(urls))
+	for _, u := range urls {
+		entry := urlEntry{Loc: u.Loc}
+		if !u.LastMod.IsZero() {
+			entry.LastMod = u.LastMod.UTC().Format(time.RFC3339)
+		}
+		out = append(out, entry)
+	}
+	return out
+}
+
+// encode marshals a sitemap document with the XML header
+func encode(doc interface{}) ([]byte, error) {
+	body, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), body...), nil
+}
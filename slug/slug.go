@@ -0,0 +1,178 @@
+// Package slug generates URL-friendly slugs from titles. Vietnamese and other
+// Latin-script text is transliterated to ASCII ("Hướng dẫn Go cơ bản" becomes
+// "huong-dan-go-co-ban"); characters of other scripts are dropped.
+package slug
+
+import (
+	"apigo1/fold"
+	"strings"
+	"unicode"
+)
+
+// DefaultMaxLength is the maximum slug length used when Options.MaxLength is not set
+const DefaultMaxLength = 80
+
+// Options configures a Slugger
+type Options struct {
+	// MaxLength is the maximum length of a slug in bytes; longer slugs are cut at
+	// a word boundary. Zero means DefaultMaxLength.
+	MaxLength int
+	// StopWords are left out of the slugs made from titles, unless the title only
+	// contains stop words. They are matched after transliteration, so "và" and "va"
+	// are the same word.
+	StopWords []string
+}
+
+// Slugger turns titles into slugs
+type Slugger struct {
+	maxLength int
+	stopWords map[string]bool
+}
+
+// New creates a new Slugger
+func New(opts Options) *Slugger {
+	s := &Slugger{
+		maxLength: opts.MaxLength,
+		stopWords: make(map[string]bool),
+	}
+	if s.maxLength <= 0 {
+		s.maxLength = DefaultMaxLength
+	}
+	for _, word := range opts.StopWords {
+		for _, w := range words(word) {
+			s.stopWords[w] = true
+		}
+	}
+	return s
+}
+
+// Make returns the slug of a title: lowercase ASCII letters and digits separated
+// by single hyphens. It returns "" when the title has no usable characters.
+func (s *Slugger) Make(title string) string {
+	all := words(title)
+
+	kept := make([]string, 0, len(all))
+	for _, w := range all {
+		if !s.stopWords[w] {
+			kept = append(kept, w)
+		}
+	}
+	if len(kept) == 0 {
+		kept = all
+	}
+	return s.join(kept)
+}
+
+// Normalize returns the slug of a slug given explicitly: it is built like Make but
+// keeps stop words, since the caller chose them.
+func (s *Slugger) Normalize(slug string) string {
+	return s.join(words(slug))
+}
+
+// join joins words with hyphens, leaving out the words past maxLength
+func (s *Slugger) join(words []string) string {
+	var b strings.Builder
+	for _, w := range words {
+		sep := 0
+		if b.Len() > 0 {
+			sep = 1
+		}
+		if b.Len()+sep+len(w) > s.maxLength {
+			// The first word alone is too long: cut it instead of returning nothing
+			if b.Len() == 0 {
+				b.WriteString(w[:s.maxLength])
+			}
+			break
+		}
+		if sep == 1 {
+			b.WriteByte('-')
+		}
+		b.WriteString(w)
+	}
+	return b.String()
+}
+
+// words transliterates text to lowercase ASCII and splits it into words
+func words(text string) []string {
+	var result []string
+	var word strings.Builder
+	flush := func() {
+		if word.Len() > 0 {
+			result = append(result, word.String())
+			word.Reset()
+		}
+	}
+
+	for _, r := range text {
+		// Apostrophes join words: "don't" becomes "dont"
+		if r == '\'' || r == '’' {
+			continue
+		}
+		folded, inWord := fold.Rune(r)
+		if !inWord {
+			flush()
+			continue
+		}
+		word.WriteString(folded)
+	}
+	flush()
+	return result
+}
+
+// Tag returns the normalized form of a tag. Letters are folded like in slugs, so
+// that "Go", "go" and " GO " are the same tag and "Lập trình" becomes "lap-trinh",
+// but symbols are kept so that "C++", "C#" and ".NET" stay distinct tags. Runs of
+// spaces, hyphens and slashes become one hyphen; slashes would split the tag in
+// URL paths. Tags are cut to DefaultMaxLength bytes and a tag without letters or
+// digits normalizes to "". Tags are escaped when they are put in URLs.
+func Tag(tag string) string {
+	var b strings.Builder
+	separate, hasWord := false, false
+	for _, r := range tag {
+		if unicode.IsSpace(r) || r == '-' || r == '/' || !unicode.IsPrint(r) {
+			separate = b.Len() > 0
+			continue
+		}
+		folded, inWord := fold.Rune(r)
+		switch {
+		case inWord:
+			hasWord = hasWord || folded != ""
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			// Letters of other scripts have no ASCII form but are kept as they are
+			folded, hasWord = string(unicode.ToLower(r)), true
+		default:
+			folded = string(r)
+		}
+		if folded == "" {
+			continue
+		}
+		if separate {
+			folded = "-" + folded
+			separate = false
+		}
+		if b.Len()+len(folded) > DefaultMaxLength {
+			break
+		}
+		b.WriteString(folded)
+	}
+	if !hasWord {
+		return ""
+	}
+	return b.String()
+}
+
+// Tags normalizes a list of tags, dropping empty and duplicate tags and keeping
+// the order of first appearance
+func Tags(tags []string) []string {
+	seen := make(map[string]bool, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = Tag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
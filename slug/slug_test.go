@@ -0,0 +1,151 @@
+package slug
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMake(t *testing.T) {
+	slugs := New(Options{})
+	tests := []struct {
+		name  string
+		title string
+		want  string
+	}{
+		{"vietnamese", "Hướng dẫn Go cơ bản", "huong-dan-go-co-ban"},
+		{"vietnamese d with stroke", "Đường đi của Đạt", "duong-di-cua-dat"},
+		{"vietnamese horn letters", "Ưu tiên phương thức mới", "uu-tien-phuong-thuc-moi"},
+		{"vietnamese tone marks", "Tổng hợp những lỗi thường gặp", "tong-hop-nhung-loi-thuong-gap"},
+		{"vietnamese decomposed", "Hu\u031bo\u031b\u0301ng da\u0302\u0303n ho\u0323c Go", "huong-dan-hoc-go"},
+		{"vietnamese punctuation", "Go 1.21: Có gì mới?", "go-1-21-co-gi-moi"},
+		{"french", "Café crème à la française", "cafe-creme-a-la-francaise"},
+		{"german", "Straße über Köln", "strasse-uber-koln"},
+		{"spanish", "¿Qué es El Niño?", "que-es-el-nino"},
+		{"polish", "Łódź nocą", "lodz-noca"},
+		{"danish", "Smørrebrød og æbleskiver", "smorrebrod-og-aebleskiver"},
+		{"apostrophes", "Don’t panic, it's Go", "dont-panic-its-go"},
+		{"other scripts dropped", "Go 入门 guide", "go-guide"},
+		{"extra separators", "  Hello --  World!  ", "hello-world"},
+		{"no usable characters", "入门 !!!", ""},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := slugs.Make(tt.title); got != tt.want {
+				t.Errorf("Make(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMakeMaxLength(t *testing.T) {
+	tests := []struct {
+		name      string
+		maxLength int
+		title     string
+		want      string
+	}{
+		{"cut at word boundary", 20, "Hướng dẫn lập trình Go cơ bản", "huong-dan-lap-trinh"},
+		{"exact fit", 9, "Hướng dẫn", "huong-dan"},
+		{"one byte short", 8, "Hướng dẫn", "huong"},
+		{"first word too long", 5, "Internationalization", "inter"},
+		{"default", 0, "Bài viết " + strings.Repeat("rất ", 30) + "dài", "bai-viet" + strings.Repeat("-rat", 18)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := New(Options{MaxLength: tt.maxLength}).Make(tt.title)
+			if got != tt.want {
+				t.Errorf("Make(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMakeStopWords(t *testing.T) {
+	slugs := New(Options{StopWords: []string{"và", "của", "the", "a"}})
+	tests := []struct {
+		name  string
+		title string
+		want  string
+	}{
+		{"vietnamese stop words", "Go và Rust", "go-rust"},
+		{"matched without diacritics", "Sức mạnh cua Go", "suc-manh-go"},
+		{"english stop words", "The Art of a Go Program", "art-of-go-program"},
+		{"only stop words", "Và của", "va-cua"},
+		{"only english stop words", "The A", "the-a"},
+		{"no stop words", "Lập trình Go", "lap-trinh-go"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := slugs.Make(tt.title); got != tt.want {
+				t.Errorf("Make(%q) = %q, want %q", tt.title, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	slugs := New(Options{MaxLength: 20, StopWords: []string{"và", "the"}})
+	tests := []struct {
+		slug string
+		want string
+	}{
+		{"go-va-rust", "go-va-rust"},
+		{"Go và Rust", "go-va-rust"},
+		{"the-go-way", "the-go-way"},
+		{"  Hello, World! ", "hello-world"},
+		{"huong-dan-lap-trinh-go", "huong-dan-lap-trinh"},
+		{"!!!", ""},
+	}
+	for _, tt := range tests {
+		if got := slugs.Normalize(tt.slug); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.slug, got, tt.want)
+		}
+	}
+}
+
+func TestTags(t *testing.T) {
+	got := Tags([]string{"Go", " go ", "Lập Trình", "", "!!!", "the", "lap-trinh"})
+	want := []string{"go", "lap-trinh", "the"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tags() = %q, want %q", got, want)
+	}
+}
+
+func TestTag(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{"Go", "go"},
+		{"  Lập   Trình ", "lap-trinh"},
+		{"lap-trinh", "lap-trinh"},
+		{"C++", "c++"},
+		{"C#", "c#"},
+		{"C", "c"},
+		{".NET", ".net"},
+		{"Node.js", "node.js"},
+		{"CI/CD", "ci-cd"},
+		{"日本語", "日本語"},
+		{"!!!", ""},
+		{"  ", ""},
+	}
+	for _, tt := range tests {
+		if got := Tag(tt.tag); got != tt.want {
+			t.Errorf("Tag(%q) = %q, want %q", tt.tag, got, tt.want)
+		}
+	}
+
+	if got := Tag(strings.Repeat("go ", 40)); len(got) > DefaultMaxLength || !strings.HasSuffix(got, "go") {
+		t.Errorf("long tag = %q, want at most %d bytes ending with a whole word", got, DefaultMaxLength)
+	}
+}
+
+func TestTagsKeepSymbolTagsDistinct(t *testing.T) {
+	got := Tags([]string{"C++", "C#", "C", "c#"})
+	want := []string{"c++", "c#", "c"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Tags() = %q, want %q", got, want)
+	}
+}
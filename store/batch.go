@@ -0,0 +1,218 @@
+package store
+
+import (
+	"apigo1/firebase"
+	"apigo1/models"
+	"context"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// MaxBatchOperations is the maximum number of operations of one batch
+const MaxBatchOperations = 100
+
+// maxBatchWrites is the maximum number of writes in one Firestore batch or transaction
+const maxBatchWrites = 500
+
+// TodoBatchOp is one operation of a todo batch. A create adds Todo, an update
+// applies Change to the todo ID the way Update does and a delete removes the todo ID.
+// An update whose Change returns an error fails with that error.
+type TodoBatchOp struct {
+	Type   models.BatchOp
+	ID     int
+	Todo   *models.Todo
+	Change TodoChange
+}
+
+// TodoBatchResult is the outcome of one operation of a todo batch
+type TodoBatchResult struct {
+	Todo *models.Todo // Created or updated todo, nil for deletions and failed operations
+	Next *models.Todo // Next occurrence created by completing a recurring todo, if any
+	Err  error
+}
+
+// BlogBatchOp is one operation of a blog batch. A create adds Blog, an update
+// applies Change to the blog ID the way Update does and a delete removes the blog ID.
+// An update whose Change returns an error fails with that error.
+type BlogBatchOp struct {
+	Type   models.BatchOp
+	ID     int
+	Blog   *models.Blog
+	Change BlogChange
+}
+
+// BlogBatchResult is the outcome of one operation of a blog batch
+type BlogBatchResult struct {
+	Blog *models.Blog // Created or updated blog, nil for deletions and failed operations
+	Err  error
+}
+
+// txWrite is a write prepared inside a Firestore transaction. Transactions must do
+// all their reads before writing, so batches prepare every operation first.
+type txWrite func(tx *firestore.Transaction) error
+
+// checkBatchTarget returns the error of a batch operation of type op on the item id
+// before it is prepared: an unknown type or an item targeted by an earlier operation.
+// targeted collects the items of the updates and deletes checked so far.
+func checkBatchTarget(op models.BatchOp, id int, targeted map[int]bool) error {
+	switch op {
+	case models.BatchCreate:
+		return nil
+	case models.BatchUpdate, models.BatchDelete:
+		if targeted[id] {
+			return fmt.Errorf("%w: %d is changed by more than one operation of the batch", ErrConflict, id)
+		}
+		targeted[id] = true
+		return nil
+	}
+	return fmt.Errorf("%w: unknown batch operation %q", ErrInvalidQuery, op)
+}
+
+// planTodoBatch prepares the operations of a todo batch of ownerID without writing
+// anything. existing holds the current state of the todos targeted by updates and
+// deletes, without the todos that do not exist or belong to another owner, and last
+// is the last position of the owner. Created todos, updated todos (copies of the
+// existing ones) and next occurrences get their final state and are appended in
+// operation order, but get no ID. Operations that cannot be applied get an error.
+func planTodoBatch(ownerID string, ops []TodoBatchOp, existing map[int]*models.Todo, last string, now time.Time) []TodoBatchResult {
+	results := make([]TodoBatchResult, len(ops))
+	targeted := make(map[int]bool)
+	appendPosition := func() (string, error) {
+		position, err := positionBetween(last, "")
+		if err == nil {
+			last = position
+		}
+		return position, err
+	}
+
+	for i, op := range ops {
+		if err := checkBatchTarget(op.Type, op.ID, targeted); err != nil {
+			results[i].Err = err
+			continue
+		}
+		if op.Type != models.BatchCreate && existing[op.ID] == nil {
+			results[i].Err = ErrNotFound
+			continue
+		}
+
+		switch op.Type {
+		case models.BatchCreate:
+			position, err := appendPosition()
+			if err != nil {
+				results[i].Err = err
+				continue
+			}
+			todo := op.Todo
+			todo.OwnerID = ownerID
+			todo.Position = position
+			todo.CreatedAt = now
+			todo.UpdatedAt = now
+			prepareTodo(todo)
+			results[i].Todo = todo
+
+		case models.BatchUpdate:
+			todo := *existing[op.ID]
+			wasCompleted := todo.Completed
+			if err := applyTodoChange(&todo, op.Change, now); err != nil {
+				results[i].Err = err
+				continue
+			}
+
+			// Completing an occurrence of a recurring todo creates the next one
+			if next := nextTodo(&todo, wasCompleted); next != nil {
+				position, err := appendPosition()
+				if err != nil {
+					results[i].Err = err
+					continue
+				}
+				next.Position = position
+				next.CreatedAt = now
+				next.UpdatedAt = now
+				results[i].Next = next
+			}
+			results[i].Todo = &todo
+		}
+	}
+	return results
+}
+
+// abortTodoBatch reports whether an atomic batch must be aborted because one of
+// its operations failed, and then sets ErrBatchAborted on the other operations
+func abortTodoBatch(results []TodoBatchResult, atomic bool) bool {
+	if !atomic || !todoBatchFailed(results) {
+		return false
+	}
+	for i := range results {
+		if results[i].Err == nil {
+			results[i] = TodoBatchResult{Err: ErrBatchAborted}
+		}
+	}
+	return true
+}
+
+func todoBatchFailed(results []TodoBatchResult) bool {
+	for _, result := range results {
+		if result.Err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// abortBlogBatch reports whether an atomic batch must be aborted because one of
+// its operations failed, and then sets ErrBatchAborted on the other operations
+func abortBlogBatch(results []BlogBatchResult, atomic bool) bool {
+	if !atomic || !blogBatchFailed(results) {
+		return false
+	}
+	for i := range results {
+		if results[i].Err == nil {
+			results[i] = BlogBatchResult{Err: ErrBatchAborted}
+		}
+	}
+	return true
+}
+
+func blogBatchFailed(results []BlogBatchResult) bool {
+	for _, result := range results {
+		if result.Err != nil {
+			return true
+		}
+	}
+	return false
+}
+
+// commitBatches calls write for the indexes 0 to n-1 and commits the writes in
+// batches of at most maxBatchWrites
+func commitBatches(ctx context.Context, n int, write func(batch *firestore.WriteBatch, i int)) error {
+	for start := 0; start < n; start += maxBatchWrites {
+		batch := firebase.FirestoreClient.Batch()
+		for i := start; i < min(start+maxBatchWrites, n); i++ {
+			write(batch, i)
+		}
+		if _, err := batch.Commit(ctx); err != nil {
+			return translateError(err)
+		}
+	}
+	return nil
+}
+
+// checkBatchSize returns ErrBatchTooLarge when a batch has more than
+// MaxBatchOperations operations
+func checkBatchSize(n int) error {
+	if n > MaxBatchOperations {
+		return fmt.Errorf("%w: the batch has %d operations, at most %d are allowed", ErrBatchTooLarge, n, MaxBatchOperations)
+	}
+	return nil
+}
+
+// checkBatchWrites returns ErrBatchTooLarge when a batch needs more writes than
+// one transaction allows
+func checkBatchWrites(n int) error {
+	if n > maxBatchWrites {
+		return fmt.Errorf("%w: the batch needs %d writes, at most %d fit in one transaction", ErrBatchTooLarge, n, maxBatchWrites)
+	}
+	return nil
+}
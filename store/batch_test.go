@@ -0,0 +1,118 @@
+package store
+
+import (
+	"apigo1/models"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestTodoBatchAbortLeavesNoWrites(t *testing.T) {
+	ctx := context.Background()
+	s := NewTodoStore()
+	first, _ := s.Create(ctx, &models.Todo{Title: "first", OwnerID: "alice"})
+	second, _ := s.Create(ctx, &models.Todo{Title: "second", OwnerID: "alice"})
+	foreign, _ := s.Create(ctx, &models.Todo{Title: "foreign", OwnerID: "bob"})
+
+	ops := []TodoBatchOp{
+		{Type: models.BatchCreate, Todo: &models.Todo{Title: "created", OwnerID: "alice"}},
+		{Type: models.BatchUpdate, ID: first.ID, Change: func(todo *models.Todo) error {
+			todo.Title = "renamed"
+			return nil
+		}},
+		{Type: models.BatchDelete, ID: second.ID},
+		{Type: models.BatchDelete, ID: foreign.ID},
+	}
+	results, err := s.Batch(ctx, "alice", ops, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []error{ErrBatchAborted, ErrBatchAborted, ErrBatchAborted, ErrNotFound} {
+		if !errors.Is(results[i].Err, want) {
+			t.Errorf("operation %d: got %v, want %v", i, results[i].Err, want)
+		}
+	}
+
+	todos, _ := s.GetAll(ctx, "alice")
+	if len(todos) != 2 || todos[0].Title != "first" || todos[1].Title != "second" {
+		t.Errorf("todos after the aborted batch = %v, want first and second unchanged", todos)
+	}
+	created, _ := s.Create(ctx, &models.Todo{Title: "next", OwnerID: "alice"})
+	if created.ID != foreign.ID+1 {
+		t.Errorf("next todo ID = %d, want %d", created.ID, foreign.ID+1)
+	}
+}
+
+func TestBlogBatchAbortLeavesNoWrites(t *testing.T) {
+	ctx := context.Background()
+	s := NewMemoryBlogStore()
+	blog, _ := s.Create(ctx, &models.Blog{Title: "Post", Slug: "post"})
+
+	ops := []BlogBatchOp{
+		{Type: models.BatchCreate, Blog: &models.Blog{Title: "Created", Slug: "created"}},
+		{Type: models.BatchUpdate, ID: blog.ID, Change: func(blog *models.Blog) error {
+			blog.Title = "Renamed"
+			blog.Slug = "renamed"
+			return nil
+		}},
+		{Type: models.BatchDelete, ID: 99},
+	}
+	results, err := s.Batch(ctx, ops, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []error{ErrBatchAborted, ErrBatchAborted, ErrNotFound} {
+		if !errors.Is(results[i].Err, want) {
+			t.Errorf("operation %d: got %v, want %v", i, results[i].Err, want)
+		}
+	}
+
+	blogs, _ := s.GetAll(ctx)
+	if len(blogs) != 1 || blogs[0].Title != "Post" || blogs[0].Revision != 1 {
+		t.Errorf("blogs after the aborted batch = %v, want Post unchanged", blogs)
+	}
+	if _, err := s.GetBySlug(ctx, "created"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetBySlug(created): got %v, want ErrNotFound", err)
+	}
+	if _, err := s.GetBySlug(ctx, "renamed"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("GetBySlug(renamed): got %v, want ErrNotFound", err)
+	}
+	if revisions, _ := s.ListRevisions(ctx, blog.ID); len(revisions) != 1 {
+		t.Errorf("revisions after the aborted batch = %d, want 1", len(revisions))
+	}
+	created, _ := s.Create(ctx, &models.Blog{Title: "Next", Slug: "next"})
+	if created.ID != blog.ID+1 {
+		t.Errorf("next blog ID = %d, want %d", created.ID, blog.ID+1)
+	}
+}
+
+func TestBatchTooLarge(t *testing.T) {
+	ctx := context.Background()
+
+	todoOps := make([]TodoBatchOp, MaxBatchOperations+1)
+	for i := range todoOps {
+		todoOps[i] = TodoBatchOp{Type: models.BatchCreate, Todo: &models.Todo{Title: "todo", OwnerID: "alice"}}
+	}
+	todos := NewTodoStore()
+	if _, err := todos.Batch(ctx, "alice", todoOps, false); !errors.Is(err, ErrBatchTooLarge) {
+		t.Errorf("todo batch of %d operations: got %v, want ErrBatchTooLarge", len(todoOps), err)
+	}
+	if all, _ := todos.GetAll(ctx, "alice"); len(all) != 0 {
+		t.Errorf("todos after the rejected batch = %d, want 0", len(all))
+	}
+	if _, err := todos.Batch(ctx, "alice", todoOps[:MaxBatchOperations], false); err != nil {
+		t.Errorf("todo batch of %d operations: %v", MaxBatchOperations, err)
+	}
+
+	blogOps := make([]BlogBatchOp, MaxBatchOperations+1)
+	for i := range blogOps {
+		blogOps[i] = BlogBatchOp{Type: models.BatchCreate, Blog: &models.Blog{Title: "Post", Slug: "post"}}
+	}
+	blogs := NewMemoryBlogStore()
+	if _, err := blogs.Batch(ctx, blogOps, false); !errors.Is(err, ErrBatchTooLarge) {
+		t.Errorf("blog batch of %d operations: got %v, want ErrBatchTooLarge", len(blogOps), err)
+	}
+	if all, _ := blogs.GetAll(ctx); len(all) != 0 {
+		t.Errorf("blogs after the rejected batch = %d, want 0", len(all))
+	}
+}
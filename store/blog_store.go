@@ -4,38 +4,44 @@ import (
 	"apigo1/firebase"
 	"apigo1/models"
 	"context"
+	"errors"
+	"log"
 	"strconv"
 	"time"
 
+	"cloud.google.com/go/firestore"
 	"google.golang.org/api/iterator"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // BlogStore manages blogs in Firestore
 type BlogStore struct {
 	collection string
-	ctx        context.Context
+	ids        IDAllocator
 }
 
 // NewBlogStore creates a new BlogStore
-func NewBlogStore(ctx context.Context) *BlogStore {
+func NewBlogStore(ids IDAllocator) *BlogStore {
 	return &BlogStore{
 		collection: "blogs",
-		ctx:        ctx,
+		ids:        ids,
 	}
 }
 
 // GetAll returns all blogs
-func (s *BlogStore) GetAll() []*models.Blog {
-	var blogs []*models.Blog
+func (s *BlogStore) GetAll(ctx context.Context) ([]*models.Blog, error) {
+	blogs := []*models.Blog{}
 
-	iter := firebase.FirestoreClient.Collection(s.collection).Documents(s.ctx)
+	iter := firebase.FirestoreClient.Collection(s.collection).Documents(ctx)
+	defer iter.Stop()
 	for {
 		doc, err := iter.Next()
 		if err == iterator.Done {
 			break
 		}
 		if err != nil {
-			return blogs
+			return nil, translateError(err)
 		}
 
 		blog := &models.Blog{}
@@ -49,123 +55,623 @@ func (s *BlogStore) GetAll() []*models.Blog {
 		blogs = append(blogs, blog)
 	}
 
-	return blogs
+	return blogs, nil
+}
+
+// List returns one page of blogs matching opts, newest first unless opts.Sort is set,
+// together with the cursor of the next page ("" on the last page)
+func (s *BlogStore) List(ctx context.Context, opts ListOptions) ([]*models.Blog, string, error) {
+	collection := firebase.FirestoreClient.Collection(s.collection)
+	query, err := applyFirestoreQuery(collection.Query, blogFieldPaths, opts, blogDefaultSort)
+	if err != nil {
+		return nil, "", err
+	}
+
+	docs, nextCursor, err := firestorePage(ctx, collection, query, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	blogs := make([]*models.Blog, 0, len(docs))
+	for _, doc := range docs {
+		blog := &models.Blog{}
+		if err := doc.DataTo(blog); err != nil {
+			continue
+		}
+		if id, err := strconv.Atoi(doc.Ref.ID); err == nil {
+			blog.ID = id
+		}
+		blogs = append(blogs, blog)
+	}
+
+	return blogs, nextCursor, nil
 }
 
 // GetByID returns a blog by ID
-func (s *BlogStore) GetByID(id int) (*models.Blog, bool) {
+func (s *BlogStore) GetByID(ctx context.Context, id int) (*models.Blog, error) {
 	docRef := firebase.FirestoreClient.Collection(s.collection).Doc(strconv.Itoa(id))
-	doc, err := docRef.Get(s.ctx)
+	doc, err := docRef.Get(ctx)
 	if err != nil {
-		return nil, false
+		return nil, translateError(err)
 	}
 
 	blog := &models.Blog{}
 	if err := doc.DataTo(blog); err != nil {
-		return nil, false
+		return nil, err
 	}
 	blog.ID = id
-	return blog, true
+	return blog, nil
 }
 
-// GetBySlug returns a blog by slug
-func (s *BlogStore) GetBySlug(slug string) (*models.Blog, bool) {
-	iter := firebase.FirestoreClient.Collection(s.collection).Where("slug", "==", slug).Documents(s.ctx)
-	docs, err := iter.GetAll()
-	if err != nil || len(docs) == 0 {
-		return nil, false
+// GetBySlug returns the blog using slug, either as its current slug or as one of
+// its previous slugs (callers compare blog.Slug to detect a renamed slug)
+func (s *BlogStore) GetBySlug(ctx context.Context, slug string) (*models.Blog, error) {
+	client := firebase.FirestoreClient
+	doc, err := client.Collection(slugsCollection).Doc(slug).Get(ctx)
+	if err == nil {
+		var reservation slugReservation
+		if err := doc.DataTo(&reservation); err != nil {
+			return nil, err
+		}
+		return s.GetByID(ctx, reservation.BlogID)
+	}
+	if status.Code(err) != codes.NotFound {
+		return nil, translateError(err)
+	}
+
+	// Blogs created before slug reservations are only found by their current slug
+	docs, err := client.Collection(s.collection).Where(blogFieldPaths["slug"], "==", slug).Limit(1).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, translateError(err)
+	}
+	if len(docs) == 0 {
+		return nil, ErrNotFound
 	}
 
 	blog := &models.Blog{}
 	if err := docs[0].DataTo(blog); err != nil {
-		return nil, false
+		return nil, err
 	}
 	if id, err := strconv.Atoi(docs[0].Ref.ID); err == nil {
 		blog.ID = id
 	}
-	return blog, true
+	return blog, nil
 }
 
-// Create creates a new blog
-func (s *BlogStore) Create(blog *models.Blog) *models.Blog {
-	// Get the next ID by counting documents
-	docs, err := firebase.FirestoreClient.Collection(s.collection).Documents(s.ctx).GetAll()
+// Create creates a new blog together with its first revision. When blog.Slug is
+// taken by another blog, a numeric suffix is appended (my-post-2, my-post-3, ...).
+func (s *BlogStore) Create(ctx context.Context, blog *models.Blog) (*models.Blog, error) {
+	id, err := s.ids.NextID(ctx, s.collection)
 	if err != nil {
-		// Fallback: use timestamp as ID
-		blog.ID = int(time.Now().Unix())
-	} else {
-		maxID := 0
-		for _, doc := range docs {
-			if id, err := strconv.Atoi(doc.Ref.ID); err == nil && id > maxID {
-				maxID = id
-			}
-		}
-		blog.ID = maxID + 1
+		return nil, err
 	}
+	blog.ID = id
 
 	now := time.Now()
 	blog.CreatedAt = now
 	blog.UpdatedAt = now
+	blog.Revision = 1
+
+	client := firebase.FirestoreClient
+	baseSlug := blog.Slug
+	err = client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		writes, err := s.prepareCreate(tx, blog, baseSlug, nil)
+		if err != nil {
+			return err
+		}
+		return applyWrites(tx, writes)
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return blog, nil
+}
+
+// Update applies a change to a blog in a transaction and saves the result as a new
+// revision. An error returned by change aborts the transaction and is returned as is.
+func (s *BlogStore) Update(ctx context.Context, id int, change BlogChange) (*models.Blog, error) {
+	var blog *models.Blog
+	err := firebase.FirestoreClient.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		var writes []txWrite
+		var err error
+		if blog, writes, err = s.prepareUpdate(tx, id, change, nil); err != nil {
+			return err
+		}
+		return applyWrites(tx, writes)
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return blog, nil
+}
+
+// PublishDue publishes every unpublished blog whose PublishAt is not after now and
+// returns the blogs it published. Each blog is flipped in its own transaction that
+// re-checks the schedule, so concurrent calls from several instances publish a blog
+// (and save its revision) only once.
+func (s *BlogStore) PublishDue(ctx context.Context, now time.Time) ([]*models.Blog, error) {
+	docs, err := firebase.FirestoreClient.Collection(s.collection).
+		Where(blogFieldPaths["published"], "==", false).
+		Where("PublishAt", "<=", now).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	published := []*models.Blog{}
+	for _, doc := range docs {
+		id, err := strconv.Atoi(doc.Ref.ID)
+		if err != nil {
+			continue
+		}
+
+		blog, err := s.Update(ctx, id, func(blog *models.Blog) error {
+			return publishIfDue(blog, now)
+		})
+		if errors.Is(err, errNotDue) || errors.Is(err, ErrNotFound) {
+			// Published by another instance, rescheduled or deleted in the meantime
+			continue
+		}
+		if err != nil {
+			return published, err
+		}
+		published = append(published, blog)
+	}
+	return published, nil
+}
+
+// prepareCreate reads what creating blog (whose ID is set) needs in tx and returns
+// its writes: the slug reservation, the blog and its first revision. When the base
+// slug is taken by another blog, a numeric suffix is appended. pending holds the
+// slugs reserved by earlier operations of a batch, nil otherwise, and receives the
+// slug of the blog.
+func (s *BlogStore) prepareCreate(tx *firestore.Transaction, blog *models.Blog, base string, pending map[string]int) ([]txWrite, error) {
+	slug, reserved, err := s.findSlug(tx, base, blog.ID, pending)
+	if err != nil {
+		return nil, err
+	}
+	blog.Slug = slug
 
+	// Create fails with AlreadyExists instead of overwriting another blog or slug
+	var writes []txWrite
+	if !reserved {
+		writes = append(writes, reserveSlug(slug, blog.ID, pending))
+	}
 	docRef := firebase.FirestoreClient.Collection(s.collection).Doc(strconv.Itoa(blog.ID))
-	_, err = docRef.Set(s.ctx, blog)
+	return append(writes,
+		func(tx *firestore.Transaction) error { return tx.Create(docRef, blog) },
+		func(tx *firestore.Transaction) error {
+			return tx.Create(revisionRef(docRef, blog.Revision), newRevision(blog))
+		},
+	), nil
+}
+
+// prepareUpdate reads the blog id in tx, applies a change to it and returns the
+// result with the writes saving it as a new revision. A changed slug is made unique
+// and the old slug is kept reserved as a previous slug. An error returned by apply
+// is returned as is. pending is used as in prepareCreate.
+func (s *BlogStore) prepareUpdate(tx *firestore.Transaction, id int, apply BlogChange, pending map[string]int) (*models.Blog, []txWrite, error) {
+	docRef := firebase.FirestoreClient.Collection(s.collection).Doc(strconv.Itoa(id))
+	doc, err := tx.Get(docRef)
 	if err != nil {
+		return nil, nil, err
+	}
+
+	blog := &models.Blog{}
+	if err := doc.DataTo(blog); err != nil {
+		return nil, nil, err
+	}
+	blog.ID = id
+
+	// Blogs created before revision history keep their current state as revision 1
+	var writes []txWrite
+	if blog.Revision == 0 {
+		blog.Revision = 1
+		initialRevision := newRevision(blog)
+		writes = append(writes, func(tx *firestore.Transaction) error {
+			return tx.Create(revisionRef(docRef, initialRevision.Revision), initialRevision)
+		})
+	}
+
+	oldSlug := blog.Slug
+	if err := apply(blog); err != nil {
+		return nil, nil, err
+	}
+
+	if blog.Slug != oldSlug {
+		slug, reserved, err := s.findSlug(tx, blog.Slug, id, pending)
+		if err != nil {
+			return nil, nil, err
+		}
+		blog.Slug = slug
+		if !reserved {
+			writes = append(writes, reserveSlug(slug, id, pending))
+		}
+
+		// Blogs created before slug reservations do not own their old slug yet
+		if oldSlug != "" && oldSlug != slug {
+			if _, err := tx.Get(slugRef(oldSlug)); status.Code(err) == codes.NotFound {
+				writes = append(writes, reserveSlug(oldSlug, id, pending))
+			} else if err != nil {
+				return nil, nil, err
+			}
+		}
+		retireSlug(blog, oldSlug)
+	}
+
+	blog.UpdatedAt = time.Now()
+	blog.Revision++
+	return blog, append(writes,
+		func(tx *firestore.Transaction) error { return tx.Set(docRef, blog) },
+		func(tx *firestore.Transaction) error {
+			return tx.Create(revisionRef(docRef, blog.Revision), newRevision(blog))
+		},
+	), nil
+}
+
+// MergeTags replaces the tags in from with to on every blog, saves a revision of each
+// changed blog and returns the changed blogs. Blogs are rewritten with batched writes
+// of tagMergeBatchSize blogs; a blog modified concurrently fails its batch with
+// ErrConflict, and the blogs of batches committed before an error are still returned.
+func (s *BlogStore) MergeTags(ctx context.Context, from []string, to string, updatedBy string) ([]*models.Blog, error) {
+	client := firebase.FirestoreClient
+	collection := client.Collection(s.collection)
+
+	// array-contains-any accepts a limited number of values, so from is queried in chunks
+	docs := make(map[string]*firestore.DocumentSnapshot)
+	for start := 0; start < len(from); start += tagQueryLimit {
+		chunk := from[start:min(start+tagQueryLimit, len(from))]
+		found, err := collection.Where(blogFieldPaths["tags"], "array-contains-any", chunk).Documents(ctx).GetAll()
+		if err != nil {
+			return nil, translateError(err)
+		}
+		for _, doc := range found {
+			docs[doc.Ref.ID] = doc
+		}
+	}
+
+	fromSet := tagSet(from)
+	now := time.Now()
+	merged := []*models.Blog{}
+	var pending []*models.Blog
+	batch := client.Batch()
+	commit := func() error {
+		if len(pending) == 0 {
+			return nil
+		}
+		if _, err := batch.Commit(ctx); err != nil {
+			return translateError(err)
+		}
+		merged = append(merged, pending...)
+		pending = nil
+		batch = client.Batch()
 		return nil
 	}
 
-	return blog
+	for _, doc := range docs {
+		id, err := strconv.Atoi(doc.Ref.ID)
+		if err != nil {
+			continue
+		}
+		blog := &models.Blog{}
+		if err := doc.DataTo(blog); err != nil {
+			return merged, err
+		}
+		blog.ID = id
+
+		tags, changed := replaceTags(blog.Tags, fromSet, to)
+		if !changed {
+			continue
+		}
+
+		// Blogs created before revision history keep their current state as revision 1
+		if blog.Revision == 0 {
+			blog.Revision = 1
+			batch.Create(revisionRef(doc.Ref, blog.Revision), newRevision(blog))
+		}
+		blog.Tags = tags
+		blog.UpdatedBy = updatedBy
+		blog.UpdatedAt = now
+		blog.Revision++
+		batch.Update(doc.Ref, []firestore.Update{
+			{Path: blogFieldPaths["tags"], Value: blog.Tags},
+			{Path: "UpdatedBy", Value: blog.UpdatedBy},
+			{Path: blogFieldPaths["updated_at"], Value: blog.UpdatedAt},
+			{Path: "Revision", Value: blog.Revision},
+		}, firestore.LastUpdateTime(doc.UpdateTime))
+		batch.Create(revisionRef(doc.Ref, blog.Revision), newRevision(blog))
+
+		pending = append(pending, blog)
+		if len(pending) == tagMergeBatchSize {
+			if err := commit(); err != nil {
+				return merged, err
+			}
+		}
+	}
+	if err := commit(); err != nil {
+		return merged, err
+	}
+	return merged, nil
 }
 
-// Update updates an existing blog
-func (s *BlogStore) Update(id int, updatedBlog *models.Blog) (*models.Blog, bool) {
+// Delete deletes a blog by ID together with its revisions, and releases its current
+// and previous slugs
+func (s *BlogStore) Delete(ctx context.Context, id int) error {
+	err := firebase.FirestoreClient.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		writes, err := s.prepareDelete(tx, id)
+		if err != nil {
+			return err
+		}
+		return applyWrites(tx, writes)
+	})
+	if err != nil {
+		return translateError(err)
+	}
+	s.purgeRevisions(ctx, id)
+	return nil
+}
+
+// prepareDelete reads the blog id in tx and returns the writes deleting it and the
+// slugs still reserved for it. A blog can have more revisions than a transaction can
+// write, so they are left to purgeRevisions.
+func (s *BlogStore) prepareDelete(tx *firestore.Transaction, id int) ([]txWrite, error) {
 	docRef := firebase.FirestoreClient.Collection(s.collection).Doc(strconv.Itoa(id))
-	doc, err := docRef.Get(s.ctx)
+	doc, err := tx.Get(docRef)
 	if err != nil {
-		return nil, false
+		return nil, err
+	}
+	blog := &models.Blog{}
+	if err := doc.DataTo(blog); err != nil {
+		return nil, err
 	}
 
-	existingBlog := &models.Blog{}
-	if err := doc.DataTo(existingBlog); err != nil {
-		return nil, false
+	var refs []*firestore.DocumentRef
+	// Only release slugs that are still reserved for this blog
+	for _, slug := range append([]string{blog.Slug}, blog.PreviousSlugs...) {
+		if slug == "" {
+			continue
+		}
+		slugDoc, err := tx.Get(slugRef(slug))
+		if status.Code(err) == codes.NotFound {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		var reservation slugReservation
+		if err := slugDoc.DataTo(&reservation); err == nil && reservation.BlogID == id {
+			refs = append(refs, slugDoc.Ref)
+		}
 	}
 
-	// Merge updates
-	if updatedBlog.Title != "" {
-		existingBlog.Title = updatedBlog.Title
+	writes := make([]txWrite, 0, len(refs)+1)
+	for _, ref := range append(refs, docRef) {
+		ref := ref
+		writes = append(writes, func(tx *firestore.Transaction) error { return tx.Delete(ref) })
 	}
-	if updatedBlog.Content != "" {
-		existingBlog.Content = updatedBlog.Content
+	return writes, nil
+}
+
+// Batch applies the operations of a blog batch in one transaction and returns one
+// result per operation. Every operation is checked before the first write: an atomic
+// batch with a failed operation writes nothing, otherwise the failed operations are
+// skipped. Slugs released by deletes are only free after the batch.
+func (s *BlogStore) Batch(ctx context.Context, ops []BlogBatchOp, atomic bool) ([]BlogBatchResult, error) {
+	if err := checkBatchSize(len(ops)); err != nil {
+		return nil, err
 	}
-	if updatedBlog.Slug != "" {
-		existingBlog.Slug = updatedBlog.Slug
+
+	creates := 0
+	for _, op := range ops {
+		if op.Type == models.BatchCreate {
+			creates++
+		}
 	}
-	if updatedBlog.Author != "" {
-		existingBlog.Author = updatedBlog.Author
+	var firstID int
+	if creates > 0 {
+		var err error
+		if firstID, err = s.ids.NextIDs(ctx, s.collection, creates); err != nil {
+			return nil, err
+		}
 	}
-	existingBlog.Published = updatedBlog.Published
-	if updatedBlog.Tags != nil {
-		existingBlog.Tags = updatedBlog.Tags
+
+	var results []BlogBatchResult
+	err := firebase.FirestoreClient.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		results = make([]BlogBatchResult, len(ops))
+		writes := make([][]txWrite, len(ops))
+		targeted := make(map[int]bool)
+		pending := make(map[string]int)
+		nextID := firstID
+		now := time.Now()
+		for i, op := range ops {
+			if err := checkBatchTarget(op.Type, op.ID, targeted); err != nil {
+				results[i].Err = err
+				continue
+			}
+
+			var err error
+			switch op.Type {
+			case models.BatchCreate:
+				// A copy keeps the requested slug for retried transactions
+				blog := *op.Blog
+				blog.ID = nextID
+				nextID++
+				blog.CreatedAt = now
+				blog.UpdatedAt = now
+				blog.Revision = 1
+				writes[i], err = s.prepareCreate(tx, &blog, op.Blog.Slug, pending)
+				results[i].Blog = &blog
+			case models.BatchUpdate:
+				var changeErr error
+				results[i].Blog, writes[i], err = s.prepareUpdate(tx, op.ID, func(blog *models.Blog) error {
+					changeErr = op.Change(blog)
+					return changeErr
+				}, pending)
+				// A rejected change fails its operation instead of the transaction
+				if changeErr != nil {
+					results[i] = BlogBatchResult{Err: changeErr}
+					continue
+				}
+			case models.BatchDelete:
+				writes[i], err = s.prepareDelete(tx, op.ID)
+			}
+			// Missing blogs fail their operation instead of the transaction
+			if status.Code(err) == codes.NotFound {
+				err = ErrNotFound
+			}
+			if err != nil && (errors.Is(err, ErrNotFound) || errors.Is(err, ErrConflict)) {
+				results[i] = BlogBatchResult{Err: err}
+			} else if err != nil {
+				return err
+			}
+		}
+		if abortBlogBatch(results, atomic) {
+			return nil
+		}
+
+		var all []txWrite
+		for i := range ops {
+			if results[i].Err == nil {
+				all = append(all, writes[i]...)
+			}
+		}
+		if err := checkBatchWrites(len(all)); err != nil {
+			return err
+		}
+		return applyWrites(tx, all)
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	for i, op := range ops {
+		if op.Type == models.BatchDelete && results[i].Err == nil {
+			s.purgeRevisions(ctx, op.ID)
+		}
 	}
-	existingBlog.UpdatedAt = time.Now()
+	return results, nil
+}
 
-	_, err = docRef.Set(s.ctx, existingBlog)
+// purgeRevisions deletes the revisions of a deleted blog in batches. Revisions are
+// only reachable through their blog, so a failed purge merely leaves unreachable
+// documents behind and is logged instead of failing the deletion.
+func (s *BlogStore) purgeRevisions(ctx context.Context, id int) {
+	docRef := firebase.FirestoreClient.Collection(s.collection).Doc(strconv.Itoa(id))
+	revisions, err := docRef.Collection(revisionsCollection).Select().Documents(ctx).GetAll()
+	if err == nil {
+		err = commitBatches(ctx, len(revisions), func(batch *firestore.WriteBatch, i int) {
+			batch.Delete(revisions[i].Ref)
+		})
+	}
 	if err != nil {
-		return nil, false
+		log.Printf("Failed to delete revisions of blog %d: %v", id, err)
 	}
+}
 
-	existingBlog.ID = id
-	return existingBlog, true
+// ListRevisions returns the revisions of a blog, newest first
+func (s *BlogStore) ListRevisions(ctx context.Context, id int) ([]*models.BlogRevision, error) {
+	docRef := firebase.FirestoreClient.Collection(s.collection).Doc(strconv.Itoa(id))
+	docs, err := docRef.Collection(revisionsCollection).
+		OrderBy("Revision", firestore.Desc).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	revisions := make([]*models.BlogRevision, 0, len(docs))
+	for _, doc := range docs {
+		revision := &models.BlogRevision{}
+		if err := doc.DataTo(revision); err != nil {
+			continue
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, nil
 }
 
-// Delete deletes a blog by ID
-func (s *BlogStore) Delete(id int) bool {
+// GetRevision returns one revision of a blog
+func (s *BlogStore) GetRevision(ctx context.Context, id int, rev int) (*models.BlogRevision, error) {
 	docRef := firebase.FirestoreClient.Collection(s.collection).Doc(strconv.Itoa(id))
-	_, err := docRef.Get(s.ctx)
+	doc, err := revisionRef(docRef, rev).Get(ctx)
 	if err != nil {
-		return false
+		return nil, translateError(err)
 	}
 
-	_, err = docRef.Delete(s.ctx)
-	return err == nil
+	revision := &models.BlogRevision{}
+	if err := doc.DataTo(revision); err != nil {
+		return nil, err
+	}
+	return revision, nil
+}
+
+// revisionRef returns the document of revision rev in the revisions subcollection of a blog
+func revisionRef(blogRef *firestore.DocumentRef, rev int) *firestore.DocumentRef {
+	return blogRef.Collection(revisionsCollection).Doc(strconv.Itoa(rev))
+}
+
+// slugRef returns the reservation document of a slug
+func slugRef(slug string) *firestore.DocumentRef {
+	return firebase.FirestoreClient.Collection(slugsCollection).Doc(slug)
 }
 
+// findSlug returns the first candidate for base that is free or already reserved
+// for blogID, and whether it is already reserved. Blogs created before slug
+// reservations are checked by their Slug field, and slugs in pending are taken.
+func (s *BlogStore) findSlug(tx *firestore.Transaction, base string, blogID int, pending map[string]int) (string, bool, error) {
+	collection := firebase.FirestoreClient.Collection(s.collection)
+	for n := 1; n <= maxSlugAttempts; n++ {
+		candidate := slugCandidate(base, n)
+		if id, taken := pending[candidate]; taken && id != blogID {
+			continue
+		}
+
+		doc, err := tx.Get(slugRef(candidate))
+		if err == nil {
+			var reservation slugReservation
+			if err := doc.DataTo(&reservation); err != nil {
+				return "", false, err
+			}
+			if reservation.BlogID == blogID {
+				return candidate, true, nil
+			}
+			continue
+		}
+		if status.Code(err) != codes.NotFound {
+			return "", false, err
+		}
+
+		docs, err := tx.Documents(collection.Where(blogFieldPaths["slug"], "==", candidate).Limit(1)).GetAll()
+		if err != nil {
+			return "", false, err
+		}
+		if len(docs) == 0 || docs[0].Ref.ID == strconv.Itoa(blogID) {
+			return candidate, false, nil
+		}
+	}
+	return "", false, errNoFreeSlug(base)
+}
+
+// reserveSlug returns the write reserving slug for blogID, and records it in pending
+// when it is not nil
+func reserveSlug(slug string, blogID int, pending map[string]int) txWrite {
+	if pending != nil {
+		pending[slug] = blogID
+	}
+	return func(tx *firestore.Transaction) error {
+		return tx.Create(slugRef(slug), slugReservation{BlogID: blogID})
+	}
+}
+
+// applyWrites applies the writes prepared in a transaction, in order
+func applyWrites(tx *firestore.Transaction, writes []txWrite) error {
+	for _, write := range writes {
+		if err := write(tx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
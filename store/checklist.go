@@ -0,0 +1,91 @@
+package store
+
+import (
+	"apigo1/models"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// itemsCollection is the subcollection holding the checklist items of a todo (todos/{id}/items)
+const itemsCollection = "items"
+
+// sortItems orders checklist items by position
+func sortItems(items []*models.ChecklistItem) {
+	sort.Slice(items, func(i, j int) bool {
+		if items[i].Position != items[j].Position {
+			return items[i].Position < items[j].Position
+		}
+		return items[i].ID < items[j].ID
+	})
+}
+
+// nextItemPosition returns the position after the last item
+func nextItemPosition(items []*models.ChecklistItem) int {
+	position := 0
+	for _, item := range items {
+		if item.Position >= position {
+			position = item.Position + 1
+		}
+	}
+	return position
+}
+
+// mergeItem copies the non-empty Title of updatedItem into item; Done is always taken
+func mergeItem(item, updatedItem *models.ChecklistItem) {
+	if updatedItem.Title != "" {
+		item.Title = updatedItem.Title
+	}
+	item.Done = updatedItem.Done
+	item.UpdatedAt = updatedItem.UpdatedAt
+}
+
+// applyProgress recomputes the progress fields of a todo from its checklist items
+// and completes it when it auto-completes and every item is done
+func applyProgress(todo *models.Todo, items []*models.ChecklistItem, now time.Time) {
+	todo.ItemCount = len(items)
+	todo.ItemsDone = 0
+	for _, item := range items {
+		if item.Done {
+			todo.ItemsDone++
+		}
+	}
+	completeIfDone(todo, now)
+}
+
+// completeIfDone updates the progress percentage of a todo and completes it when
+// it auto-completes and every checklist item is done. Auto-completed todos are not
+// reopened when items are added or unchecked later.
+func completeIfDone(todo *models.Todo, now time.Time) {
+	todo.Progress = 0
+	if todo.ItemCount > 0 {
+		todo.Progress = todo.ItemsDone * 100 / todo.ItemCount
+	}
+
+	if todo.AutoComplete && !todo.Completed && todo.ItemCount > 0 && todo.ItemsDone == todo.ItemCount {
+		todo.Completed = true
+		todo.CompletedAt = &now
+	}
+}
+
+// reorderItems sets the positions of items to the order of itemIDs, which must list
+// every item exactly once
+func reorderItems(items []*models.ChecklistItem, itemIDs []int) error {
+	byID := make(map[int]*models.ChecklistItem, len(items))
+	for _, item := range items {
+		byID[item.ID] = item
+	}
+	if len(itemIDs) != len(items) {
+		return fmt.Errorf("%w: the checklist changed, reload it and retry", ErrConflict)
+	}
+	for position, id := range itemIDs {
+		item, ok := byID[id]
+		if !ok {
+			return fmt.Errorf("%w: the checklist changed, reload it and retry", ErrConflict)
+		}
+		item.Position = position
+		delete(byID, id)
+	}
+	sortItems(items)
+	return nil
+}
@@ -0,0 +1,19 @@
+package store
+
+import (
+	"apigo1/models"
+	"sort"
+)
+
+// commentsCollection is the subcollection holding the comments of a blog (blogs/{id}/comments)
+const commentsCollection = "comments"
+
+// sortComments orders comments oldest first
+func sortComments(comments []*models.Comment) {
+	sort.Slice(comments, func(i, j int) bool {
+		if !comments[i].CreatedAt.Equal(comments[j].CreatedAt) {
+			return comments[i].CreatedAt.Before(comments[j].CreatedAt)
+		}
+		return comments[i].ID < comments[j].ID
+	})
+}
@@ -0,0 +1,172 @@
+package store
+
+import (
+	"apigo1/firebase"
+	"apigo1/models"
+	"context"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// CommentStore manages comments in Firestore, in the subcollection blogs/{id}/comments
+type CommentStore struct {
+	blogs string
+	ids   IDAllocator
+}
+
+// NewCommentStore creates a new CommentStore
+func NewCommentStore(ids IDAllocator) *CommentStore {
+	return &CommentStore{
+		blogs: "blogs",
+		ids:   ids,
+	}
+}
+
+// ListByBlog returns every comment of a blog, whatever its status, oldest first
+func (s *CommentStore) ListByBlog(ctx context.Context, blogID int) ([]*models.Comment, error) {
+	docs, err := s.collection(blogID).OrderBy("CreatedAt", firestore.Asc).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return commentsFromDocs(docs), nil
+}
+
+// ListByStatus returns up to limit comments with the given status across all blogs,
+// oldest first. It runs a collection group query over every comments subcollection,
+// which needs a composite index on (Status, CreatedAt).
+func (s *CommentStore) ListByStatus(ctx context.Context, status models.CommentStatus, limit int) ([]*models.Comment, error) {
+	docs, err := firebase.FirestoreClient.CollectionGroup(commentsCollection).
+		Where("Status", "==", string(status)).
+		OrderBy("CreatedAt", firestore.Asc).
+		Limit(limit).
+		Documents(ctx).
+		GetAll()
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return commentsFromDocs(docs), nil
+}
+
+// GetByID returns a comment of a blog by ID
+func (s *CommentStore) GetByID(ctx context.Context, blogID int, id int) (*models.Comment, error) {
+	doc, err := s.collection(blogID).Doc(strconv.Itoa(id)).Get(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return commentFromDoc(doc)
+}
+
+// Create creates a new comment
+func (s *CommentStore) Create(ctx context.Context, comment *models.Comment) (*models.Comment, error) {
+	id, err := s.ids.NextID(ctx, commentsCollection)
+	if err != nil {
+		return nil, err
+	}
+	comment.ID = id
+
+	now := time.Now()
+	comment.CreatedAt = now
+	comment.UpdatedAt = now
+
+	// Create fails with AlreadyExists instead of overwriting another comment
+	docRef := s.collection(comment.BlogID).Doc(strconv.Itoa(comment.ID))
+	if _, err := docRef.Create(ctx, comment); err != nil {
+		return nil, translateError(err)
+	}
+
+	return comment, nil
+}
+
+// SetStatus changes the moderation status of a comment
+func (s *CommentStore) SetStatus(ctx context.Context, blogID int, id int, status models.CommentStatus) (*models.Comment, error) {
+	client := firebase.FirestoreClient
+	docRef := s.collection(blogID).Doc(strconv.Itoa(id))
+
+	var comment *models.Comment
+	err := client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(docRef)
+		if err != nil {
+			return err
+		}
+
+		comment, err = commentFromDoc(doc)
+		if err != nil {
+			return err
+		}
+		comment.Status = status
+		comment.UpdatedAt = time.Now()
+
+		return tx.Set(docRef, comment)
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	return comment, nil
+}
+
+// Delete deletes a comment together with its replies
+func (s *CommentStore) Delete(ctx context.Context, blogID int, id int) error {
+	client := firebase.FirestoreClient
+	collection := s.collection(blogID)
+	docRef := collection.Doc(strconv.Itoa(id))
+
+	err := client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		if _, err := tx.Get(docRef); err != nil {
+			return err
+		}
+		replies, err := tx.Documents(collection.Where("ParentID", "==", id).Select()).GetAll()
+		if err != nil {
+			return err
+		}
+
+		for _, reply := range replies {
+			if err := tx.Delete(reply.Ref); err != nil {
+				return err
+			}
+		}
+		return tx.Delete(docRef)
+	})
+	return translateError(err)
+}
+
+// DeleteByBlog deletes every comment of a blog, in batches
+func (s *CommentStore) DeleteByBlog(ctx context.Context, blogID int) error {
+	docs, err := s.collection(blogID).Select().Documents(ctx).GetAll()
+	if err != nil {
+		return translateError(err)
+	}
+
+	return commitBatches(ctx, len(docs), func(batch *firestore.WriteBatch, i int) {
+		batch.Delete(docs[i].Ref)
+	})
+}
+
+// collection returns the comments subcollection of a blog
+func (s *CommentStore) collection(blogID int) *firestore.CollectionRef {
+	return firebase.FirestoreClient.Collection(s.blogs).Doc(strconv.Itoa(blogID)).Collection(commentsCollection)
+}
+
+// commentFromDoc decodes a comment document
+func commentFromDoc(doc *firestore.DocumentSnapshot) (*models.Comment, error) {
+	comment := &models.Comment{}
+	if err := doc.DataTo(comment); err != nil {
+		return nil, err
+	}
+	return comment, nil
+}
+
+// commentsFromDocs decodes comment documents, skipping malformed ones
+func commentsFromDocs(docs []*firestore.DocumentSnapshot) []*models.Comment {
+	comments := make([]*models.Comment, 0, len(docs))
+	for _, doc := range docs {
+		comment, err := commentFromDoc(doc)
+		if err != nil {
+			continue
+		}
+		comments = append(comments, comment)
+	}
+	return comments
+}
@@ -0,0 +1,42 @@
+package store
+
+import (
+	"errors"
+	"fmt"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Errors returned by store implementations. Callers should compare with errors.Is,
+// since backend errors are wrapped to keep the original cause.
+var (
+	// ErrNotFound is returned when the requested item does not exist
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when a write conflicts with the current state
+	ErrConflict = errors.New("conflict")
+	// ErrUnavailable is returned when the storage backend cannot be reached
+	ErrUnavailable = errors.New("store unavailable")
+	// ErrBatchAborted is the result of the operations of an atomic batch that were
+	// not applied because another operation failed
+	ErrBatchAborted = errors.New("batch aborted")
+	// ErrBatchTooLarge is returned when a batch needs more writes than one transaction allows
+	ErrBatchTooLarge = errors.New("batch too large")
+)
+
+// translateError maps a Firestore (gRPC) error to one of the store errors
+func translateError(err error) error {
+	if err == nil {
+		return nil
+	}
+
+	switch status.Code(err) {
+	case codes.NotFound:
+		return fmt.Errorf("%w: %v", ErrNotFound, err)
+	case codes.AlreadyExists, codes.Aborted, codes.FailedPrecondition:
+		return fmt.Errorf("%w: %v", ErrConflict, err)
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+		return fmt.Errorf("%w: %v", ErrUnavailable, err)
+	}
+	return err
+}
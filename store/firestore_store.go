@@ -4,141 +4,1039 @@ import (
 	"apigo1/firebase"
 	"apigo1/models"
 	"context"
+	"errors"
 	"strconv"
 	"time"
 
+	"cloud.google.com/go/firestore"
 	"google.golang.org/api/iterator"
 )
 
+// errIDsNeeded aborts a transaction that creates more todos than it has IDs reserved
+// for, since IDs cannot be allocated inside it
+var errIDsNeeded = errors.New("more todo IDs must be reserved")
+
 // FirestoreStore manages todos in Firestore
 type FirestoreStore struct {
 	collection string
-	ctx        context.Context
+	lists      string
+	ids        IDAllocator
 }
 
 // NewFirestoreStore creates a new FirestoreStore
-func NewFirestoreStore(ctx context.Context) *FirestoreStore {
+func NewFirestoreStore(ids IDAllocator) *FirestoreStore {
 	return &FirestoreStore{
 		collection: "todos",
-		ctx:        ctx,
+		lists:      listsCollection,
+		ids:        ids,
 	}
 }
 
-// GetAll returns all todos
-func (s *FirestoreStore) GetAll() []*models.Todo {
-	var todos []*models.Todo
+// GetAll returns all todos owned by ownerID, ordered by position
+func (s *FirestoreStore) GetAll(ctx context.Context, ownerID string) ([]*models.Todo, error) {
+	todos := []*models.Todo{}
 
-	iter := firebase.FirestoreClient.Collection(s.collection).Documents(s.ctx)
+	iter := firebase.FirestoreClient.Collection(s.collection).
+		Where(todoFieldPaths["owner_id"], "==", ownerID).
+		Documents(ctx)
+	defer iter.Stop()
 	for {
 		doc, err := iter.Next()
 		if err == iterator.Done {
 			break
 		}
 		if err != nil {
-			return todos
+			return nil, translateError(err)
 		}
 
-		todo := &models.Todo{}
-		if err := doc.DataTo(todo); err != nil {
+		todo, err := todoFromDoc(doc)
+		if err != nil {
 			continue
 		}
-		// Parse ID from document ID
-		if id, err := strconv.Atoi(doc.Ref.ID); err == nil {
-			todo.ID = id
+		todos = append(todos, todo)
+	}
+
+	sortTodos(todos)
+	return todos, nil
+}
+
+// GetAllOwners returns the todos of every owner, leaving out todos without one
+func (s *FirestoreStore) GetAllOwners(ctx context.Context) ([]*models.Todo, error) {
+	docs, err := firebase.FirestoreClient.Collection(s.collection).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	todos := make([]*models.Todo, 0, len(docs))
+	for _, doc := range docs {
+		todo, err := todoFromDoc(doc)
+		if err != nil || todo.OwnerID == "" {
+			continue
 		}
 		todos = append(todos, todo)
 	}
+	return todos, nil
+}
+
+// List returns one page of todos owned by ownerID and matching opts, ordered by
+// position unless opts.Sort is set, together with the cursor of the next page ("" on the last page)
+func (s *FirestoreStore) List(ctx context.Context, ownerID string, opts ListOptions) ([]*models.Todo, string, error) {
+	collection := firebase.FirestoreClient.Collection(s.collection)
+	query, err := applyFirestoreQuery(collection.Query, todoFieldPaths, withOwner(opts, ownerID), todoDefaultSort)
+	if err != nil {
+		return nil, "", err
+	}
 
-	return todos
+	docs, nextCursor, err := firestorePage(ctx, collection, query, opts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	todos := make([]*models.Todo, 0, len(docs))
+	for _, doc := range docs {
+		todo, err := todoFromDoc(doc)
+		if err != nil {
+			continue
+		}
+		todos = append(todos, todo)
+	}
+
+	return todos, nextCursor, nil
 }
 
 // GetByID returns a todo by ID
-func (s *FirestoreStore) GetByID(id int) (*models.Todo, bool) {
+func (s *FirestoreStore) GetByID(ctx context.Context, ownerID string, id int) (*models.Todo, error) {
 	docRef := firebase.FirestoreClient.Collection(s.collection).Doc(strconv.Itoa(id))
-	doc, err := docRef.Get(s.ctx)
+	doc, err := docRef.Get(ctx)
 	if err != nil {
-		return nil, false
+		return nil, translateError(err)
 	}
 
-	todo := &models.Todo{}
-	if err := doc.DataTo(todo); err != nil {
-		return nil, false
+	todo, err := todoFromDoc(doc)
+	if err != nil {
+		return nil, err
 	}
-	todo.ID = id
-	return todo, true
+	// Other users' todos are reported as missing so their existence does not leak
+	if todo.OwnerID != ownerID {
+		return nil, ErrNotFound
+	}
+	return todo, nil
 }
 
 // Create creates a new todo
-func (s *FirestoreStore) Create(todo *models.Todo) *models.Todo {
-	// Get the next ID by counting documents
-	docs, err := firebase.FirestoreClient.Collection(s.collection).Documents(s.ctx).GetAll()
+func (s *FirestoreStore) Create(ctx context.Context, todo *models.Todo) (*models.Todo, error) {
+	id, err := s.ids.NextID(ctx, s.collection)
 	if err != nil {
-		// Fallback: use timestamp as ID
-		todo.ID = int(time.Now().Unix())
-	} else {
-		maxID := 0
-		for _, doc := range docs {
-			if id, err := strconv.Atoi(doc.Ref.ID); err == nil && id > maxID {
-				maxID = id
-			}
-		}
-		todo.ID = maxID + 1
+		return nil, err
 	}
+	todo.ID = id
+	prepareTodo(todo)
 
 	now := time.Now()
 	todo.CreatedAt = now
 	todo.UpdatedAt = now
 
-	docRef := firebase.FirestoreClient.Collection(s.collection).Doc(strconv.Itoa(todo.ID))
-	_, err = docRef.Set(s.ctx, todo)
+	// The todo is appended in a transaction so that concurrent creates do not get
+	// the same position. Create fails with AlreadyExists instead of overwriting
+	// another todo.
+	client := firebase.FirestoreClient
+	docRef := client.Collection(s.collection).Doc(strconv.Itoa(todo.ID))
+	err = client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		last, err := s.neighborPosition(tx, todo.OwnerID, todo.ID, "", "")
+		if err != nil {
+			return err
+		}
+		if todo.Position, err = positionBetween(last, ""); err != nil {
+			return err
+		}
+		return tx.Create(docRef, todo)
+	})
 	if err != nil {
-		return nil
+		return nil, translateError(err)
 	}
 
-	return todo
+	return todo, nil
 }
 
-// Update updates an existing todo
-func (s *FirestoreStore) Update(id int, updatedTodo *models.Todo) (*models.Todo, bool) {
-	docRef := firebase.FirestoreClient.Collection(s.collection).Doc(strconv.Itoa(id))
-	doc, err := docRef.Get(s.ctx)
+// Update applies a change to an existing todo in a transaction and returns it with
+// the next occurrence it creates, if any. An error returned by change aborts the
+// transaction and is returned as is.
+// The ID of a next occurrence cannot be allocated inside the transaction: when the
+// change completes a recurring todo, the transaction is aborted, an ID is reserved
+// and the update runs again. The ID is left unused when the todo is no longer
+// completed by then.
+func (s *FirestoreStore) Update(ctx context.Context, ownerID string, id int, change TodoChange) (*models.Todo, *models.Todo, error) {
+	todo, next, err := s.update(ctx, ownerID, id, change, 0)
+	if errors.Is(err, errIDsNeeded) {
+		var nextID int
+		if nextID, err = s.ids.NextID(ctx, s.collection); err != nil {
+			return nil, nil, err
+		}
+		todo, next, err = s.update(ctx, ownerID, id, change, nextID)
+	}
 	if err != nil {
-		return nil, false
+		return nil, nil, translateError(err)
 	}
+	return todo, next, nil
+}
+
+// update runs the transaction of Update with nextID, 0 when no ID is reserved, as
+// the ID of the next occurrence. It fails with errIDsNeeded when it needs an ID.
+func (s *FirestoreStore) update(ctx context.Context, ownerID string, id int, change TodoChange, nextID int) (*models.Todo, *models.Todo, error) {
+	client := firebase.FirestoreClient
+	docRef := client.Collection(s.collection).Doc(strconv.Itoa(id))
+
+	var todo, next *models.Todo
+	err := client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		next = nil
+		doc, err := tx.Get(docRef)
+		if err != nil {
+			return err
+		}
+
+		todo, err = todoFromDoc(doc)
+		if err != nil {
+			return err
+		}
+		if todo.OwnerID != ownerID {
+			return ErrNotFound
+		}
+
+		wasCompleted := todo.Completed
+		if err := applyTodoChange(todo, change, time.Now()); err != nil {
+			return err
+		}
 
-	existingTodo := &models.Todo{}
-	if err := doc.DataTo(existingTodo); err != nil {
-		return nil, false
+		// Completing an occurrence of a recurring todo creates the next one
+		if next = nextTodo(todo, wasCompleted); next != nil {
+			if nextID == 0 {
+				return errIDsNeeded
+			}
+			last, err := s.neighborPosition(tx, ownerID, 0, "", "")
+			if err != nil {
+				return err
+			}
+			if next.Position, err = positionBetween(last, ""); err != nil {
+				return err
+			}
+			next.ID = nextID
+			next.CreatedAt = todo.UpdatedAt
+			next.UpdatedAt = todo.UpdatedAt
+			nextRef := client.Collection(s.collection).Doc(strconv.Itoa(nextID))
+			if err := tx.Create(nextRef, next); err != nil {
+				return err
+			}
+			todo.NextTodoID = nextID
+		}
+
+		return tx.Set(docRef, todo)
+	})
+	if err != nil {
+		return nil, nil, err
 	}
+	return todo, next, nil
+}
 
-	// Merge updates
-	if updatedTodo.Title != "" {
-		existingTodo.Title = updatedTodo.Title
+// Move moves a todo right after the todo afterID and/or right before the todo
+// beforeID (0 when not given), or to the end without anchors. When both are given,
+// afterID must be right before beforeID. Only the moved todo is written; the
+// transaction reads its neighbours so that concurrent moves into the same gap are
+// retried instead of getting the same position.
+func (s *FirestoreStore) Move(ctx context.Context, ownerID string, id int, beforeID, afterID int) (*models.Todo, error) {
+	client := firebase.FirestoreClient
+	collection := client.Collection(s.collection)
+	docRef := collection.Doc(strconv.Itoa(id))
+
+	// anchorPosition reads the position of an anchor todo of the same owner
+	anchorPosition := func(tx *firestore.Transaction, anchorID int) (string, error) {
+		doc, err := tx.Get(collection.Doc(strconv.Itoa(anchorID)))
+		if err != nil {
+			return "", err
+		}
+		anchor, err := todoFromDoc(doc)
+		if err != nil {
+			return "", err
+		}
+		if anchor.OwnerID != ownerID {
+			return "", ErrNotFound
+		}
+		return anchor.Position, nil
 	}
-	if updatedTodo.Description != "" {
-		existingTodo.Description = updatedTodo.Description
+
+	var todo *models.Todo
+	err := client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(docRef)
+		if err != nil {
+			return err
+		}
+		todo, err = todoFromDoc(doc)
+		if err != nil {
+			return err
+		}
+		if todo.OwnerID != ownerID {
+			return ErrNotFound
+		}
+
+		var lower, upper string
+		if afterID != 0 {
+			if lower, err = anchorPosition(tx, afterID); err != nil {
+				return err
+			}
+		}
+		if beforeID != 0 {
+			if upper, err = anchorPosition(tx, beforeID); err != nil {
+				return err
+			}
+		}
+		switch {
+		case afterID != 0 && beforeID != 0:
+			var next string
+			if next, err = s.neighborPosition(tx, ownerID, id, ">", lower); err == nil && next != upper {
+				err = notAdjacentError(afterID, beforeID)
+			}
+		case afterID != 0 && beforeID == 0:
+			upper, err = s.neighborPosition(tx, ownerID, id, ">", lower)
+		case beforeID != 0 && afterID == 0:
+			lower, err = s.neighborPosition(tx, ownerID, id, "<", upper)
+		case afterID == 0 && beforeID == 0:
+			lower, err = s.neighborPosition(tx, ownerID, id, "", "")
+		}
+		if err != nil {
+			return err
+		}
+
+		if todo.Position, err = positionBetween(lower, upper); err != nil {
+			return err
+		}
+		todo.UpdatedAt = time.Now()
+		return tx.Set(docRef, todo)
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return todo, nil
+}
+
+// neighborPosition returns the position of the todo of ownerID closest to position
+// on the side given by op (">" for the next todo, "<" for the previous one), or the
+// last position when op is empty. The todo excludeID is skipped, and "" is returned
+// when there is no such todo.
+func (s *FirestoreStore) neighborPosition(tx *firestore.Transaction, ownerID string, excludeID int, op string, position string) (string, error) {
+	path := todoFieldPaths["position"]
+	query := firebase.FirestoreClient.Collection(s.collection).
+		Where(todoFieldPaths["owner_id"], "==", ownerID)
+	direction := firestore.Desc
+	if op != "" {
+		query = query.Where(path, op, position)
+		if op == ">" {
+			direction = firestore.Asc
+		}
+	}
+
+	docs, err := tx.Documents(query.OrderBy(path, direction).Limit(2)).GetAll()
+	if err != nil {
+		return "", err
+	}
+	for _, doc := range docs {
+		if doc.Ref.ID == strconv.Itoa(excludeID) {
+			continue
+		}
+		todo, err := todoFromDoc(doc)
+		if err != nil {
+			return "", err
+		}
+		return todo.Position, nil
+	}
+	return "", nil
+}
+
+// AssignMissingOwners gives the todos created before todos had an owner to
+// ownerID, appending them by position after the todos ownerID already has. With
+// an empty ownerID they are left as they are: every scoped method and GetAllOwners
+// ignore them, so they stay quarantined until an owner is given. It returns the
+// number of todos without an owner that were found.
+func (s *FirestoreStore) AssignMissingOwners(ctx context.Context, ownerID string) (int, error) {
+	docs, err := firebase.FirestoreClient.Collection(s.collection).Documents(ctx).GetAll()
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	var last string
+	var missing []*models.Todo
+	snapshots := make(map[int]*firestore.DocumentSnapshot)
+	for _, doc := range docs {
+		todo, err := todoFromDoc(doc)
+		if err != nil {
+			continue
+		}
+		if todo.OwnerID == "" {
+			missing = append(missing, todo)
+			snapshots[todo.ID] = doc
+		} else if todo.OwnerID == ownerID && todo.Position > last {
+			last = todo.Position
+		}
+	}
+	if ownerID == "" || len(missing) == 0 {
+		return len(missing), nil
+	}
+	sortTodos(missing)
+
+	for _, todo := range missing {
+		position, err := positionBetween(last, "")
+		if err != nil {
+			return 0, err
+		}
+		todo.Position = position
+		last = position
+	}
+
+	// The precondition fails the batch if a todo changed since it was read
+	err = commitBatches(ctx, len(missing), func(batch *firestore.WriteBatch, i int) {
+		doc := snapshots[missing[i].ID]
+		batch.Update(doc.Ref, []firestore.Update{
+			{Path: todoFieldPaths["owner_id"], Value: ownerID},
+			{Path: todoFieldPaths["position"], Value: missing[i].Position},
+		}, firestore.LastUpdateTime(doc.UpdateTime))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(missing), nil
+}
+
+// AssignMissingPositions gives a position to the todos created before todos had
+// one, appending them by ID after the other todos of their owner. Firestore leaves
+// documents without the field out of queries ordered by it, so this must run before
+// such todos can be listed. It returns the number of todos updated.
+func (s *FirestoreStore) AssignMissingPositions(ctx context.Context) (int, error) {
+	docs, err := firebase.FirestoreClient.Collection(s.collection).Documents(ctx).GetAll()
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	last := make(map[string]string)
+	var missing []*models.Todo
+	snapshots := make(map[int]*firestore.DocumentSnapshot)
+	for _, doc := range docs {
+		todo, err := todoFromDoc(doc)
+		if err != nil {
+			continue
+		}
+		if todo.Position == "" {
+			missing = append(missing, todo)
+			snapshots[todo.ID] = doc
+		} else if todo.Position > last[todo.OwnerID] {
+			last[todo.OwnerID] = todo.Position
+		}
+	}
+	sortTodos(missing)
+
+	for _, todo := range missing {
+		position, err := positionBetween(last[todo.OwnerID], "")
+		if err != nil {
+			return 0, err
+		}
+		todo.Position = position
+		last[todo.OwnerID] = position
 	}
-	existingTodo.Completed = updatedTodo.Completed
-	existingTodo.UpdatedAt = time.Now()
 
-	_, err = docRef.Set(s.ctx, existingTodo)
+	// The precondition fails the batch if a todo changed since it was read
+	err = commitBatches(ctx, len(missing), func(batch *firestore.WriteBatch, i int) {
+		doc := snapshots[missing[i].ID]
+		batch.Update(doc.Ref, []firestore.Update{
+			{Path: todoFieldPaths["position"], Value: missing[i].Position},
+		}, firestore.LastUpdateTime(doc.UpdateTime))
+	})
 	if err != nil {
-		return nil, false
+		return 0, err
 	}
+	return len(missing), nil
+}
 
-	existingTodo.ID = id
-	return existingTodo, true
+// AssignMissingPriorityRanks stores the priority and its rank on the todos created
+// before todos had a priority, which read as medium. Firestore leaves documents
+// without PriorityRank out of queries ordered by priority, so this must run before
+// such todos can be listed by priority. It returns the number of todos updated.
+func (s *FirestoreStore) AssignMissingPriorityRanks(ctx context.Context) (int, error) {
+	docs, err := firebase.FirestoreClient.Collection(s.collection).Documents(ctx).GetAll()
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	var missing []*firestore.DocumentSnapshot
+	var todos []*models.Todo
+	for _, doc := range docs {
+		if _, ok := doc.Data()[todoFieldPaths["priority"]]; ok {
+			continue
+		}
+		todo, err := todoFromDoc(doc)
+		if err != nil {
+			continue
+		}
+		prepareTodo(todo)
+		missing = append(missing, doc)
+		todos = append(todos, todo)
+	}
+
+	// The precondition fails the batch if a todo changed since it was read
+	err = commitBatches(ctx, len(missing), func(batch *firestore.WriteBatch, i int) {
+		batch.Update(missing[i].Ref, []firestore.Update{
+			{Path: "Priority", Value: string(todos[i].Priority)},
+			{Path: todoFieldPaths["priority"], Value: todos[i].PriorityRank},
+		}, firestore.LastUpdateTime(missing[i].UpdateTime))
+	})
+	if err != nil {
+		return 0, err
+	}
+	return len(missing), nil
 }
 
 // Delete deletes a todo by ID
-func (s *FirestoreStore) Delete(id int) bool {
-	docRef := firebase.FirestoreClient.Collection(s.collection).Doc(strconv.Itoa(id))
-	_, err := docRef.Get(s.ctx)
+func (s *FirestoreStore) Delete(ctx context.Context, ownerID string, id int) error {
+	client := firebase.FirestoreClient
+	docRef := client.Collection(s.collection).Doc(strconv.Itoa(id))
+
+	err := client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(docRef)
+		if err != nil {
+			return err
+		}
+
+		todo, err := todoFromDoc(doc)
+		if err != nil {
+			return err
+		}
+		if todo.OwnerID != ownerID {
+			return ErrNotFound
+		}
+
+		// The checklist is deleted with the todo
+		items, err := tx.Documents(docRef.Collection(itemsCollection).Select()).GetAll()
+		if err != nil {
+			return err
+		}
+		for _, item := range items {
+			if err := tx.Delete(item.Ref); err != nil {
+				return err
+			}
+		}
+
+		return tx.Delete(docRef)
+	})
+	return translateError(err)
+}
+
+// ListItems returns the checklist of a todo, by position
+func (s *FirestoreStore) ListItems(ctx context.Context, ownerID string, todoID int) ([]*models.ChecklistItem, error) {
+	if _, err := s.GetByID(ctx, ownerID, todoID); err != nil {
+		return nil, err
+	}
+
+	docRef := firebase.FirestoreClient.Collection(s.collection).Doc(strconv.Itoa(todoID))
+	docs, err := docRef.Collection(itemsCollection).Documents(ctx).GetAll()
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return itemsFromDocs(docs), nil
+}
+
+// CreateItem appends an item to the checklist of a todo and returns it with the updated todo
+func (s *FirestoreStore) CreateItem(ctx context.Context, ownerID string, todoID int, item *models.ChecklistItem) (*models.ChecklistItem, *models.Todo, error) {
+	id, err := s.ids.NextID(ctx, "todo_items")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	todo, err := s.updateChecklist(ctx, ownerID, todoID, func(tx *firestore.Transaction, items []*models.ChecklistItem, now time.Time) ([]*models.ChecklistItem, error) {
+		item.ID = id
+		item.TodoID = todoID
+		item.Position = nextItemPosition(items)
+		item.CreatedAt = now
+		item.UpdatedAt = now
+
+		if err := tx.Create(s.itemRef(todoID, id), item); err != nil {
+			return nil, err
+		}
+		return append(items, item), nil
+	})
 	if err != nil {
-		return false
+		return nil, nil, err
 	}
+	return item, todo, nil
+}
+
+// UpdateItem updates a checklist item and returns it with the updated todo
+func (s *FirestoreStore) UpdateItem(ctx context.Context, ownerID string, todoID int, itemID int, updatedItem *models.ChecklistItem) (*models.ChecklistItem, *models.Todo, error) {
+	var updated *models.ChecklistItem
+	todo, err := s.updateChecklist(ctx, ownerID, todoID, func(tx *firestore.Transaction, items []*models.ChecklistItem, now time.Time) ([]*models.ChecklistItem, error) {
+		for _, item := range items {
+			if item.ID != itemID {
+				continue
+			}
+			updatedItem.UpdatedAt = now
+			mergeItem(item, updatedItem)
+			updated = item
+			return items, tx.Set(s.itemRef(todoID, itemID), item)
+		}
+		return nil, ErrNotFound
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return updated, todo, nil
+}
+
+// DeleteItem deletes a checklist item and returns the updated todo
+func (s *FirestoreStore) DeleteItem(ctx context.Context, ownerID string, todoID int, itemID int) (*models.Todo, error) {
+	return s.updateChecklist(ctx, ownerID, todoID, func(tx *firestore.Transaction, items []*models.ChecklistItem, now time.Time) ([]*models.ChecklistItem, error) {
+		for i, item := range items {
+			if item.ID != itemID {
+				continue
+			}
+			if err := tx.Delete(s.itemRef(todoID, itemID)); err != nil {
+				return nil, err
+			}
+			return append(items[:i:i], items[i+1:]...), nil
+		}
+		return nil, ErrNotFound
+	})
+}
 
-	_, err = docRef.Delete(s.ctx)
-	return err == nil
+// ReorderItems moves the items of a checklist to the order of itemIDs, which must
+// list every item of the todo once
+func (s *FirestoreStore) ReorderItems(ctx context.Context, ownerID string, todoID int, itemIDs []int) ([]*models.ChecklistItem, error) {
+	var reordered []*models.ChecklistItem
+	_, err := s.updateChecklist(ctx, ownerID, todoID, func(tx *firestore.Transaction, items []*models.ChecklistItem, now time.Time) ([]*models.ChecklistItem, error) {
+		if err := reorderItems(items, itemIDs); err != nil {
+			return nil, err
+		}
+		for _, item := range items {
+			item.UpdatedAt = now
+			if err := tx.Set(s.itemRef(todoID, item.ID), item); err != nil {
+				return nil, err
+			}
+		}
+		reordered = items
+		return items, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reordered, nil
 }
 
+// updateChecklist runs change in a transaction over a todo and its checklist items.
+// change writes the items it modifies and returns the resulting checklist, from which
+// the progress of the todo is recomputed before the todo is stored.
+func (s *FirestoreStore) updateChecklist(ctx context.Context, ownerID string, todoID int, change func(tx *firestore.Transaction, items []*models.ChecklistItem, now time.Time) ([]*models.ChecklistItem, error)) (*models.Todo, error) {
+	client := firebase.FirestoreClient
+	docRef := client.Collection(s.collection).Doc(strconv.Itoa(todoID))
+
+	var todo *models.Todo
+	err := client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(docRef)
+		if err != nil {
+			return err
+		}
+		todo, err = todoFromDoc(doc)
+		if err != nil {
+			return err
+		}
+		if todo.OwnerID != ownerID {
+			return ErrNotFound
+		}
+
+		docs, err := tx.Documents(docRef.Collection(itemsCollection)).GetAll()
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		items, err := change(tx, itemsFromDocs(docs), now)
+		if err != nil {
+			return err
+		}
+
+		applyProgress(todo, items, now)
+		todo.UpdatedAt = now
+		return tx.Set(docRef, todo)
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return todo, nil
+}
+
+// GetLists returns the todo lists owned by ownerID, ordered by ID
+func (s *FirestoreStore) GetLists(ctx context.Context, ownerID string) ([]*models.TodoList, error) {
+	docs, err := firebase.FirestoreClient.Collection(s.lists).
+		Where("OwnerID", "==", ownerID).
+		Documents(ctx).GetAll()
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	lists := make([]*models.TodoList, 0, len(docs))
+	for _, doc := range docs {
+		list, err := listFromDoc(doc)
+		if err != nil {
+			continue
+		}
+		lists = append(lists, list)
+	}
+	sortLists(lists)
+	return lists, nil
+}
+
+// GetList returns a todo list by ID
+func (s *FirestoreStore) GetList(ctx context.Context, ownerID string, id int) (*models.TodoList, error) {
+	doc, err := firebase.FirestoreClient.Collection(s.lists).Doc(strconv.Itoa(id)).Get(ctx)
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	list, err := listFromDoc(doc)
+	if err != nil {
+		return nil, err
+	}
+	if list.OwnerID != ownerID {
+		return nil, ErrNotFound
+	}
+	return list, nil
+}
+
+// CreateList creates a new todo list
+func (s *FirestoreStore) CreateList(ctx context.Context, list *models.TodoList) (*models.TodoList, error) {
+	id, err := s.ids.NextID(ctx, s.lists)
+	if err != nil {
+		return nil, err
+	}
+	list.ID = id
+
+	now := time.Now()
+	list.CreatedAt = now
+	list.UpdatedAt = now
+
+	docRef := firebase.FirestoreClient.Collection(s.lists).Doc(strconv.Itoa(list.ID))
+	if _, err := docRef.Create(ctx, list); err != nil {
+		return nil, translateError(err)
+	}
+	return list, nil
+}
+
+// UpdateList renames a todo list
+func (s *FirestoreStore) UpdateList(ctx context.Context, ownerID string, id int, updatedList *models.TodoList) (*models.TodoList, error) {
+	client := firebase.FirestoreClient
+	docRef := client.Collection(s.lists).Doc(strconv.Itoa(id))
+
+	var list *models.TodoList
+	err := client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		doc, err := tx.Get(docRef)
+		if err != nil {
+			return err
+		}
+		list, err = listFromDoc(doc)
+		if err != nil {
+			return err
+		}
+		if list.OwnerID != ownerID {
+			return ErrNotFound
+		}
+
+		list.Name = updatedList.Name
+		list.UpdatedAt = time.Now()
+		return tx.Set(docRef, list)
+	})
+	if err != nil {
+		return nil, translateError(err)
+	}
+	return list, nil
+}
+
+// DeleteList deletes a todo list, deleting its todos (and their checklists) when
+// cascade is set and moving them to moveTo (0 for no list) otherwise. Lists can
+// hold more todos than a transaction can write, so the todos are changed in
+// batches and the list is deleted last: a failed deletion can simply be retried.
+func (s *FirestoreStore) DeleteList(ctx context.Context, ownerID string, id int, cascade bool, moveTo int) ([]*models.Todo, error) {
+	if _, err := s.GetList(ctx, ownerID, id); err != nil {
+		return nil, err
+	}
+	if !cascade && moveTo != 0 {
+		if moveTo == id {
+			return nil, ErrNotFound
+		}
+		if _, err := s.GetList(ctx, ownerID, moveTo); err != nil {
+			return nil, err
+		}
+	}
+
+	client := firebase.FirestoreClient
+	docs, err := client.Collection(s.collection).
+		Where(todoFieldPaths["owner_id"], "==", ownerID).
+		Where(todoFieldPaths["list_id"], "==", id).
+		Documents(ctx).GetAll()
+	if err != nil {
+		return nil, translateError(err)
+	}
+
+	todos := make([]*models.Todo, 0, len(docs))
+	refs := make([]*firestore.DocumentRef, 0, len(docs))
+	for _, doc := range docs {
+		todo, err := todoFromDoc(doc)
+		if err != nil {
+			continue
+		}
+		todos = append(todos, todo)
+		refs = append(refs, doc.Ref)
+	}
+
+	if cascade {
+		// Checklist items go first so that no item outlives its todo
+		var deletes []*firestore.DocumentRef
+		for _, ref := range refs {
+			items, err := ref.Collection(itemsCollection).Select().Documents(ctx).GetAll()
+			if err != nil {
+				return nil, translateError(err)
+			}
+			for _, item := range items {
+				deletes = append(deletes, item.Ref)
+			}
+		}
+		deletes = append(deletes, refs...)
+
+		err = commitBatches(ctx, len(deletes), func(batch *firestore.WriteBatch, i int) {
+			batch.Delete(deletes[i])
+		})
+	} else {
+		now := time.Now()
+		for _, todo := range todos {
+			todo.ListID = moveTo
+			todo.UpdatedAt = now
+		}
+		err = commitBatches(ctx, len(refs), func(batch *firestore.WriteBatch, i int) {
+			batch.Update(refs[i], []firestore.Update{
+				{Path: todoFieldPaths["list_id"], Value: moveTo},
+				{Path: todoFieldPaths["updated_at"], Value: now},
+			})
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := client.Collection(s.lists).Doc(strconv.Itoa(id)).Delete(ctx); err != nil {
+		return nil, translateError(err)
+	}
+	return todos, nil
+}
+
+// Batch applies the operations of a todo batch of ownerID in one transaction and
+// returns one result per operation. Every operation is checked before the first
+// write: an atomic batch with a failed operation writes nothing, otherwise the
+// failed operations are skipped. IDs are reserved up front for the creates; when
+// updates complete recurring todos, the batch runs again with IDs reserved for
+// their next occurrences too, like in Update.
+func (s *FirestoreStore) Batch(ctx context.Context, ownerID string, ops []TodoBatchOp, atomic bool) ([]TodoBatchResult, error) {
+	if err := checkBatchSize(len(ops)); err != nil {
+		return nil, err
+	}
+
+	reserved := 0
+	for _, op := range ops {
+		if op.Type == models.BatchCreate {
+			reserved++
+		}
+	}
+
+	for {
+		var firstID int
+		if reserved > 0 {
+			var err error
+			if firstID, err = s.ids.NextIDs(ctx, s.collection, reserved); err != nil {
+				return nil, err
+			}
+		}
+
+		results, needed, err := s.batch(ctx, ownerID, ops, atomic, firstID, reserved)
+		if errors.Is(err, errIDsNeeded) {
+			reserved = needed
+			continue
+		}
+		if err != nil {
+			return nil, translateError(err)
+		}
+		return results, nil
+	}
+}
+
+// batch runs the transaction of Batch with reserved IDs starting at firstID. When
+// the batch creates more todos than that, it fails with errIDsNeeded and returns
+// the number of IDs it needs.
+func (s *FirestoreStore) batch(ctx context.Context, ownerID string, ops []TodoBatchOp, atomic bool, firstID, reserved int) ([]TodoBatchResult, int, error) {
+	client := firebase.FirestoreClient
+	collection := client.Collection(s.collection)
+
+	var results []TodoBatchResult
+	var needed int
+	err := client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		var refs []*firestore.DocumentRef
+		for _, op := range ops {
+			if op.Type != models.BatchCreate {
+				refs = append(refs, collection.Doc(strconv.Itoa(op.ID)))
+			}
+		}
+		docs, err := tx.GetAll(refs)
+		if err != nil {
+			return err
+		}
+		existing := make(map[int]*models.Todo)
+		for _, doc := range docs {
+			if !doc.Exists() {
+				continue
+			}
+			todo, err := todoFromDoc(doc)
+			if err != nil {
+				return err
+			}
+			if todo.OwnerID == ownerID {
+				existing[todo.ID] = todo
+			}
+		}
+		last, err := s.neighborPosition(tx, ownerID, 0, "", "")
+		if err != nil {
+			return err
+		}
+
+		results = planTodoBatch(ownerID, ops, existing, last, time.Now())
+		if abortTodoBatch(results, atomic) {
+			return nil
+		}
+
+		needed = 0
+		for i, op := range ops {
+			if results[i].Err == nil && op.Type == models.BatchCreate {
+				needed++
+			}
+			if results[i].Next != nil {
+				needed++
+			}
+		}
+		if needed > reserved {
+			return errIDsNeeded
+		}
+
+		nextID := firstID
+		var writes []txWrite
+		for i, op := range ops {
+			result := &results[i]
+			if result.Err != nil {
+				continue
+			}
+			docRef := collection.Doc(strconv.Itoa(op.ID))
+			switch op.Type {
+			case models.BatchCreate:
+				todo := result.Todo
+				todo.ID = nextID
+				nextID++
+				writes = append(writes, func(tx *firestore.Transaction) error {
+					return tx.Create(collection.Doc(strconv.Itoa(todo.ID)), todo)
+				})
+
+			case models.BatchUpdate:
+				todo, next := result.Todo, result.Next
+				if next != nil {
+					next.ID = nextID
+					nextID++
+					todo.NextTodoID = next.ID
+					writes = append(writes, func(tx *firestore.Transaction) error {
+						return tx.Create(collection.Doc(strconv.Itoa(next.ID)), next)
+					})
+				}
+				writes = append(writes, func(tx *firestore.Transaction) error {
+					return tx.Set(docRef, todo)
+				})
+
+			case models.BatchDelete:
+				// The checklist is deleted with the todo
+				items, err := tx.Documents(docRef.Collection(itemsCollection).Select()).GetAll()
+				if err != nil {
+					return err
+				}
+				for _, item := range items {
+					itemRef := item.Ref
+					writes = append(writes, func(tx *firestore.Transaction) error {
+						return tx.Delete(itemRef)
+					})
+				}
+				writes = append(writes, func(tx *firestore.Transaction) error {
+					return tx.Delete(docRef)
+				})
+			}
+		}
+
+		if err := checkBatchWrites(len(writes)); err != nil {
+			return err
+		}
+		for _, write := range writes {
+			if err := write(tx); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, needed, err
+	}
+	return results, needed, nil
+}
+
+// listFromDoc decodes a todo list document, taking the ID from the document ID
+func listFromDoc(doc *firestore.DocumentSnapshot) (*models.TodoList, error) {
+	list := &models.TodoList{}
+	if err := doc.DataTo(list); err != nil {
+		return nil, err
+	}
+	if id, err := strconv.Atoi(doc.Ref.ID); err == nil {
+		list.ID = id
+	}
+	return list, nil
+}
+
+// itemRef returns the document of a checklist item
+func (s *FirestoreStore) itemRef(todoID, itemID int) *firestore.DocumentRef {
+	return firebase.FirestoreClient.Collection(s.collection).Doc(strconv.Itoa(todoID)).
+		Collection(itemsCollection).Doc(strconv.Itoa(itemID))
+}
+
+// itemsFromDocs decodes checklist item documents, ordered by position
+func itemsFromDocs(docs []*firestore.DocumentSnapshot) []*models.ChecklistItem {
+	items := make([]*models.ChecklistItem, 0, len(docs))
+	for _, doc := range docs {
+		item := &models.ChecklistItem{}
+		if err := doc.DataTo(item); err != nil {
+			continue
+		}
+		if id, err := strconv.Atoi(doc.Ref.ID); err == nil {
+			item.ID = id
+		}
+		items = append(items, item)
+	}
+	sortItems(items)
+	return items
+}
+
+// todoFromDoc decodes a todo document, taking the ID from the document ID
+func todoFromDoc(doc *firestore.DocumentSnapshot) (*models.Todo, error) {
+	todo := &models.Todo{}
+	if err := doc.DataTo(todo); err != nil {
+		return nil, err
+	}
+	if id, err := strconv.Atoi(doc.Ref.ID); err == nil {
+		todo.ID = id
+	}
+	// Todos created before priorities read as medium until AssignMissingPriorityRanks stores it
+	if todo.Priority == "" {
+		prepareTodo(todo)
+	}
+	return todo, nil
+}
@@ -0,0 +1,95 @@
+package store
+
+import (
+	"apigo1/firebase"
+	"context"
+	"strconv"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// IDAllocator hands out unique integer IDs per collection. NextIDs reserves n
+// consecutive IDs and returns the first one.
+type IDAllocator interface {
+	NextID(ctx context.Context, collection string) (int, error)
+	NextIDs(ctx context.Context, collection string, n int) (int, error)
+}
+
+// FirestoreIDAllocator allocates IDs from counter documents stored in Firestore.
+// Each collection has one counter document that is incremented inside a transaction,
+// so concurrent creates (even across server instances) never receive the same ID.
+type FirestoreIDAllocator struct {
+	collection string
+}
+
+// idCounter is the Firestore representation of a counter document
+type idCounter struct {
+	Value int64
+}
+
+// NewFirestoreIDAllocator creates a new FirestoreIDAllocator
+func NewFirestoreIDAllocator() *FirestoreIDAllocator {
+	return &FirestoreIDAllocator{
+		collection: "counters",
+	}
+}
+
+// NextID returns the next unused ID for the given collection
+func (a *FirestoreIDAllocator) NextID(ctx context.Context, collection string) (int, error) {
+	return a.NextIDs(ctx, collection, 1)
+}
+
+// NextIDs reserves the next n unused IDs for the given collection and returns the first one
+func (a *FirestoreIDAllocator) NextIDs(ctx context.Context, collection string, n int) (int, error) {
+	client := firebase.FirestoreClient
+	counterRef := client.Collection(a.collection).Doc(collection)
+
+	var first int64
+	err := client.RunTransaction(ctx, func(ctx context.Context, tx *firestore.Transaction) error {
+		counter := idCounter{}
+
+		doc, err := tx.Get(counterRef)
+		switch {
+		case status.Code(err) == codes.NotFound:
+			// First allocation: seed the counter from the IDs already in use,
+			// so documents created before the counter existed are not overwritten
+			counter.Value, err = maxDocumentID(tx, client.Collection(collection))
+			if err != nil {
+				return err
+			}
+		case err != nil:
+			return err
+		default:
+			if err := doc.DataTo(&counter); err != nil {
+				return err
+			}
+		}
+
+		first = counter.Value + 1
+		return tx.Set(counterRef, idCounter{Value: counter.Value + int64(n)})
+	})
+	if err != nil {
+		return 0, translateError(err)
+	}
+
+	return int(first), nil
+}
+
+// maxDocumentID returns the highest numeric document ID in a collection
+func maxDocumentID(tx *firestore.Transaction, collection *firestore.CollectionRef) (int64, error) {
+	// Select with no fields only fetches document references
+	docs, err := tx.Documents(collection.Select()).GetAll()
+	if err != nil {
+		return 0, err
+	}
+
+	var maxID int64
+	for _, doc := range docs {
+		if id, err := strconv.ParseInt(doc.Ref.ID, 10, 64); err == nil && id > maxID {
+			maxID = id
+		}
+	}
+	return maxID, nil
+}
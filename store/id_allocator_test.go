@@ -0,0 +1,142 @@
+package store
+
+import (
+	"apigo1/firebase"
+	"apigo1/models"
+	"context"
+	"fmt"
+	"os"
+	"sync"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// concurrentCreates is the number of creates racing for IDs in each test
+const concurrentCreates = 20
+
+// useEmulator points firebase.FirestoreClient at the Firestore emulator, skipping
+// the test when FIRESTORE_EMULATOR_HOST is not set. Every test gets its own
+// project, so it starts from empty collections.
+func useEmulator(t *testing.T) context.Context {
+	t.Helper()
+	if os.Getenv("FIRESTORE_EMULATOR_HOST") == "" {
+		t.Skip("FIRESTORE_EMULATOR_HOST is not set")
+	}
+
+	ctx := context.Background()
+	client, err := firestore.NewClient(ctx, fmt.Sprintf("apigo1-test-%d", time.Now().UnixNano()))
+	if err != nil {
+		t.Fatalf("connecting to the emulator: %v", err)
+	}
+	firebase.FirestoreClient = client
+	t.Cleanup(func() {
+		firebase.FirestoreClient = nil
+		client.Close()
+	})
+	return ctx
+}
+
+// createConcurrently runs create(i) for every i below n at the same time and
+// returns the IDs it returned, failing the test on any error
+func createConcurrently(t *testing.T, n int, create func(i int) (int, error)) []int {
+	t.Helper()
+	ids := make([]int, n)
+	errs := make([]error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ids[i], errs[i] = create(i)
+		}(i)
+	}
+	wg.Wait()
+
+	for i, err := range errs {
+		if err != nil {
+			t.Fatalf("create %d: %v", i, err)
+		}
+	}
+	return ids
+}
+
+// checkUniqueIDs fails the test when two creates got the same ID
+func checkUniqueIDs(t *testing.T, ids []int) {
+	t.Helper()
+	seen := make(map[int]int)
+	for i, id := range ids {
+		if j, ok := seen[id]; ok {
+			t.Fatalf("creates %d and %d both got ID %d", j, i, id)
+		}
+		seen[id] = i
+	}
+}
+
+func TestFirestoreStoreConcurrentCreates(t *testing.T) {
+	ctx := useEmulator(t)
+	s := NewFirestoreStore(NewFirestoreIDAllocator())
+
+	ids := createConcurrently(t, concurrentCreates, func(i int) (int, error) {
+		todo, err := s.Create(ctx, &models.Todo{Title: fmt.Sprintf("todo %d", i), OwnerID: "owner"})
+		if err != nil {
+			return 0, err
+		}
+		return todo.ID, nil
+	})
+	checkUniqueIDs(t, ids)
+
+	// A todo overwritten by another create would have the other title
+	for i, id := range ids {
+		todo, err := s.GetByID(ctx, "owner", id)
+		if err != nil {
+			t.Fatalf("todo %d: %v", id, err)
+		}
+		if want := fmt.Sprintf("todo %d", i); todo.Title != want {
+			t.Errorf("todo %d has title %q, want %q", id, todo.Title, want)
+		}
+	}
+	todos, err := s.GetAll(ctx, "owner")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(todos) != concurrentCreates {
+		t.Errorf("got %d todos, want %d", len(todos), concurrentCreates)
+	}
+}
+
+func TestBlogStoreConcurrentCreates(t *testing.T) {
+	ctx := useEmulator(t)
+	s := NewBlogStore(NewFirestoreIDAllocator())
+
+	ids := createConcurrently(t, concurrentCreates, func(i int) (int, error) {
+		blog, err := s.Create(ctx, &models.Blog{
+			Title: fmt.Sprintf("post %d", i),
+			Slug:  fmt.Sprintf("post-%d", i),
+		})
+		if err != nil {
+			return 0, err
+		}
+		return blog.ID, nil
+	})
+	checkUniqueIDs(t, ids)
+
+	// A blog overwritten by another create would have the other title
+	for i, id := range ids {
+		blog, err := s.GetByID(ctx, id)
+		if err != nil {
+			t.Fatalf("blog %d: %v", id, err)
+		}
+		if want := fmt.Sprintf("post %d", i); blog.Title != want {
+			t.Errorf("blog %d has title %q, want %q", id, blog.Title, want)
+		}
+	}
+	blogs, err := s.GetAll(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(blogs) != concurrentCreates {
+		t.Errorf("got %d blogs, want %d", len(blogs), concurrentCreates)
+	}
+}
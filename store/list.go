@@ -0,0 +1,14 @@
+package store
+
+import (
+	"apigo1/models"
+	"sort"
+)
+
+// listsCollection is the Firestore collection of todo lists
+const listsCollection = "lists"
+
+// sortLists orders todo lists by ID, which is their creation order
+func sortLists(lists []*models.TodoList) {
+	sort.Slice(lists, func(i, j int) bool { return lists[i].ID < lists[j].ID })
+}
@@ -0,0 +1,339 @@
+package store
+
+import (
+	"apigo1/models"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryBlogStore manages blogs in memory
+type MemoryBlogStore struct {
+	blogs     map[int]*models.Blog
+	revisions map[int][]*models.BlogRevision // oldest first
+	slugs     map[string]int                 // current and previous slugs, by blog ID
+	mu        sync.RWMutex
+	nextID    int
+}
+
+// NewMemoryBlogStore creates a new MemoryBlogStore
+func NewMemoryBlogStore() *MemoryBlogStore {
+	return &MemoryBlogStore{
+		blogs:     make(map[int]*models.Blog),
+		revisions: make(map[int][]*models.BlogRevision),
+		slugs:     make(map[string]int),
+		nextID:    1,
+	}
+}
+
+// GetAll returns all blogs
+func (s *MemoryBlogStore) GetAll(ctx context.Context) ([]*models.Blog, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	blogs := make([]*models.Blog, 0, len(s.blogs))
+	for _, blog := range s.blogs {
+		blogs = append(blogs, blog)
+	}
+	return blogs, nil
+}
+
+// List returns one page of blogs matching opts, newest first unless opts.Sort is set,
+// together with the cursor of the next page ("" on the last page)
+func (s *MemoryBlogStore) List(ctx context.Context, opts ListOptions) ([]*models.Blog, string, error) {
+	blogs, _ := s.GetAll(ctx)
+	idOf := func(blog *models.Blog) int { return blog.ID }
+
+	blogs, err := filterAndSort(blogs, blogFieldPaths, blogFieldValue, idOf, opts, blogDefaultSort)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return paginate(blogs, idOf, opts)
+}
+
+// GetByID returns a blog by ID
+func (s *MemoryBlogStore) GetByID(ctx context.Context, id int) (*models.Blog, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	blog, exists := s.blogs[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return blog, nil
+}
+
+// GetBySlug returns the blog using slug, either as its current slug or as one of
+// its previous slugs (callers compare blog.Slug to detect a renamed slug)
+func (s *MemoryBlogStore) GetBySlug(ctx context.Context, slug string) (*models.Blog, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	id, exists := s.slugs[slug]
+	if !exists {
+		return nil, ErrNotFound
+	}
+	return s.blogs[id], nil
+}
+
+// Create creates a new blog together with its first revision. When blog.Slug is
+// taken by another blog, a numeric suffix is appended (my-post-2, my-post-3, ...).
+func (s *MemoryBlogStore) Create(ctx context.Context, blog *models.Blog) (*models.Blog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	slug, err := s.findSlug(blog.Slug, s.nextID, nil)
+	if err != nil {
+		return nil, err
+	}
+	blog.Slug = slug
+	blog.ID = s.nextID
+	s.nextID++
+	s.slugs[slug] = blog.ID
+	blog.Revision = 1
+	s.blogs[blog.ID] = blog
+	s.revisions[blog.ID] = []*models.BlogRevision{newRevision(blog)}
+	return blog, nil
+}
+
+// Update applies a change to a blog under the store lock and saves the result as a
+// new revision. An error returned by change is returned as is.
+func (s *MemoryBlogStore) Update(ctx context.Context, id int, change BlogChange) (*models.Blog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	blog, exists := s.blogs[id]
+	if !exists {
+		return nil, ErrNotFound
+	}
+
+	// Change a copy so that a failed change leaves the blog untouched
+	merged := *blog
+	if err := change(&merged); err != nil {
+		return nil, err
+	}
+	if merged.Slug != blog.Slug {
+		slug, err := s.findSlug(merged.Slug, id, nil)
+		if err != nil {
+			return nil, err
+		}
+		merged.Slug = slug
+		s.slugs[slug] = id
+		retireSlug(&merged, blog.Slug)
+	}
+	*blog = merged
+
+	blog.UpdatedAt = time.Now()
+	blog.Revision++
+	s.revisions[id] = append(s.revisions[id], newRevision(blog))
+
+	return blog, nil
+}
+
+// PublishDue publishes every unpublished blog whose PublishAt is not after now and
+// returns the blogs it published
+func (s *MemoryBlogStore) PublishDue(ctx context.Context, now time.Time) ([]*models.Blog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	published := []*models.Blog{}
+	for id, blog := range s.blogs {
+		if err := publishIfDue(blog, now); err != nil {
+			continue
+		}
+		blog.UpdatedAt = time.Now()
+		blog.Revision++
+		s.revisions[id] = append(s.revisions[id], newRevision(blog))
+		published = append(published, blog)
+	}
+	return published, nil
+}
+
+// MergeTags replaces the tags in from with to on every blog, saves a revision of
+// each changed blog and returns the changed blogs
+func (s *MemoryBlogStore) MergeTags(ctx context.Context, from []string, to string, updatedBy string) ([]*models.Blog, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fromSet := tagSet(from)
+	now := time.Now()
+	merged := []*models.Blog{}
+	for id, blog := range s.blogs {
+		tags, changed := replaceTags(blog.Tags, fromSet, to)
+		if !changed {
+			continue
+		}
+		blog.Tags = tags
+		blog.UpdatedBy = updatedBy
+		blog.UpdatedAt = now
+		blog.Revision++
+		s.revisions[id] = append(s.revisions[id], newRevision(blog))
+		merged = append(merged, blog)
+	}
+	return merged, nil
+}
+
+// Delete deletes a blog by ID together with its revisions, and releases its current
+// and previous slugs
+func (s *MemoryBlogStore) Delete(ctx context.Context, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, exists := s.blogs[id]; !exists {
+		return ErrNotFound
+	}
+	s.remove(id)
+	return nil
+}
+
+// remove deletes a blog with its revisions and slugs. The caller must hold the write lock.
+func (s *MemoryBlogStore) remove(id int) {
+	for slug, blogID := range s.slugs {
+		if blogID == id {
+			delete(s.slugs, slug)
+		}
+	}
+	delete(s.blogs, id)
+	delete(s.revisions, id)
+}
+
+// Batch applies the operations of a blog batch under the store lock and returns one
+// result per operation. Every operation is checked before the first one is applied:
+// an atomic batch with a failed operation changes nothing, otherwise the failed
+// operations are skipped. Slugs released by deletes are only free after the batch.
+func (s *MemoryBlogStore) Batch(ctx context.Context, ops []BlogBatchOp, atomic bool) ([]BlogBatchResult, error) {
+	if err := checkBatchSize(len(ops)); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	results := make([]BlogBatchResult, len(ops))
+	targeted := make(map[int]bool)
+	pending := make(map[string]int)
+	nextID := s.nextID
+	now := time.Now()
+	for i, op := range ops {
+		if err := checkBatchTarget(op.Type, op.ID, targeted); err != nil {
+			results[i].Err = err
+			continue
+		}
+
+		switch op.Type {
+		case models.BatchCreate:
+			slug, err := s.findSlug(op.Blog.Slug, nextID, pending)
+			if err != nil {
+				results[i].Err = err
+				continue
+			}
+			blog := op.Blog
+			blog.ID = nextID
+			nextID++
+			blog.Slug = slug
+			blog.Revision = 1
+			pending[slug] = blog.ID
+			results[i].Blog = blog
+
+		case models.BatchUpdate:
+			blog, exists := s.blogs[op.ID]
+			if !exists {
+				results[i].Err = ErrNotFound
+				continue
+			}
+			merged := *blog
+			if err := op.Change(&merged); err != nil {
+				results[i].Err = err
+				continue
+			}
+			if merged.Slug != blog.Slug {
+				slug, err := s.findSlug(merged.Slug, op.ID, pending)
+				if err != nil {
+					results[i].Err = err
+					continue
+				}
+				merged.Slug = slug
+				pending[slug] = op.ID
+				retireSlug(&merged, blog.Slug)
+			}
+			merged.UpdatedAt = now
+			merged.Revision++
+			results[i].Blog = &merged
+
+		case models.BatchDelete:
+			if _, exists := s.blogs[op.ID]; !exists {
+				results[i].Err = ErrNotFound
+			}
+		}
+	}
+	if abortBlogBatch(results, atomic) {
+		return results, nil
+	}
+
+	s.nextID = nextID
+	for i, op := range ops {
+		result := &results[i]
+		if result.Err != nil {
+			continue
+		}
+		switch op.Type {
+		case models.BatchCreate:
+			blog := result.Blog
+			s.blogs[blog.ID] = blog
+			s.slugs[blog.Slug] = blog.ID
+			s.revisions[blog.ID] = []*models.BlogRevision{newRevision(blog)}
+		case models.BatchUpdate:
+			blog := s.blogs[op.ID]
+			s.slugs[result.Blog.Slug] = op.ID
+			*blog = *result.Blog
+			result.Blog = blog
+			s.revisions[op.ID] = append(s.revisions[op.ID], newRevision(blog))
+		case models.BatchDelete:
+			s.remove(op.ID)
+		}
+	}
+	return results, nil
+}
+
+// ListRevisions returns the revisions of a blog, newest first
+func (s *MemoryBlogStore) ListRevisions(ctx context.Context, id int) ([]*models.BlogRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stored := s.revisions[id]
+	revisions := make([]*models.BlogRevision, 0, len(stored))
+	for i := len(stored) - 1; i >= 0; i-- {
+		revisions = append(revisions, stored[i])
+	}
+	return revisions, nil
+}
+
+// GetRevision returns one revision of a blog
+func (s *MemoryBlogStore) GetRevision(ctx context.Context, id int, rev int) (*models.BlogRevision, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	// Revisions are numbered from 1 and never removed while the blog exists
+	stored := s.revisions[id]
+	if rev < 1 || rev > len(stored) {
+		return nil, ErrNotFound
+	}
+	return stored[rev-1], nil
+}
+
+// findSlug returns the first candidate for base that is free or already used by blogID.
+// pending holds the slugs taken by earlier operations of a batch, nil otherwise.
+// The caller must hold the write lock.
+func (s *MemoryBlogStore) findSlug(base string, blogID int, pending map[string]int) (string, error) {
+	for n := 1; n <= maxSlugAttempts; n++ {
+		candidate := slugCandidate(base, n)
+		id, taken := s.slugs[candidate]
+		if pendingID, ok := pending[candidate]; ok {
+			id, taken = pendingID, true
+		}
+		if !taken || id == blogID {
+			return candidate, nil
+		}
+	}
+	return "", errNoFreeSlug(base)
+}
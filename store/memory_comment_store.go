@@ -0,0 +1,126 @@
+package store
+
+import (
+	"apigo1/models"
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryCommentStore manages comments in memory
+type MemoryCommentStore struct {
+	comments map[int]*models.Comment
+	mu       sync.RWMutex
+	nextID   int
+}
+
+// NewMemoryCommentStore creates a new MemoryCommentStore
+func NewMemoryCommentStore() *MemoryCommentStore {
+	return &MemoryCommentStore{
+		comments: make(map[int]*models.Comment),
+		nextID:   1,
+	}
+}
+
+// ListByBlog returns every comment of a blog, whatever its status, oldest first
+func (s *MemoryCommentStore) ListByBlog(ctx context.Context, blogID int) ([]*models.Comment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	comments := []*models.Comment{}
+	for _, comment := range s.comments {
+		if comment.BlogID == blogID {
+			comments = append(comments, comment)
+		}
+	}
+	sortComments(comments)
+	return comments, nil
+}
+
+// ListByStatus returns up to limit comments with the given status across all blogs, oldest first
+func (s *MemoryCommentStore) ListByStatus(ctx context.Context, status models.CommentStatus, limit int) ([]*models.Comment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	comments := []*models.Comment{}
+	for _, comment := range s.comments {
+		if comment.Status == status {
+			comments = append(comments, comment)
+		}
+	}
+	sortComments(comments)
+	if len(comments) > limit {
+		comments = comments[:limit]
+	}
+	return comments, nil
+}
+
+// GetByID returns a comment of a blog by ID
+func (s *MemoryCommentStore) GetByID(ctx context.Context, blogID int, id int) (*models.Comment, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	comment, exists := s.comments[id]
+	if !exists || comment.BlogID != blogID {
+		return nil, ErrNotFound
+	}
+	return comment, nil
+}
+
+// Create creates a new comment
+func (s *MemoryCommentStore) Create(ctx context.Context, comment *models.Comment) (*models.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comment.ID = s.nextID
+	s.nextID++
+	now := time.Now()
+	comment.CreatedAt = now
+	comment.UpdatedAt = now
+	s.comments[comment.ID] = comment
+	return comment, nil
+}
+
+// SetStatus changes the moderation status of a comment
+func (s *MemoryCommentStore) SetStatus(ctx context.Context, blogID int, id int, status models.CommentStatus) (*models.Comment, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	comment, exists := s.comments[id]
+	if !exists || comment.BlogID != blogID {
+		return nil, ErrNotFound
+	}
+	comment.Status = status
+	comment.UpdatedAt = time.Now()
+	return comment, nil
+}
+
+// Delete deletes a comment together with its replies
+func (s *MemoryCommentStore) Delete(ctx context.Context, blogID int, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if comment, exists := s.comments[id]; !exists || comment.BlogID != blogID {
+		return ErrNotFound
+	}
+	for replyID, comment := range s.comments {
+		if comment.ParentID == id {
+			delete(s.comments, replyID)
+		}
+	}
+	delete(s.comments, id)
+	return nil
+}
+
+// DeleteByBlog deletes every comment of a blog
+func (s *MemoryCommentStore) DeleteByBlog(ctx context.Context, blogID int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for id, comment := range s.comments {
+		if comment.BlogID == blogID {
+			delete(s.comments, id)
+		}
+	}
+	return nil
+}
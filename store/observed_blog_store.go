@@ -0,0 +1,127 @@
+package store
+
+import (
+	"apigo1/models"
+	"context"
+	"sync"
+	"time"
+)
+
+// BlogEventType identifies the kind of change reported to blog listeners
+type BlogEventType string
+
+const (
+	BlogCreated BlogEventType = "created"
+	BlogUpdated BlogEventType = "updated"
+	BlogDeleted BlogEventType = "deleted"
+)
+
+// BlogEvent describes a successful change to a blog. Blog is nil for deletions.
+type BlogEvent struct {
+	Type BlogEventType
+	ID   int
+	Blog *models.Blog
+}
+
+// BlogListener is called after a blog has been changed
+type BlogListener func(BlogEvent)
+
+// ObservedBlogStore wraps a BlogStoreInterface and notifies listeners after every
+// successful Create, Update, Delete, scheduled publish, tag merge and batch
+// operation, so caches and indexes built from blogs can stay in sync with the store
+type ObservedBlogStore struct {
+	BlogStoreInterface
+	listeners []BlogListener
+	mu        sync.RWMutex
+}
+
+// NewObservedBlogStore creates a new ObservedBlogStore around s
+func NewObservedBlogStore(s BlogStoreInterface) *ObservedBlogStore {
+	return &ObservedBlogStore{BlogStoreInterface: s}
+}
+
+// Subscribe registers a listener for blog changes
+func (s *ObservedBlogStore) Subscribe(l BlogListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, l)
+}
+
+// Create creates a new blog and notifies listeners
+func (s *ObservedBlogStore) Create(ctx context.Context, blog *models.Blog) (*models.Blog, error) {
+	created, err := s.BlogStoreInterface.Create(ctx, blog)
+	if err != nil {
+		return nil, err
+	}
+	s.notify(BlogEvent{Type: BlogCreated, ID: created.ID, Blog: created})
+	return created, nil
+}
+
+// Update updates an existing blog and notifies listeners
+func (s *ObservedBlogStore) Update(ctx context.Context, id int, change BlogChange) (*models.Blog, error) {
+	updated, err := s.BlogStoreInterface.Update(ctx, id, change)
+	if err != nil {
+		return nil, err
+	}
+	s.notify(BlogEvent{Type: BlogUpdated, ID: id, Blog: updated})
+	return updated, nil
+}
+
+// Delete deletes a blog by ID and notifies listeners
+func (s *ObservedBlogStore) Delete(ctx context.Context, id int) error {
+	if err := s.BlogStoreInterface.Delete(ctx, id); err != nil {
+		return err
+	}
+	s.notify(BlogEvent{Type: BlogDeleted, ID: id})
+	return nil
+}
+
+// PublishDue publishes scheduled blogs and notifies listeners of each one
+func (s *ObservedBlogStore) PublishDue(ctx context.Context, now time.Time) ([]*models.Blog, error) {
+	published, err := s.BlogStoreInterface.PublishDue(ctx, now)
+	for _, blog := range published {
+		s.notify(BlogEvent{Type: BlogUpdated, ID: blog.ID, Blog: blog})
+	}
+	return published, err
+}
+
+// MergeTags merges tags and notifies listeners of each changed blog
+func (s *ObservedBlogStore) MergeTags(ctx context.Context, from []string, to string, updatedBy string) ([]*models.Blog, error) {
+	merged, err := s.BlogStoreInterface.MergeTags(ctx, from, to, updatedBy)
+	for _, blog := range merged {
+		s.notify(BlogEvent{Type: BlogUpdated, ID: blog.ID, Blog: blog})
+	}
+	return merged, err
+}
+
+// Batch applies a blog batch and notifies listeners of each applied operation
+func (s *ObservedBlogStore) Batch(ctx context.Context, ops []BlogBatchOp, atomic bool) ([]BlogBatchResult, error) {
+	results, err := s.BlogStoreInterface.Batch(ctx, ops, atomic)
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range results {
+		if result.Err != nil {
+			continue
+		}
+		switch ops[i].Type {
+		case models.BatchCreate:
+			s.notify(BlogEvent{Type: BlogCreated, ID: result.Blog.ID, Blog: result.Blog})
+		case models.BatchUpdate:
+			s.notify(BlogEvent{Type: BlogUpdated, ID: ops[i].ID, Blog: result.Blog})
+		case models.BatchDelete:
+			s.notify(BlogEvent{Type: BlogDeleted, ID: ops[i].ID})
+		}
+	}
+	return results, nil
+}
+
+func (s *ObservedBlogStore) notify(event BlogEvent) {
+	s.mu.RLock()
+	listeners := s.listeners
+	s.mu.RUnlock()
+
+	for _, l := range listeners {
+		l(event)
+	}
+}
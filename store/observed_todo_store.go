@@ -0,0 +1,174 @@
+package store
+
+import (
+	"apigo1/models"
+	"context"
+	"sync"
+)
+
+// TodoEventType identifies the kind of change reported to todo listeners
+type TodoEventType string
+
+const (
+	TodoCreated TodoEventType = "created"
+	TodoUpdated TodoEventType = "updated"
+	TodoDeleted TodoEventType = "deleted"
+)
+
+// TodoEvent describes a successful change to a todo. Todo is nil for deletions.
+type TodoEvent struct {
+	Type    TodoEventType
+	ID      int
+	OwnerID string
+	Todo    *models.Todo
+}
+
+// TodoListener is called after a todo has been changed
+type TodoListener func(TodoEvent)
+
+// ObservedTodoStore wraps a TodoStoreInterface and notifies listeners after every
+// successful Create, Update (and the next occurrence of a recurring todo it
+// creates), Move, Delete, checklist change (which updates the progress of the
+// todo), list deletion (which deletes or moves its todos) and batch operation, so
+// indexes built from todos can stay in sync with the store
+type ObservedTodoStore struct {
+	TodoStoreInterface
+	listeners []TodoListener
+	mu        sync.RWMutex
+}
+
+// NewObservedTodoStore creates a new ObservedTodoStore around s
+func NewObservedTodoStore(s TodoStoreInterface) *ObservedTodoStore {
+	return &ObservedTodoStore{TodoStoreInterface: s}
+}
+
+// Subscribe registers a listener for todo changes
+func (s *ObservedTodoStore) Subscribe(l TodoListener) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.listeners = append(s.listeners, l)
+}
+
+// Create creates a new todo and notifies listeners
+func (s *ObservedTodoStore) Create(ctx context.Context, todo *models.Todo) (*models.Todo, error) {
+	created, err := s.TodoStoreInterface.Create(ctx, todo)
+	if err != nil {
+		return nil, err
+	}
+	s.notify(TodoEvent{Type: TodoCreated, ID: created.ID, OwnerID: created.OwnerID, Todo: created})
+	return created, nil
+}
+
+// Update updates an existing todo and notifies listeners, also of the next
+// occurrence created by the update
+func (s *ObservedTodoStore) Update(ctx context.Context, ownerID string, id int, change TodoChange) (*models.Todo, *models.Todo, error) {
+	updated, next, err := s.TodoStoreInterface.Update(ctx, ownerID, id, change)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.notify(TodoEvent{Type: TodoUpdated, ID: id, OwnerID: ownerID, Todo: updated})
+	if next != nil {
+		s.notify(TodoEvent{Type: TodoCreated, ID: next.ID, OwnerID: ownerID, Todo: next})
+	}
+	return updated, next, nil
+}
+
+// Move moves a todo and notifies listeners
+func (s *ObservedTodoStore) Move(ctx context.Context, ownerID string, id int, beforeID, afterID int) (*models.Todo, error) {
+	moved, err := s.TodoStoreInterface.Move(ctx, ownerID, id, beforeID, afterID)
+	if err != nil {
+		return nil, err
+	}
+	s.notify(TodoEvent{Type: TodoUpdated, ID: id, OwnerID: ownerID, Todo: moved})
+	return moved, nil
+}
+
+// Delete deletes a todo by ID and notifies listeners
+func (s *ObservedTodoStore) Delete(ctx context.Context, ownerID string, id int) error {
+	if err := s.TodoStoreInterface.Delete(ctx, ownerID, id); err != nil {
+		return err
+	}
+	s.notify(TodoEvent{Type: TodoDeleted, ID: id, OwnerID: ownerID})
+	return nil
+}
+
+// CreateItem adds a checklist item and notifies listeners of the updated todo
+func (s *ObservedTodoStore) CreateItem(ctx context.Context, ownerID string, todoID int, item *models.ChecklistItem) (*models.ChecklistItem, *models.Todo, error) {
+	created, todo, err := s.TodoStoreInterface.CreateItem(ctx, ownerID, todoID, item)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.notify(TodoEvent{Type: TodoUpdated, ID: todoID, OwnerID: ownerID, Todo: todo})
+	return created, todo, nil
+}
+
+// UpdateItem updates a checklist item and notifies listeners of the updated todo
+func (s *ObservedTodoStore) UpdateItem(ctx context.Context, ownerID string, todoID int, itemID int, updatedItem *models.ChecklistItem) (*models.ChecklistItem, *models.Todo, error) {
+	updated, todo, err := s.TodoStoreInterface.UpdateItem(ctx, ownerID, todoID, itemID, updatedItem)
+	if err != nil {
+		return nil, nil, err
+	}
+	s.notify(TodoEvent{Type: TodoUpdated, ID: todoID, OwnerID: ownerID, Todo: todo})
+	return updated, todo, nil
+}
+
+// DeleteItem deletes a checklist item and notifies listeners of the updated todo
+func (s *ObservedTodoStore) DeleteItem(ctx context.Context, ownerID string, todoID int, itemID int) (*models.Todo, error) {
+	todo, err := s.TodoStoreInterface.DeleteItem(ctx, ownerID, todoID, itemID)
+	if err != nil {
+		return nil, err
+	}
+	s.notify(TodoEvent{Type: TodoUpdated, ID: todoID, OwnerID: ownerID, Todo: todo})
+	return todo, nil
+}
+
+// DeleteList deletes a todo list and notifies listeners of each deleted or moved todo
+func (s *ObservedTodoStore) DeleteList(ctx context.Context, ownerID string, id int, cascade bool, moveTo int) ([]*models.Todo, error) {
+	affected, err := s.TodoStoreInterface.DeleteList(ctx, ownerID, id, cascade, moveTo)
+	if err != nil {
+		return nil, err
+	}
+	for _, todo := range affected {
+		if cascade {
+			s.notify(TodoEvent{Type: TodoDeleted, ID: todo.ID, OwnerID: ownerID})
+		} else {
+			s.notify(TodoEvent{Type: TodoUpdated, ID: todo.ID, OwnerID: ownerID, Todo: todo})
+		}
+	}
+	return affected, nil
+}
+
+// Batch applies a todo batch and notifies listeners of each applied operation
+func (s *ObservedTodoStore) Batch(ctx context.Context, ownerID string, ops []TodoBatchOp, atomic bool) ([]TodoBatchResult, error) {
+	results, err := s.TodoStoreInterface.Batch(ctx, ownerID, ops, atomic)
+	if err != nil {
+		return nil, err
+	}
+	for i, result := range results {
+		if result.Err != nil {
+			continue
+		}
+		switch ops[i].Type {
+		case models.BatchCreate:
+			s.notify(TodoEvent{Type: TodoCreated, ID: result.Todo.ID, OwnerID: ownerID, Todo: result.Todo})
+		case models.BatchUpdate:
+			s.notify(TodoEvent{Type: TodoUpdated, ID: ops[i].ID, OwnerID: ownerID, Todo: result.Todo})
+			if result.Next != nil {
+				s.notify(TodoEvent{Type: TodoCreated, ID: result.Next.ID, OwnerID: ownerID, Todo: result.Next})
+			}
+		case models.BatchDelete:
+			s.notify(TodoEvent{Type: TodoDeleted, ID: ops[i].ID, OwnerID: ownerID})
+		}
+	}
+	return results, nil
+}
+
+func (s *ObservedTodoStore) notify(event TodoEvent) {
+	s.mu.RLock()
+	listeners := s.listeners
+	s.mu.RUnlock()
+
+	for _, l := range listeners {
+		l(event)
+	}
+}
@@ -0,0 +1,122 @@
+package store
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"strconv"
+
+	"cloud.google.com/go/firestore"
+	"google.golang.org/api/iterator"
+)
+
+// ErrInvalidCursor is returned when a pagination cursor cannot be decoded
+// or points to an item that no longer exists
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// ListOptions controls the filtering, sorting and paging of list queries
+type ListOptions struct {
+	// Limit is the maximum number of items per page, 0 means no limit
+	Limit int
+	// Cursor is the opaque next_cursor returned with the previous page
+	Cursor string
+	// Filters restricts the items returned, all filters must match
+	Filters []Filter
+	// Sort orders the items, the store default order is used when empty
+	Sort []SortField
+}
+
+// encodeCursor builds an opaque cursor pointing after the item with the given ID
+func encodeCursor(id int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(id)))
+}
+
+// decodeCursor returns the item ID stored in a cursor
+func decodeCursor(cursor string) (int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	id, err := strconv.Atoi(string(raw))
+	if err != nil {
+		return 0, ErrInvalidCursor
+	}
+	return id, nil
+}
+
+// paginate returns the page of already sorted items that follows opts.Cursor,
+// together with the cursor of the next page ("" when this is the last page)
+func paginate[T any](items []T, idOf func(T) int, opts ListOptions) ([]T, string, error) {
+	start := 0
+	if opts.Cursor != "" {
+		cursorID, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		start = -1
+		for i, item := range items {
+			if idOf(item) == cursorID {
+				start = i + 1
+				break
+			}
+		}
+		if start < 0 {
+			return nil, "", ErrInvalidCursor
+		}
+	}
+
+	items = items[start:]
+	if opts.Limit <= 0 || len(items) <= opts.Limit {
+		return items, "", nil
+	}
+	page := items[:opts.Limit]
+	return page, encodeCursor(idOf(page[len(page)-1])), nil
+}
+
+// firestorePage runs an ordered query and returns the page of documents that
+// follows opts.Cursor, together with the cursor of the next page
+func firestorePage(ctx context.Context, collection *firestore.CollectionRef, query firestore.Query, opts ListOptions) ([]*firestore.DocumentSnapshot, string, error) {
+	if opts.Cursor != "" {
+		cursorID, err := decodeCursor(opts.Cursor)
+		if err != nil {
+			return nil, "", err
+		}
+		// Starting after the cursor document reuses its values for every OrderBy field
+		cursorDoc, err := collection.Doc(strconv.Itoa(cursorID)).Get(ctx)
+		if err != nil {
+			if errors.Is(translateError(err), ErrNotFound) {
+				return nil, "", ErrInvalidCursor
+			}
+			return nil, "", translateError(err)
+		}
+		query = query.StartAfter(cursorDoc)
+	}
+	if opts.Limit > 0 {
+		// Fetch one extra document to know whether another page exists
+		query = query.Limit(opts.Limit + 1)
+	}
+
+	var docs []*firestore.DocumentSnapshot
+	iter := query.Documents(ctx)
+	defer iter.Stop()
+	for {
+		doc, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return nil, "", translateError(err)
+		}
+		docs = append(docs, doc)
+	}
+
+	if opts.Limit <= 0 || len(docs) <= opts.Limit {
+		return docs, "", nil
+	}
+	docs = docs[:opts.Limit]
+	lastID, err := strconv.Atoi(docs[len(docs)-1].Ref.ID)
+	if err != nil {
+		return nil, "", err
+	}
+	return docs, encodeCursor(lastID), nil
+}
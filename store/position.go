@@ -0,0 +1,91 @@
+package store
+
+import (
+	"apigo1/fracindex"
+	"apigo1/models"
+	"fmt"
+	"sort"
+)
+
+// sortTodos orders todos by position, then by ID for todos without one
+func sortTodos(todos []*models.Todo) {
+	sort.Slice(todos, func(i, j int) bool {
+		if todos[i].Position != todos[j].Position {
+			return todos[i].Position < todos[j].Position
+		}
+		return todos[i].ID < todos[j].ID
+	})
+}
+
+// positionBetween returns a position after lower and before upper, where "" is
+// unbounded. Bounds that are out of order, which a concurrent move can cause,
+// are reported as a conflict.
+func positionBetween(lower, upper string) (string, error) {
+	position, err := fracindex.KeyBetween(lower, upper)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrConflict, err)
+	}
+	return position, nil
+}
+
+// notAdjacentError is returned for moves between two anchors that are not
+// neighbours, which would leave the moved todo at an unspecified place among
+// the todos between them
+func notAdjacentError(afterID, beforeID int) error {
+	return fmt.Errorf("%w: todo %d is not right before todo %d", ErrInvalidQuery, afterID, beforeID)
+}
+
+// movedPosition returns the position of a todo moved right after the todo afterID
+// and/or right before the todo beforeID (0 when not given), or to the end without
+// anchors. When both are given, afterID must be right before beforeID. ordered
+// holds the other todos of the owner, sorted by position.
+func movedPosition(ordered []*models.Todo, beforeID, afterID int) (string, error) {
+	index := func(id int) (int, error) {
+		for i, todo := range ordered {
+			if todo.ID == id {
+				return i, nil
+			}
+		}
+		return 0, ErrNotFound
+	}
+
+	var lower, upper string
+	switch {
+	case afterID != 0 && beforeID != 0:
+		a, err := index(afterID)
+		if err != nil {
+			return "", err
+		}
+		b, err := index(beforeID)
+		if err != nil {
+			return "", err
+		}
+		if b != a+1 {
+			return "", notAdjacentError(afterID, beforeID)
+		}
+		lower, upper = ordered[a].Position, ordered[b].Position
+	case afterID != 0:
+		a, err := index(afterID)
+		if err != nil {
+			return "", err
+		}
+		lower = ordered[a].Position
+		if a+1 < len(ordered) {
+			upper = ordered[a+1].Position
+		}
+	case beforeID != 0:
+		b, err := index(beforeID)
+		if err != nil {
+			return "", err
+		}
+		upper = ordered[b].Position
+		if b > 0 {
+			lower = ordered[b-1].Position
+		}
+	default:
+		if len(ordered) > 0 {
+			lower = ordered[len(ordered)-1].Position
+		}
+	}
+	return positionBetween(lower, upper)
+}
@@ -0,0 +1,77 @@
+package store
+
+import (
+	"apigo1/models"
+	"errors"
+	"testing"
+)
+
+func TestPositionBetween(t *testing.T) {
+	tests := []struct {
+		name         string
+		lower, upper string
+	}{
+		{"empty list", "", ""},
+		{"append", "a0", ""},
+		{"prepend", "", "a0"},
+		{"between", "a0", "a1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			position, err := positionBetween(tt.lower, tt.upper)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (tt.lower != "" && position <= tt.lower) || (tt.upper != "" && position >= tt.upper) {
+				t.Errorf("positionBetween(%q, %q) = %q", tt.lower, tt.upper, position)
+			}
+		})
+	}
+
+	if _, err := positionBetween("a1", "a0"); !errors.Is(err, ErrConflict) {
+		t.Errorf("reversed bounds: got %v, want ErrConflict", err)
+	}
+}
+
+func TestMovedPosition(t *testing.T) {
+	ordered := []*models.Todo{
+		{ID: 1, Position: "a0"},
+		{ID: 2, Position: "a1"},
+		{ID: 3, Position: "a2"},
+	}
+	tests := []struct {
+		name           string
+		beforeID       int
+		afterID        int
+		wantLo, wantUp string
+		wantErr        error
+	}{
+		{"to the end", 0, 0, "a2", "", nil},
+		{"after", 0, 1, "a0", "a1", nil},
+		{"after the last", 0, 3, "a2", "", nil},
+		{"before", 2, 0, "a0", "a1", nil},
+		{"before the first", 1, 0, "", "a0", nil},
+		{"between neighbours", 2, 1, "a0", "a1", nil},
+		{"between distant anchors", 3, 1, "", "", ErrInvalidQuery},
+		{"between reversed anchors", 1, 2, "", "", ErrInvalidQuery},
+		{"same anchor", 2, 2, "", "", ErrInvalidQuery},
+		{"unknown anchor", 9, 0, "", "", ErrNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			position, err := movedPosition(ordered, tt.beforeID, tt.afterID)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("got %q, %v, want %v", position, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if (tt.wantLo != "" && position <= tt.wantLo) || (tt.wantUp != "" && position >= tt.wantUp) {
+				t.Errorf("got position %q, want between %q and %q", position, tt.wantLo, tt.wantUp)
+			}
+		})
+	}
+}
@@ -0,0 +1,337 @@
+package store
+
+import (
+	"apigo1/models"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/firestore"
+)
+
+// ErrInvalidQuery is returned when a list query uses an unknown field or operator
+var ErrInvalidQuery = errors.New("invalid query")
+
+// Filter restricts a list query to items whose field matches a value.
+// Field uses the API (JSON) field name, Op is one of the Firestore operators
+// "==", "!=", "<", "<=", ">", ">=" or "array-contains".
+type Filter struct {
+	Field string
+	Op    string
+	Value interface{}
+}
+
+// SortField orders a list query by one field
+type SortField struct {
+	Field string
+	Desc  bool
+}
+
+// todoFieldPaths maps todo API field names to Firestore field paths
+var todoFieldPaths = map[string]string{
+	"id":           "ID",
+	"title":        "Title",
+	"description":  "Description",
+	"completed":    "Completed",
+	"priority":     "PriorityRank",
+	"due_at":       "DueAt",
+	"completed_at": "CompletedAt",
+	"list_id":      "ListID",
+	"position":     "Position",
+	"owner_id":     "OwnerID",
+	"created_at":   "CreatedAt",
+	"updated_at":   "UpdatedAt",
+}
+
+// blogFieldPaths maps blog API field names to Firestore field paths
+var blogFieldPaths = map[string]string{
+	"id":         "ID",
+	"title":      "Title",
+	"slug":       "Slug",
+	"author":     "Author",
+	"author_id":  "AuthorID",
+	"published":  "Published",
+	"tags":       "Tags",
+	"created_at": "CreatedAt",
+	"updated_at": "UpdatedAt",
+}
+
+// todoDefaultSort orders todos by their manual position when a query has no sort fields
+var todoDefaultSort = []SortField{{Field: "position"}, {Field: "id"}}
+
+// blogDefaultSort orders blogs newest first when a query has no sort fields
+var blogDefaultSort = []SortField{{Field: "created_at", Desc: true}}
+
+// inequalityOps are the filter operators that Firestore only accepts when the
+// filtered field comes first in the order of the query
+var inequalityOps = map[string]bool{"<": true, "<=": true, ">": true, ">=": true, "!=": true}
+
+// querySort returns the order of a list query: the fields of inequality filters
+// first, as Firestore requires, then opts.Sort, or defaultSort when opts has no sort
+// fields. A filtered field that is also a sort field keeps its direction. Both
+// backends use this order so that they return the same pages.
+func querySort(opts ListOptions, defaultSort []SortField) []SortField {
+	requested := opts.Sort
+	if len(requested) == 0 {
+		requested = defaultSort
+	}
+
+	fields := make([]SortField, 0, len(requested)+1)
+	seen := make(map[string]bool)
+	for _, filter := range opts.Filters {
+		if !inequalityOps[filter.Op] || seen[filter.Field] {
+			continue
+		}
+		seen[filter.Field] = true
+		field := SortField{Field: filter.Field}
+		for _, s := range requested {
+			if s.Field == filter.Field {
+				field.Desc = s.Desc
+			}
+		}
+		fields = append(fields, field)
+	}
+	for _, field := range requested {
+		if !seen[field.Field] {
+			seen[field.Field] = true
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+// todoFieldValue returns the value of a todo API field, used by the in-memory store
+func todoFieldValue(todo *models.Todo, field string) interface{} {
+	switch field {
+	case "id":
+		return todo.ID
+	case "title":
+		return todo.Title
+	case "description":
+		return todo.Description
+	case "completed":
+		return todo.Completed
+	case "priority":
+		return todo.PriorityRank
+	case "due_at":
+		return optionalTime(todo.DueAt)
+	case "completed_at":
+		return optionalTime(todo.CompletedAt)
+	case "list_id":
+		return todo.ListID
+	case "position":
+		return todo.Position
+	case "owner_id":
+		return todo.OwnerID
+	case "created_at":
+		return todo.CreatedAt
+	case "updated_at":
+		return todo.UpdatedAt
+	}
+	return nil
+}
+
+// optionalTime returns the value of an optional time field, nil when it is not set
+func optionalTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+// blogFieldValue returns the value of a blog API field, used by the in-memory store
+func blogFieldValue(blog *models.Blog, field string) interface{} {
+	switch field {
+	case "id":
+		return blog.ID
+	case "title":
+		return blog.Title
+	case "slug":
+		return blog.Slug
+	case "author":
+		return blog.Author
+	case "author_id":
+		return blog.AuthorID
+	case "published":
+		return blog.Published
+	case "tags":
+		return blog.Tags
+	case "created_at":
+		return blog.CreatedAt
+	case "updated_at":
+		return blog.UpdatedAt
+	}
+	return nil
+}
+
+// withOwner returns a copy of opts restricted to items owned by ownerID
+func withOwner(opts ListOptions, ownerID string) ListOptions {
+	filters := make([]Filter, 0, len(opts.Filters)+1)
+	filters = append(filters, Filter{Field: "owner_id", Op: "==", Value: ownerID})
+	opts.Filters = append(filters, opts.Filters...)
+	return opts
+}
+
+// validateFields checks that every filter and sort field of opts is known
+func validateFields(paths map[string]string, opts ListOptions) error {
+	for _, filter := range opts.Filters {
+		if _, ok := paths[filter.Field]; !ok {
+			return fmt.Errorf("%w: unknown field %q", ErrInvalidQuery, filter.Field)
+		}
+	}
+	for _, field := range opts.Sort {
+		if _, ok := paths[field.Field]; !ok {
+			return fmt.Errorf("%w: unknown field %q", ErrInvalidQuery, field.Field)
+		}
+	}
+	return nil
+}
+
+// applyFirestoreQuery adds the filters and sort order of opts to a Firestore query.
+// The order is given by querySort.
+func applyFirestoreQuery(query firestore.Query, paths map[string]string, opts ListOptions, defaultSort []SortField) (firestore.Query, error) {
+	if err := validateFields(paths, opts); err != nil {
+		return query, err
+	}
+
+	for _, filter := range opts.Filters {
+		query = query.Where(paths[filter.Field], filter.Op, filter.Value)
+	}
+
+	for _, field := range querySort(opts, defaultSort) {
+		direction := firestore.Asc
+		if field.Desc {
+			direction = firestore.Desc
+		}
+		query = query.OrderBy(paths[field.Field], direction)
+	}
+
+	return query, nil
+}
+
+// filterAndSort applies the filters and sort order of opts to in-memory items,
+// ordered by querySort like Firestore does. valueOf returns the value of an API
+// field for an item; items with equal sort keys are ordered by ID.
+func filterAndSort[T any](items []T, paths map[string]string, valueOf func(T, string) interface{}, idOf func(T) int, opts ListOptions, defaultSort []SortField) ([]T, error) {
+	if err := validateFields(paths, opts); err != nil {
+		return nil, err
+	}
+
+	matched := make([]T, 0, len(items))
+	for _, item := range items {
+		ok, err := matchesFilters(item, valueOf, opts.Filters)
+		if err != nil {
+			return nil, err
+		}
+		if ok {
+			matched = append(matched, item)
+		}
+	}
+
+	sortFields := querySort(opts, defaultSort)
+	sort.SliceStable(matched, func(i, j int) bool {
+		for _, field := range sortFields {
+			c := compareValues(valueOf(matched[i], field.Field), valueOf(matched[j], field.Field))
+			if c != 0 {
+				if field.Desc {
+					return c > 0
+				}
+				return c < 0
+			}
+		}
+		return idOf(matched[i]) < idOf(matched[j])
+	})
+
+	return matched, nil
+}
+
+// matchesFilters reports whether an item satisfies every filter
+func matchesFilters[T any](item T, valueOf func(T, string) interface{}, filters []Filter) (bool, error) {
+	for _, filter := range filters {
+		value := valueOf(item, filter.Field)
+		// Like null in Firestore, a missing value only matches "==" on another missing value
+		if value == nil && filter.Op != "==" {
+			return false, nil
+		}
+
+		var match bool
+		switch filter.Op {
+		case "array-contains":
+			values, _ := value.([]string)
+			for _, v := range values {
+				if v == filter.Value {
+					match = true
+					break
+				}
+			}
+		case "==":
+			match = compareValues(value, filter.Value) == 0
+		case "!=":
+			match = compareValues(value, filter.Value) != 0
+		case "<":
+			match = compareValues(value, filter.Value) < 0
+		case "<=":
+			match = compareValues(value, filter.Value) <= 0
+		case ">":
+			match = compareValues(value, filter.Value) > 0
+		case ">=":
+			match = compareValues(value, filter.Value) >= 0
+		default:
+			return false, fmt.Errorf("%w: unknown operator %q", ErrInvalidQuery, filter.Op)
+		}
+		if !match {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// compareValues orders two field values of the same type, like Firestore does:
+// missing values (nil) first, false < true, numbers and times ascending, strings lexicographically
+func compareValues(a, b interface{}) int {
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		}
+		return 1
+	}
+
+	switch a := a.(type) {
+	case bool:
+		b, _ := b.(bool)
+		switch {
+		case a == b:
+			return 0
+		case !a:
+			return -1
+		default:
+			return 1
+		}
+	case int:
+		b, _ := b.(int)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	case string:
+		b, _ := b.(string)
+		switch {
+		case a < b:
+			return -1
+		case a > b:
+			return 1
+		}
+		return 0
+	case time.Time:
+		b, _ := b.(time.Time)
+		return a.Compare(b)
+	}
+	return 0
+}
@@ -0,0 +1,162 @@
+package store
+
+import (
+	"apigo1/models"
+	"context"
+	"encoding/base64"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestListMultiKeySortIsStable(t *testing.T) {
+	ctx := context.Background()
+	s := NewTodoStore()
+	for _, todo := range []*models.Todo{
+		{Title: "a", Completed: true, Priority: models.PriorityHigh},
+		{Title: "b", Priority: models.PriorityLow},
+		{Title: "c", Priority: models.PriorityHigh},
+		{Title: "d", Completed: true, Priority: models.PriorityHigh},
+		{Title: "e", Priority: models.PriorityHigh},
+		{Title: "f", Priority: models.PriorityLow},
+	} {
+		todo.OwnerID = "alice"
+		s.Create(ctx, todo)
+	}
+
+	opts := ListOptions{Sort: []SortField{{Field: "completed"}, {Field: "priority", Desc: true}}}
+	want := "cebfad"
+	// Ties keep the ID order on every page, whatever the order of the map behind the store
+	for run := 0; run < 10; run++ {
+		todos, _, err := s.List(ctx, "alice", opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := titles(todos); got != want {
+			t.Fatalf("List sorted by completed, -priority = %q, want %q", got, want)
+		}
+	}
+
+	var got string
+	paged := opts
+	paged.Limit = 4
+	for {
+		todos, next, err := s.List(ctx, "alice", paged)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got += titles(todos)
+		if next == "" {
+			break
+		}
+		paged.Cursor = next
+	}
+	if got != want {
+		t.Errorf("paged List = %q, want %q", got, want)
+	}
+}
+
+func TestListInvalidCursor(t *testing.T) {
+	ctx := context.Background()
+	s := NewTodoStore()
+	open, _ := s.Create(ctx, &models.Todo{Title: "open", OwnerID: "alice"})
+	done, _ := s.Create(ctx, &models.Todo{Title: "done", OwnerID: "alice", Completed: true})
+	foreign, _ := s.Create(ctx, &models.Todo{Title: "foreign", OwnerID: "bob"})
+
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "!!!"},
+		{"not an ID", base64.RawURLEncoding.EncodeToString([]byte("abc"))},
+		{"unknown todo", encodeCursor(99)},
+		{"other owner's todo", encodeCursor(foreign.ID)},
+		{"filtered out todo", encodeCursor(done.ID)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := ListOptions{
+				Cursor:  tt.cursor,
+				Filters: []Filter{{Field: "completed", Op: "==", Value: false}},
+			}
+			if _, _, err := s.List(ctx, "alice", opts); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("List with cursor %q: got %v, want ErrInvalidCursor", tt.cursor, err)
+			}
+		})
+	}
+
+	todos, next, err := s.List(ctx, "alice", ListOptions{Cursor: encodeCursor(open.ID)})
+	if err != nil || next != "" || titles(todos) != "done" {
+		t.Errorf("List after %q = %q, %q, %v, want done", "open", titles(todos), next, err)
+	}
+}
+
+func TestQuerySort(t *testing.T) {
+	dueBefore := Filter{Field: "due_at", Op: "<", Value: "2025-01-01"}
+	tests := []struct {
+		name    string
+		filters []Filter
+		sort    []SortField
+		want    []SortField
+	}{
+		{"default", nil, nil, todoDefaultSort},
+		{"equality filter", []Filter{{Field: "completed", Op: "==", Value: false}}, nil, todoDefaultSort},
+		{"inequality filter", []Filter{dueBefore}, nil,
+			[]SortField{{Field: "due_at"}, {Field: "position"}, {Field: "id"}}},
+		{"inequality filter and sort", []Filter{dueBefore}, []SortField{{Field: "priority", Desc: true}},
+			[]SortField{{Field: "due_at"}, {Field: "priority", Desc: true}}},
+		{"filtered field sorted", []Filter{dueBefore}, []SortField{{Field: "priority"}, {Field: "due_at", Desc: true}},
+			[]SortField{{Field: "due_at", Desc: true}, {Field: "priority"}}},
+		{"two filters on one field", []Filter{dueBefore, {Field: "due_at", Op: ">", Value: "2024-01-01"}}, nil,
+			[]SortField{{Field: "due_at"}, {Field: "position"}, {Field: "id"}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := querySort(ListOptions{Filters: tt.filters, Sort: tt.sort}, todoDefaultSort)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("querySort() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestListInequalityFilterOrdersByTheFilteredField(t *testing.T) {
+	ctx := context.Background()
+	s := NewTodoStore()
+	now := time.Now()
+	for _, todo := range []*models.Todo{
+		{Title: "a", DueAt: ptr(now.Add(-time.Hour)), Priority: models.PriorityLow},
+		{Title: "b", DueAt: ptr(now.Add(-3 * time.Hour)), Priority: models.PriorityHigh},
+		{Title: "c", DueAt: ptr(now.Add(time.Hour))},
+		{Title: "d", DueAt: ptr(now.Add(-2 * time.Hour)), Priority: models.PriorityUrgent},
+	} {
+		todo.OwnerID = "alice"
+		s.Create(ctx, todo)
+	}
+
+	opts := ListOptions{
+		Filters: []Filter{{Field: "due_at", Op: "<", Value: now}},
+		Sort:    []SortField{{Field: "priority", Desc: true}},
+	}
+	todos, _, err := s.List(ctx, "alice", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := titles(todos); got != "bda" {
+		t.Errorf("List due before now sorted by -priority = %q, want %q", got, "bda")
+	}
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
+
+// titles concatenates the titles of todos, in order
+func titles(todos []*models.Todo) string {
+	var s string
+	for _, todo := range todos {
+		s += todo.Title
+	}
+	return s
+}
@@ -0,0 +1,32 @@
+package store
+
+import "apigo1/models"
+
+// nextTodo returns the todo of the occurrence following todo when todo has just
+// been completed, or nil when it does not recur, was already completed, already
+// has a next occurrence or ends its series. The caller assigns the ID and
+// timestamps and sets todo.NextTodoID. The checklist is not carried over.
+func nextTodo(todo *models.Todo, wasCompleted bool) *models.Todo {
+	if wasCompleted || !todo.Completed || todo.Recurrence == "" || todo.NextTodoID != 0 {
+		return nil
+	}
+	occurrences := todo.NextOccurrences(1)
+	if len(occurrences) == 0 {
+		return nil
+	}
+
+	dueAt := occurrences[0]
+	next := &models.Todo{
+		Title:        todo.Title,
+		Description:  todo.Description,
+		Priority:     todo.Priority,
+		DueAt:        &dueAt,
+		AutoComplete: todo.AutoComplete,
+		Recurrence:   todo.Recurrence,
+		Occurrence:   todo.Occurrence + 1,
+		ListID:       todo.ListID,
+		OwnerID:      todo.OwnerID,
+	}
+	prepareTodo(next)
+	return next
+}
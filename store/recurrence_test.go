@@ -0,0 +1,62 @@
+package store
+
+import (
+	"apigo1/models"
+	"testing"
+	"time"
+)
+
+func TestNextTodo(t *testing.T) {
+	dueAt := time.Date(2025, 1, 6, 9, 0, 0, 0, time.UTC)
+	recurring := func(recurrence string, occurrence int) *models.Todo {
+		return &models.Todo{
+			Title:      "Standup",
+			Completed:  true,
+			DueAt:      &dueAt,
+			Recurrence: recurrence,
+			Occurrence: occurrence,
+			OwnerID:    "alice",
+		}
+	}
+	tests := []struct {
+		name         string
+		todo         *models.Todo
+		wasCompleted bool
+		wantDueAt    time.Time // zero when no next todo is spawned
+	}{
+		{"daily", recurring("FREQ=DAILY", 1), false, dueAt.AddDate(0, 0, 1)},
+		{"weekly by day", recurring("FREQ=WEEKLY;BYDAY=MO,TH", 1), false, dueAt.AddDate(0, 0, 3)},
+		{"before COUNT is reached", recurring("FREQ=DAILY;COUNT=3", 2), false, dueAt.AddDate(0, 0, 1)},
+		{"COUNT exhausted", recurring("FREQ=DAILY;COUNT=3", 3), false, time.Time{}},
+		{"past UNTIL", recurring("FREQ=DAILY;UNTIL=20250106", 1), false, time.Time{}},
+		{"already completed", recurring("FREQ=DAILY", 1), true, time.Time{}},
+		{"not recurring", recurring("", 0), false, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := nextTodo(tt.todo, tt.wasCompleted)
+			if tt.wantDueAt.IsZero() {
+				if next != nil {
+					t.Fatalf("spawned %+v, want no next todo", next)
+				}
+				return
+			}
+			if next == nil {
+				t.Fatal("no next todo spawned")
+			}
+			if next.DueAt == nil || !next.DueAt.Equal(tt.wantDueAt) {
+				t.Errorf("next due at %v, want %v", next.DueAt, tt.wantDueAt)
+			}
+			if next.Occurrence != tt.todo.Occurrence+1 || next.Completed || next.OwnerID != tt.todo.OwnerID || next.Recurrence != tt.todo.Recurrence {
+				t.Errorf("next todo %+v does not continue %+v", next, tt.todo)
+			}
+		})
+	}
+
+	// A todo whose next occurrence was already spawned does not spawn another one
+	todo := recurring("FREQ=DAILY", 1)
+	todo.NextTodoID = 7
+	if next := nextTodo(todo, false); next != nil {
+		t.Errorf("spawned %+v for a todo with a next occurrence", next)
+	}
+}
@@ -0,0 +1,22 @@
+package store
+
+import "apigo1/models"
+
+// revisionsCollection is the subcollection holding the revisions of a blog (blogs/{id}/revisions)
+const revisionsCollection = "revisions"
+
+// newRevision snapshots the current state of a blog as its revision blog.Revision
+func newRevision(blog *models.Blog) *models.BlogRevision {
+	return &models.BlogRevision{
+		BlogID:    blog.ID,
+		Revision:  blog.Revision,
+		Title:     blog.Title,
+		Content:   blog.Content,
+		Slug:      blog.Slug,
+		Author:    blog.Author,
+		Published: blog.Published,
+		Tags:      append([]string(nil), blog.Tags...),
+		EditorID:  blog.UpdatedBy,
+		CreatedAt: blog.UpdatedAt,
+	}
+}
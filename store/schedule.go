@@ -0,0 +1,23 @@
+package store
+
+import (
+	"apigo1/models"
+	"errors"
+	"time"
+)
+
+// errNotDue aborts a scheduled publish when the blog is no longer due
+var errNotDue = errors.New("blog is not due for publishing")
+
+// publishIfDue publishes a scheduled blog whose PublishAt is not after now,
+// and returns errNotDue otherwise
+func publishIfDue(blog *models.Blog, now time.Time) error {
+	if blog.Published || blog.PublishAt == nil || blog.PublishAt.After(now) {
+		return errNotDue
+	}
+	blog.Published = true
+	blog.PublishAt = nil
+	// The scheduler, not a user, made this change
+	blog.UpdatedBy = ""
+	return nil
+}
@@ -0,0 +1,53 @@
+package store
+
+import (
+	"apigo1/models"
+	"fmt"
+	"strconv"
+)
+
+// slugsCollection holds one document per reserved slug (slugs/{slug}), pointing to
+// the blog using it. Previous slugs of a blog stay reserved so they can redirect.
+const slugsCollection = "slugs"
+
+// maxSlugAttempts bounds the number of suffixes tried to find a free slug
+const maxSlugAttempts = 100
+
+// fallbackSlug is used when a blog has no usable slug
+const fallbackSlug = "blog"
+
+// slugReservation is the document stored in the slugs collection
+type slugReservation struct {
+	BlogID int
+}
+
+// slugCandidate returns the n-th candidate for a base slug: base, base-2, base-3, ...
+func slugCandidate(base string, n int) string {
+	if base == "" {
+		base = fallbackSlug
+	}
+	if n == 1 {
+		return base
+	}
+	return base + "-" + strconv.Itoa(n)
+}
+
+// errNoFreeSlug is returned when every candidate slug is taken
+func errNoFreeSlug(base string) error {
+	return fmt.Errorf("%w: no free slug for %q", ErrConflict, base)
+}
+
+// retireSlug records oldSlug as a previous slug of blog after its slug changed,
+// and drops the new slug from the history when the blog takes back an old slug
+func retireSlug(blog *models.Blog, oldSlug string) {
+	previous := make([]string, 0, len(blog.PreviousSlugs)+1)
+	for _, slug := range blog.PreviousSlugs {
+		if slug != blog.Slug && slug != oldSlug {
+			previous = append(previous, slug)
+		}
+	}
+	if oldSlug != "" && oldSlug != blog.Slug {
+		previous = append(previous, oldSlug)
+	}
+	blog.PreviousSlugs = previous
+}
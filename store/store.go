@@ -2,87 +2,447 @@ package store
 
 import (
 	"apigo1/models"
+	"context"
 	"sync"
+	"time"
 )
 
 // TodoStore manages todos in memory
 type TodoStore struct {
-	todos map[int]*models.Todo
-	mu    sync.RWMutex
-	nextID int
+	todos      map[int]*models.Todo
+	items      map[int][]*models.ChecklistItem // checklist of each todo, by position
+	mu         sync.RWMutex
+	lists      map[int]*models.TodoList
+	nextID     int
+	nextItemID int
+	nextListID int
 }
 
 // NewTodoStore creates a new TodoStore
 func NewTodoStore() *TodoStore {
 	return &TodoStore{
-		todos:  make(map[int]*models.Todo),
-		nextID: 1,
+		todos:      make(map[int]*models.Todo),
+		items:      make(map[int][]*models.ChecklistItem),
+		lists:      make(map[int]*models.TodoList),
+		nextID:     1,
+		nextItemID: 1,
+		nextListID: 1,
 	}
 }
 
-// GetAll returns all todos
-func (s *TodoStore) GetAll() []*models.Todo {
+// GetAll returns all todos owned by ownerID, ordered by position
+func (s *TodoStore) GetAll(ctx context.Context, ownerID string) ([]*models.Todo, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	todos := make([]*models.Todo, 0, len(s.todos))
 	for _, todo := range s.todos {
-		todos = append(todos, todo)
+		if todo.OwnerID == ownerID {
+			todos = append(todos, todo)
+		}
 	}
-	return todos
+	sortTodos(todos)
+	return todos, nil
+}
+
+// GetAllOwners returns the todos of every owner, leaving out todos without one
+func (s *TodoStore) GetAllOwners(ctx context.Context) ([]*models.Todo, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	todos := make([]*models.Todo, 0, len(s.todos))
+	for _, todo := range s.todos {
+		if todo.OwnerID != "" {
+			todos = append(todos, todo)
+		}
+	}
+	return todos, nil
+}
+
+// List returns one page of todos owned by ownerID and matching opts, ordered by
+// position unless opts.Sort is set, together with the cursor of the next page ("" on the last page)
+func (s *TodoStore) List(ctx context.Context, ownerID string, opts ListOptions) ([]*models.Todo, string, error) {
+	todos, _ := s.GetAll(ctx, ownerID)
+	idOf := func(todo *models.Todo) int { return todo.ID }
+
+	todos, err := filterAndSort(todos, todoFieldPaths, todoFieldValue, idOf, opts, todoDefaultSort)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return paginate(todos, idOf, opts)
 }
 
 // GetByID returns a todo by ID
-func (s *TodoStore) GetByID(id int) (*models.Todo, bool) {
+func (s *TodoStore) GetByID(ctx context.Context, ownerID string, id int) (*models.Todo, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
+	// Other users' todos are reported as missing so their existence does not leak
 	todo, exists := s.todos[id]
-	return todo, exists
+	if !exists || todo.OwnerID != ownerID {
+		return nil, ErrNotFound
+	}
+	return todo, nil
 }
 
 // Create creates a new todo
-func (s *TodoStore) Create(todo *models.Todo) *models.Todo {
+func (s *TodoStore) Create(ctx context.Context, todo *models.Todo) (*models.Todo, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
+	position, err := positionBetween(s.lastPosition(todo.OwnerID), "")
+	if err != nil {
+		return nil, err
+	}
+
 	todo.ID = s.nextID
 	s.nextID++
+	todo.Position = position
+	prepareTodo(todo)
 	s.todos[todo.ID] = todo
-	return todo
+	return todo, nil
+}
+
+// Update applies a change to an existing todo and returns it with the next
+// occurrence it creates, if any. An error returned by change leaves the todo
+// unchanged and is returned as is.
+func (s *TodoStore) Update(ctx context.Context, ownerID string, id int, change TodoChange) (*models.Todo, *models.Todo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	todo, exists := s.todos[id]
+	if !exists || todo.OwnerID != ownerID {
+		return nil, nil, ErrNotFound
+	}
+
+	wasCompleted := todo.Completed
+	updated := *todo
+	if err := applyTodoChange(&updated, change, time.Now()); err != nil {
+		return nil, nil, err
+	}
+	*todo = updated
+
+	// Completing an occurrence of a recurring todo creates the next one
+	next := nextTodo(todo, wasCompleted)
+	if next != nil {
+		position, err := positionBetween(s.lastPosition(ownerID), "")
+		if err != nil {
+			return nil, nil, err
+		}
+		next.ID = s.nextID
+		s.nextID++
+		next.Position = position
+		next.CreatedAt = todo.UpdatedAt
+		next.UpdatedAt = todo.UpdatedAt
+		s.todos[next.ID] = next
+		todo.NextTodoID = next.ID
+	}
+
+	return todo, next, nil
 }
 
-// Update updates an existing todo
-func (s *TodoStore) Update(id int, updatedTodo *models.Todo) (*models.Todo, bool) {
+// Move moves a todo right after the todo afterID and/or right before the todo
+// beforeID (0 when not given), or to the end without anchors. When both are given,
+// afterID must be right before beforeID.
+func (s *TodoStore) Move(ctx context.Context, ownerID string, id int, beforeID, afterID int) (*models.Todo, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	todo, exists := s.todos[id]
-	if !exists {
-		return nil, false
+	if !exists || todo.OwnerID != ownerID {
+		return nil, ErrNotFound
 	}
 
-	if updatedTodo.Title != "" {
-		todo.Title = updatedTodo.Title
+	others := make([]*models.Todo, 0, len(s.todos))
+	for _, other := range s.todos {
+		if other.OwnerID == ownerID && other.ID != id {
+			others = append(others, other)
+		}
 	}
-	if updatedTodo.Description != "" {
-		todo.Description = updatedTodo.Description
+	sortTodos(others)
+
+	position, err := movedPosition(others, beforeID, afterID)
+	if err != nil {
+		return nil, err
 	}
-	todo.Completed = updatedTodo.Completed
-	todo.UpdatedAt = updatedTodo.UpdatedAt
+	todo.Position = position
+	todo.UpdatedAt = time.Now()
+	return todo, nil
+}
 
-	return todo, true
+// lastPosition returns the highest position among the todos of ownerID, "" without todos.
+// s.mu must be held.
+func (s *TodoStore) lastPosition(ownerID string) string {
+	last := ""
+	for _, todo := range s.todos {
+		if todo.OwnerID == ownerID && todo.Position > last {
+			last = todo.Position
+		}
+	}
+	return last
 }
 
 // Delete deletes a todo by ID
-func (s *TodoStore) Delete(id int) bool {
+func (s *TodoStore) Delete(ctx context.Context, ownerID string, id int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if todo, exists := s.todos[id]; !exists || todo.OwnerID != ownerID {
+		return ErrNotFound
+	}
+	delete(s.todos, id)
+	delete(s.items, id)
+	return nil
+}
+
+// ListItems returns the checklist of a todo, by position
+func (s *TodoStore) ListItems(ctx context.Context, ownerID string, todoID int) ([]*models.ChecklistItem, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if todo, exists := s.todos[todoID]; !exists || todo.OwnerID != ownerID {
+		return nil, ErrNotFound
+	}
+	return append([]*models.ChecklistItem{}, s.items[todoID]...), nil
+}
+
+// CreateItem appends an item to the checklist of a todo and returns it with the updated todo
+func (s *TodoStore) CreateItem(ctx context.Context, ownerID string, todoID int, item *models.ChecklistItem) (*models.ChecklistItem, *models.Todo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	todo, exists := s.todos[todoID]
+	if !exists || todo.OwnerID != ownerID {
+		return nil, nil, ErrNotFound
+	}
+
+	now := time.Now()
+	item.ID = s.nextItemID
+	s.nextItemID++
+	item.TodoID = todoID
+	item.Position = nextItemPosition(s.items[todoID])
+	item.CreatedAt = now
+	item.UpdatedAt = now
+	s.items[todoID] = append(s.items[todoID], item)
+
+	applyProgress(todo, s.items[todoID], now)
+	todo.UpdatedAt = now
+	return item, todo, nil
+}
+
+// UpdateItem updates a checklist item and returns it with the updated todo
+func (s *TodoStore) UpdateItem(ctx context.Context, ownerID string, todoID int, itemID int, updatedItem *models.ChecklistItem) (*models.ChecklistItem, *models.Todo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	todo, exists := s.todos[todoID]
+	if !exists || todo.OwnerID != ownerID {
+		return nil, nil, ErrNotFound
+	}
+	items := s.items[todoID]
+	for _, item := range items {
+		if item.ID != itemID {
+			continue
+		}
+		updatedItem.UpdatedAt = time.Now()
+		mergeItem(item, updatedItem)
+		applyProgress(todo, items, item.UpdatedAt)
+		todo.UpdatedAt = item.UpdatedAt
+		return item, todo, nil
+	}
+	return nil, nil, ErrNotFound
+}
+
+// DeleteItem deletes a checklist item and returns the updated todo
+func (s *TodoStore) DeleteItem(ctx context.Context, ownerID string, todoID int, itemID int) (*models.Todo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	todo, exists := s.todos[todoID]
+	if !exists || todo.OwnerID != ownerID {
+		return nil, ErrNotFound
+	}
+	items := s.items[todoID]
+	for i, item := range items {
+		if item.ID != itemID {
+			continue
+		}
+		items = append(items[:i:i], items[i+1:]...)
+		s.items[todoID] = items
+
+		now := time.Now()
+		applyProgress(todo, items, now)
+		todo.UpdatedAt = now
+		return todo, nil
+	}
+	return nil, ErrNotFound
+}
+
+// ReorderItems moves the items of a checklist to the order of itemIDs, which must
+// list every item of the todo once
+func (s *TodoStore) ReorderItems(ctx context.Context, ownerID string, todoID int, itemIDs []int) ([]*models.ChecklistItem, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	todo, exists := s.todos[todoID]
+	if !exists || todo.OwnerID != ownerID {
+		return nil, ErrNotFound
+	}
+	// Reorder a copy so that a rejected order leaves the checklist untouched
+	items := make([]*models.ChecklistItem, 0, len(s.items[todoID]))
+	for _, item := range s.items[todoID] {
+		copied := *item
+		items = append(items, &copied)
+	}
+	if err := reorderItems(items, itemIDs); err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	for _, item := range items {
+		item.UpdatedAt = now
+	}
+	s.items[todoID] = items
+	todo.UpdatedAt = now
+	return append([]*models.ChecklistItem{}, items...), nil
+}
+
+// GetLists returns the todo lists owned by ownerID, ordered by ID
+func (s *TodoStore) GetLists(ctx context.Context, ownerID string) ([]*models.TodoList, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	lists := []*models.TodoList{}
+	for _, list := range s.lists {
+		if list.OwnerID == ownerID {
+			lists = append(lists, list)
+		}
+	}
+	sortLists(lists)
+	return lists, nil
+}
+
+// GetList returns a todo list by ID
+func (s *TodoStore) GetList(ctx context.Context, ownerID string, id int) (*models.TodoList, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list, exists := s.lists[id]
+	if !exists || list.OwnerID != ownerID {
+		return nil, ErrNotFound
+	}
+	return list, nil
+}
+
+// CreateList creates a new todo list
+func (s *TodoStore) CreateList(ctx context.Context, list *models.TodoList) (*models.TodoList, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	list.ID = s.nextListID
+	s.nextListID++
+	list.CreatedAt = now
+	list.UpdatedAt = now
+	s.lists[list.ID] = list
+	return list, nil
+}
+
+// UpdateList renames a todo list
+func (s *TodoStore) UpdateList(ctx context.Context, ownerID string, id int, updatedList *models.TodoList) (*models.TodoList, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, exists := s.lists[id]
+	if !exists || list.OwnerID != ownerID {
+		return nil, ErrNotFound
+	}
+	list.Name = updatedList.Name
+	list.UpdatedAt = time.Now()
+	return list, nil
+}
+
+// DeleteList deletes a todo list, deleting its todos when cascade is set and
+// moving them to moveTo (0 for no list) otherwise
+func (s *TodoStore) DeleteList(ctx context.Context, ownerID string, id int, cascade bool, moveTo int) ([]*models.Todo, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	_, exists := s.todos[id]
-	if exists {
-		delete(s.todos, id)
+	if list, exists := s.lists[id]; !exists || list.OwnerID != ownerID {
+		return nil, ErrNotFound
+	}
+	if !cascade && moveTo != 0 {
+		if target, exists := s.lists[moveTo]; !exists || target.OwnerID != ownerID || moveTo == id {
+			return nil, ErrNotFound
+		}
+	}
+
+	now := time.Now()
+	affected := []*models.Todo{}
+	for todoID, todo := range s.todos {
+		if todo.OwnerID != ownerID || todo.ListID != id {
+			continue
+		}
+		if cascade {
+			delete(s.todos, todoID)
+			delete(s.items, todoID)
+		} else {
+			todo.ListID = moveTo
+			todo.UpdatedAt = now
+		}
+		affected = append(affected, todo)
 	}
-	return exists
+	delete(s.lists, id)
+	return affected, nil
 }
 
+// Batch applies the operations of a todo batch of ownerID under the store lock and
+// returns one result per operation. Every operation is checked before the first one
+// is applied: an atomic batch with a failed operation changes nothing, otherwise the
+// failed operations are skipped.
+func (s *TodoStore) Batch(ctx context.Context, ownerID string, ops []TodoBatchOp, atomic bool) ([]TodoBatchResult, error) {
+	if err := checkBatchSize(len(ops)); err != nil {
+		return nil, err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing := make(map[int]*models.Todo)
+	for _, op := range ops {
+		if todo, exists := s.todos[op.ID]; exists && todo.OwnerID == ownerID {
+			existing[op.ID] = todo
+		}
+	}
+
+	results := planTodoBatch(ownerID, ops, existing, s.lastPosition(ownerID), time.Now())
+	if abortTodoBatch(results, atomic) {
+		return results, nil
+	}
+
+	for i, op := range ops {
+		result := &results[i]
+		if result.Err != nil {
+			continue
+		}
+		switch op.Type {
+		case models.BatchCreate:
+			result.Todo.ID = s.nextID
+			s.nextID++
+			s.todos[result.Todo.ID] = result.Todo
+		case models.BatchUpdate:
+			if result.Next != nil {
+				result.Next.ID = s.nextID
+				s.nextID++
+				s.todos[result.Next.ID] = result.Next
+				result.Todo.NextTodoID = result.Next.ID
+			}
+			// The stored todo keeps its identity, as with Update
+			*s.todos[op.ID] = *result.Todo
+			result.Todo = s.todos[op.ID]
+		case models.BatchDelete:
+			delete(s.todos, op.ID)
+			delete(s.items, op.ID)
+		}
+	}
+	return results, nil
+}
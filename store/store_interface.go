@@ -1,13 +1,99 @@
 package store
 
-import "apigo1/models"
+import (
+	"apigo1/models"
+	"context"
+	"time"
+)
 
-// TodoStoreInterface defines the interface for todo storage
+// TodoStoreInterface defines the interface for todo storage.
+// Todos are scoped to their owner: reads and writes with another ownerID behave
+// as if the todo did not exist.
+// GetAllOwners is the only unscoped method: it serves internal indexes and must
+// not be exposed through the API. Todos without an owner, stored before todos had
+// one, are reachable by nobody and left out of GetAllOwners too.
+// Todos are ordered by Position, a fractional index key: Create appends a todo
+// after the last one of its owner and Move rewrites the position of the moved todo
+// only. GetAll and List without sort fields return todos by position.
+// Todos own an ordered checklist; item changes keep the progress fields of the
+// todo (ItemCount, ItemsDone, Progress) in sync and return the updated todo.
+// Update returns the updated todo and, when the update completes an occurrence of a
+// recurring todo, the next occurrence it creates (nil otherwise).
+// Todos may belong to one of their owner's lists (ListID). DeleteList either deletes
+// the todos of the list (cascade) or moves them to moveTo (0 for no list), and
+// returns the affected todos as they were before the deletion or after the move.
+// Batch applies creates, updates and deletes together and returns one result per
+// operation; an atomic batch applies all of them or, when one fails, none, and the
+// others then fail with ErrBatchAborted. A batch of more than MaxBatchOperations
+// operations fails with ErrBatchTooLarge.
+// Methods return ErrNotFound, ErrConflict or ErrUnavailable (possibly wrapped).
 type TodoStoreInterface interface {
-	GetAll() []*models.Todo
-	GetByID(id int) (*models.Todo, bool)
-	Create(todo *models.Todo) *models.Todo
-	Update(id int, updatedTodo *models.Todo) (*models.Todo, bool)
-	Delete(id int) bool
+	GetAll(ctx context.Context, ownerID string) ([]*models.Todo, error)
+	GetAllOwners(ctx context.Context) ([]*models.Todo, error)
+	List(ctx context.Context, ownerID string, opts ListOptions) ([]*models.Todo, string, error)
+	GetByID(ctx context.Context, ownerID string, id int) (*models.Todo, error)
+	Create(ctx context.Context, todo *models.Todo) (*models.Todo, error)
+	Update(ctx context.Context, ownerID string, id int, change TodoChange) (*models.Todo, *models.Todo, error)
+	Delete(ctx context.Context, ownerID string, id int) error
+	Move(ctx context.Context, ownerID string, id int, beforeID, afterID int) (*models.Todo, error)
+	ListItems(ctx context.Context, ownerID string, todoID int) ([]*models.ChecklistItem, error)
+	CreateItem(ctx context.Context, ownerID string, todoID int, item *models.ChecklistItem) (*models.ChecklistItem, *models.Todo, error)
+	UpdateItem(ctx context.Context, ownerID string, todoID int, itemID int, updatedItem *models.ChecklistItem) (*models.ChecklistItem, *models.Todo, error)
+	DeleteItem(ctx context.Context, ownerID string, todoID int, itemID int) (*models.Todo, error)
+	ReorderItems(ctx context.Context, ownerID string, todoID int, itemIDs []int) ([]*models.ChecklistItem, error)
+	GetLists(ctx context.Context, ownerID string) ([]*models.TodoList, error)
+	GetList(ctx context.Context, ownerID string, id int) (*models.TodoList, error)
+	CreateList(ctx context.Context, list *models.TodoList) (*models.TodoList, error)
+	UpdateList(ctx context.Context, ownerID string, id int, updatedList *models.TodoList) (*models.TodoList, error)
+	DeleteList(ctx context.Context, ownerID string, id int, cascade bool, moveTo int) ([]*models.Todo, error)
+	Batch(ctx context.Context, ownerID string, ops []TodoBatchOp, atomic bool) ([]TodoBatchResult, error)
 }
 
+// TodoChange changes a todo in an update. It is applied to the todo as stored when
+// the update runs, like a BlogChange, and the store then updates the fields derived
+// from the changed ones (see applyTodoChange).
+type TodoChange func(todo *models.Todo) error
+
+// BlogStoreInterface defines the interface for blog storage.
+// Create and Update save every resulting state of a blog as an immutable, numbered
+// revision, available through ListRevisions and GetRevision. PublishDue publishes
+// scheduled blogs and must be safe to call concurrently from several processes.
+// Slugs are unique: Create and Update append a numeric suffix to a slug taken by
+// another blog, and GetBySlug also resolves the previous slugs of a blog.
+// MergeTags rewrites tags across all blogs, saving a revision of each changed blog.
+// Batch applies creates, updates and deletes together like the todo store does.
+// Methods return ErrNotFound, ErrConflict or ErrUnavailable (possibly wrapped).
+type BlogStoreInterface interface {
+	GetAll(ctx context.Context) ([]*models.Blog, error)
+	List(ctx context.Context, opts ListOptions) ([]*models.Blog, string, error)
+	GetByID(ctx context.Context, id int) (*models.Blog, error)
+	GetBySlug(ctx context.Context, slug string) (*models.Blog, error)
+	Create(ctx context.Context, blog *models.Blog) (*models.Blog, error)
+	Update(ctx context.Context, id int, change BlogChange) (*models.Blog, error)
+	Delete(ctx context.Context, id int) error
+	ListRevisions(ctx context.Context, id int) ([]*models.BlogRevision, error)
+	GetRevision(ctx context.Context, id int, rev int) (*models.BlogRevision, error)
+	PublishDue(ctx context.Context, now time.Time) ([]*models.Blog, error)
+	MergeTags(ctx context.Context, from []string, to string, updatedBy string) ([]*models.Blog, error)
+	Batch(ctx context.Context, ops []BlogBatchOp, atomic bool) ([]BlogBatchResult, error)
+}
+
+// BlogChange changes a blog in an update. It is applied to the blog as stored when
+// the update runs (inside the transaction on Firestore, so it may run more than once)
+// and must only depend on that blog and its own arguments. An error returned by the
+// change aborts the update and is returned as is.
+type BlogChange func(blog *models.Blog) error
+
+// CommentStoreInterface defines the interface for comment storage.
+// Comments belong to a blog: reads and writes with another blogID behave as if the
+// comment did not exist. Deleting a comment also deletes its replies.
+// Methods return ErrNotFound, ErrConflict or ErrUnavailable (possibly wrapped).
+type CommentStoreInterface interface {
+	ListByBlog(ctx context.Context, blogID int) ([]*models.Comment, error)
+	ListByStatus(ctx context.Context, status models.CommentStatus, limit int) ([]*models.Comment, error)
+	GetByID(ctx context.Context, blogID int, id int) (*models.Comment, error)
+	Create(ctx context.Context, comment *models.Comment) (*models.Comment, error)
+	SetStatus(ctx context.Context, blogID int, id int, status models.CommentStatus) (*models.Comment, error)
+	Delete(ctx context.Context, blogID int, id int) error
+	DeleteByBlog(ctx context.Context, blogID int) error
+}
@@ -0,0 +1,39 @@
+package store
+
+const (
+	// tagQueryLimit is the maximum number of values of a Firestore array-contains-any query
+	tagQueryLimit = 30
+	// tagMergeBatchSize is the number of blogs rewritten per batched write. A blog takes
+	// up to three of the 500 writes allowed in a batch (the blog and two revisions).
+	tagMergeBatchSize = 150
+)
+
+// replaceTags replaces every tag of tags found in from with to, without duplicates.
+// It reports whether the tags changed.
+func replaceTags(tags []string, from map[string]bool, to string) ([]string, bool) {
+	replaced := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	changed := false
+	for _, tag := range tags {
+		if from[tag] {
+			changed = changed || tag != to
+			tag = to
+		}
+		if seen[tag] {
+			changed = true
+			continue
+		}
+		seen[tag] = true
+		replaced = append(replaced, tag)
+	}
+	return replaced, changed
+}
+
+// tagSet returns the tags as a set
+func tagSet(tags []string) map[string]bool {
+	set := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		set[tag] = true
+	}
+	return set
+}
@@ -0,0 +1,44 @@
+package store
+
+import (
+	"apigo1/models"
+	"time"
+)
+
+// prepareTodo fills the stored fields derived from a new todo
+func prepareTodo(todo *models.Todo) {
+	if todo.Priority == "" {
+		todo.Priority = models.PriorityMedium
+	}
+	todo.PriorityRank = todo.Priority.Rank()
+	// A new recurring todo is the first occurrence of its series
+	if todo.Recurrence != "" && todo.Occurrence == 0 {
+		todo.Occurrence = 1
+	}
+}
+
+// applyTodoChange applies change to todo, updated at now, and updates the fields
+// that derive from the changed ones. CompletedAt is cleared when the todo is not
+// completed and set to now when it is completed without one. Turning AutoComplete
+// on completes a todo whose checklist is already done.
+func applyTodoChange(todo *models.Todo, change TodoChange, now time.Time) error {
+	autoComplete := todo.AutoComplete
+	if err := change(todo); err != nil {
+		return err
+	}
+	todo.UpdatedAt = now
+	todo.PriorityRank = todo.Priority.Rank()
+
+	switch {
+	case !todo.Completed:
+		todo.CompletedAt = nil
+	case todo.CompletedAt == nil:
+		completedAt := now
+		todo.CompletedAt = &completedAt
+	}
+
+	if todo.AutoComplete && !autoComplete {
+		completeIfDone(todo, now)
+	}
+	return nil
+}